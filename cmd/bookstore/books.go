@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"practica-go/internal/export"
+	"practica-go/internal/model"
+	"practica-go/internal/query"
+	"practica-go/internal/service"
+	"strconv"
+	"strings"
+)
+
+// booksCmd agrupa la importación y exportación del catálogo
+func (a *app) booksCmd(ctx context.Context, args []string) error {
+	sub, args, err := subcommand(args, "import", "export")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("books "+sub, flag.ContinueOnError)
+	file := fs.String("file", "", "archivo de entrada o salida (vacío = stdin/stdout)")
+	format := fs.String("format", "", "formato del archivo: json, csv, jsonl, xlsx, onix o marc (por defecto según la extensión)")
+	mapping := fs.String("map", "", "mapeo de columnas CSV, ej. title:Nombre,author:Escritor")
+	q := fs.String("q", "", "exportar solo los libros que cumplen la consulta (mismo lenguaje que /books/query)")
+	dryRun := fs.Bool("dry-run", false, "MARC: validar y mostrar el reporte sin crear libros")
+	out := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = formatFromFile(*file)
+	}
+
+	switch sub {
+	case "import":
+		switch *format {
+		case export.FormatCSV:
+			return a.importCSV(ctx, *file, *mapping, out)
+		case export.FormatONIX:
+			return a.importONIX(ctx, *file, out)
+		case formatMARC:
+			return a.importMARC(ctx, *file, *dryRun, out)
+		}
+		return a.importBooks(ctx, *file, out)
+	case "export":
+		if *format == "" || *format == "json" {
+			return a.exportBooks(ctx, *file)
+		}
+		return a.exportStream(ctx, *file, *format, *q)
+	}
+	return nil
+}
+
+// formatMARC solo se puede importar, por eso no está en el paquete export
+const formatMARC = "marc"
+
+// formatFromFile deduce el formato por la extensión del archivo
+func formatFromFile(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".mrc", ".marc":
+		return formatMARC
+	}
+	return export.FormatFromFile(file)
+}
+
+// openInput abre el archivo indicado o stdin si está vacío
+func openInput(file string) (io.ReadCloser, error) {
+	if file == "" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(file)
+}
+
+// importCSV usa el mismo importador que POST /books/import
+func (a *app) importCSV(ctx context.Context, file, mapping string, out *printer) error {
+	m, err := service.ParseCSVMapping(mapping)
+	if err != nil {
+		return err
+	}
+	r, err := openInput(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	report, err := a.books.ImportCSV(ctx, r, m)
+	if report != nil {
+		// Un corte en la lectura deja un reporte parcial: las filas
+		// anteriores ya se guardaron
+		if perr := printReport(out, report); perr != nil {
+			return perr
+		}
+	}
+	return err
+}
+
+// importONIX crea o actualiza libros por ISBN desde un mensaje ONIX 3.0
+func (a *app) importONIX(ctx context.Context, file string, out *printer) error {
+	r, err := openInput(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	report, err := a.books.ImportONIX(ctx, r)
+	if err != nil {
+		return err
+	}
+	return printReport(out, report)
+}
+
+// importMARC crea libros desde registros MARC21 (ISO 2709 o MARCXML)
+func (a *app) importMARC(ctx context.Context, file string, dryRun bool, out *printer) error {
+	r, err := openInput(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	report, err := a.books.ImportMARC(ctx, r, dryRun)
+	if err != nil {
+		return err
+	}
+	return printReport(out, report)
+}
+
+// printReport muestra el reporte por fila de una importación
+func printReport(out *printer, report *service.ImportReport) error {
+	rows := make([][]string, len(report.Rows))
+	for i, row := range report.Rows {
+		id := ""
+		if row.BookID > 0 {
+			id = strconv.Itoa(row.BookID)
+		}
+		rows[i] = []string{strconv.Itoa(row.Row), id, row.Title, row.Status, row.Reason}
+	}
+	return out.print(report, []string{"FILA", "ID", "TÍTULO", "ESTADO", "MOTIVO"}, rows)
+}
+
+// importResult es el resultado de importar un libro
+type importResult struct {
+	Line  int         `json:"line"`
+	Book  *model.Book `json:"book,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// importBooks crea cada libro con BookService.CreateBook, así se aplican las
+// mismas validaciones que en la API. Un libro inválido no frena al resto.
+func (a *app) importBooks(ctx context.Context, file string, out *printer) error {
+	r, err := openInput(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	books, err := readBooksJSON(r)
+	if err != nil {
+		return err
+	}
+
+	results := make([]importResult, 0, len(books))
+	rows := make([][]string, 0, len(books))
+	for i, b := range books {
+		res := importResult{Line: i + 1}
+		created, err := a.books.CreateBook(ctx, b)
+		if err != nil {
+			res.Error = err.Error()
+			rows = append(rows, []string{strconv.Itoa(res.Line), "", b.Titulo, "error: " + res.Error})
+		} else {
+			res.Book = created
+			rows = append(rows, []string{strconv.Itoa(res.Line), strconv.Itoa(created.ID), created.Titulo, "creado"})
+		}
+		results = append(results, res)
+	}
+	return out.print(results, []string{"#", "ID", "TÍTULO", "RESULTADO"}, rows)
+}
+
+// readBooksJSON acepta un array JSON o JSON Lines (un libro por línea)
+func readBooksJSON(r io.Reader) ([]*model.Book, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+
+	var books []*model.Book
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &books); err != nil {
+			return nil, fmt.Errorf("JSON inválido: %w", err)
+		}
+		return books, nil
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		b := &model.Book{}
+		if err := json.Unmarshal(sc.Bytes(), b); err != nil {
+			return nil, fmt.Errorf("línea %d: JSON inválido: %w", line, err)
+		}
+		books = append(books, b)
+	}
+	return books, sc.Err()
+}
+
+// exportStream escribe el catálogo en csv, jsonl o xlsx leyendo libro por libro,
+// igual que GET /books/export. Pensado para exportaciones programadas.
+func (a *app) exportStream(ctx context.Context, file, format, q string) (err error) {
+	var expr query.Expr
+	if q != "" {
+		if expr, err = query.Parse(q); err != nil {
+			return err
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	ew, err := export.New(format, bw)
+	if err != nil {
+		return err
+	}
+	if err := a.books.ExportBooks(ctx, expr, ew.Write); err != nil {
+		return err
+	}
+	if err := ew.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// exportBooks escribe el catálogo completo como array JSON
+func (a *app) exportBooks(ctx context.Context, file string) error {
+	books, err := a.books.GetAllBooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if books == nil {
+		books = []*model.Book{}
+	}
+	return enc.Encode(books)
+}
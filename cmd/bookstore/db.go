@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// migrate aplica las migraciones pendientes o, con "status", las lista
+func (a *app) migrate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	out := outputFlag(fs)
+
+	status := len(args) > 0 && args[0] == "status"
+	if status {
+		args = args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if status {
+		pending, err := a.store.PendingMigrations(ctx)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, len(pending))
+		for i, name := range pending {
+			rows[i] = []string{name, "pendiente"}
+		}
+		return out.print(map[string]any{"pending": pending}, []string{"MIGRACIÓN", "ESTADO"}, rows)
+	}
+
+	n, err := a.store.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+	return out.print(map[string]int{"applied": n}, []string{"APLICADAS"}, [][]string{{strconv.Itoa(n)}})
+}
+
+// dbCmd agrupa las operaciones sobre la base de datos
+func (a *app) dbCmd(ctx context.Context, args []string) error {
+	sub, args, err := subcommand(args, "backup")
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "backup":
+		fs := flag.NewFlagSet("db backup", flag.ContinueOnError)
+		path := fs.String("out", "", "archivo de destino (no debe existir)")
+		out := outputFlag(fs)
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *path == "" {
+			return fmt.Errorf("falta -out")
+		}
+		if err := a.store.Backup(ctx, *path); err != nil {
+			return err
+		}
+		return out.print(map[string]string{"backup": *path}, []string{"BACKUP"}, [][]string{{*path}})
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Comando bookstore: CLI de administración que comparte la capa de servicios
+// con el servidor HTTP.
+//
+//	bookstore migrate [status]
+//	bookstore users list|create-admin|reset-password|restore [flags]
+//	bookstore books import|export [flags]
+//	bookstore db backup -out archivo.db
+//
+// La base se configura igual que el servidor (CONFIG_FILE, DB_URL, ...).
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"practica-go/internal/config"
+	"practica-go/internal/security"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+)
+
+const usage = `uso: bookstore <comando> [subcomando] [flags]
+
+comandos:
+  migrate [status]              aplica las migraciones pendientes o muestra su estado
+  users list                    lista los usuarios
+  users create-admin            crea un usuario administrador
+  users reset-password          cambia la contraseña de un usuario
+  users restore -id 3           vuelve a dar de alta un usuario borrado
+  books import -file f.json     importa libros desde JSON (array o una línea por libro)
+  books import -file f.csv      importa libros desde CSV con reporte por fila (-map para mapear columnas)
+  books export [-file f.json]   exporta el catálogo a JSON
+  books import -file f.xml      importa un mensaje ONIX 3.0 (crea o actualiza por ISBN)
+  books import -file f.mrc      importa registros MARC21 o MARCXML (-format marc); -dry-run solo valida
+  books export -format xlsx     exporta a csv, jsonl, xlsx u onix leyendo de a un libro (-q para filtrar)
+  db backup -out copia.db       copia consistente de la base
+
+Todos los subcomandos aceptan -output json|table.
+La base se toma de CONFIG_FILE / DB_URL igual que el servidor.
+`
+
+// app reúne las dependencias compartidas por los subcomandos
+type app struct {
+	store *store.Store
+	books *service.BookService
+	users *service.UserService
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	a, closeFn, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	cmd, rest := args[0], args[1:]
+	switch cmd {
+	case "migrate":
+		return a.migrate(ctx, rest)
+	case "users":
+		return a.usersCmd(ctx, rest)
+	case "books":
+		return a.booksCmd(ctx, rest)
+	case "db":
+		return a.dbCmd(ctx, rest)
+	default:
+		return fmt.Errorf("comando desconocido %q\n\n%s", cmd, usage)
+	}
+}
+
+// newApp abre la base y arma los services con la misma configuración que el servidor
+func newApp() (*app, func(), error) {
+	cfg, err := config.Parse(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Database.URL == "" {
+		return nil, nil, fmt.Errorf("falta la URL de la base (DB_URL)")
+	}
+	if err := security.SetBcryptCost(cfg.Auth.BcryptCost); err != nil {
+		return nil, nil, err
+	}
+
+	db, err := store.Open(cfg.Database.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	st := store.New(db)
+	a := &app{
+		store: st,
+		books: service.NewBook(*st),
+		// La CLI no emite tokens, por eso el TokenIssuer queda vacío
+		users: service.NewUser(*st, nil),
+	}
+	return a, func() { db.Close() }, nil
+}
+
+// subcommand separa el nombre del subcomando de sus flags
+func subcommand(args []string, valid ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("falta el subcomando (%v)", valid)
+	}
+	for _, v := range valid {
+		if args[0] == v {
+			return args[0], args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("subcomando desconocido %q (%v)", args[0], valid)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer escribe resultados como JSON o como tabla según -output
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+// outputFlag registra -output en el FlagSet del subcomando
+func outputFlag(fs *flag.FlagSet) *printer {
+	p := &printer{w: os.Stdout}
+	fs.StringVar(&p.format, "output", "table", "formato de salida: json o table")
+	return p
+}
+
+// print muestra v como JSON o, en formato tabla, con las columnas y filas dadas
+func (p *printer) print(v any, header []string, rows [][]string) error {
+	switch p.format {
+	case "json":
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "table":
+		tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, r := range rows {
+			fmt.Fprintln(tw, strings.Join(r, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("formato de salida desconocido %q (json o table)", p.format)
+	}
+}
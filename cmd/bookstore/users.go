@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"practica-go/internal/model"
+	"strconv"
+)
+
+// usersCmd agrupa la administración de usuarios
+func (a *app) usersCmd(ctx context.Context, args []string) error {
+	sub, args, err := subcommand(args, "list", "create-admin", "reset-password", "restore")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("users "+sub, flag.ContinueOnError)
+	out := outputFlag(fs)
+
+	switch sub {
+	case "list":
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		users, err := a.users.GetAllUser(ctx)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, len(users))
+		for i, u := range users {
+			rows[i] = []string{strconv.Itoa(u.ID), u.Username, u.Email, u.Role}
+		}
+		return out.print(users, []string{"ID", "USERNAME", "EMAIL", "ROL"}, rows)
+
+	case "create-admin":
+		username := fs.String("username", "", "nombre de usuario")
+		email := fs.String("email", "", "email")
+		password := fs.String("password", "", "contraseña (mínimo 6 caracteres)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		admin, err := a.users.CreateAdmin(ctx, &model.User{Username: *username, Email: *email, Password: *password})
+		if err != nil {
+			return err
+		}
+		return out.print(admin, []string{"ID", "USERNAME", "EMAIL", "ROL"},
+			[][]string{{strconv.Itoa(admin.ID), admin.Username, admin.Email, admin.Role}})
+
+	case "reset-password":
+		user := fs.String("user", "", "username o email")
+		password := fs.String("password", "", "contraseña nueva (mínimo 6 caracteres)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *user == "" {
+			return fmt.Errorf("falta -user")
+		}
+		if err := a.users.ResetPassword(ctx, *user, *password); err != nil {
+			return err
+		}
+		return out.print(map[string]string{"reset": *user}, []string{"CONTRASEÑA RESTABLECIDA"}, [][]string{{*user}})
+
+	case "restore":
+		id := fs.Int("id", 0, "ID del usuario borrado")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		user, err := a.users.RestoreUser(ctx, *id)
+		if err != nil {
+			return err
+		}
+		return out.print(user, []string{"ID", "USERNAME", "EMAIL", "ROL"},
+			[][]string{{strconv.Itoa(user.ID), user.Username, user.Email, user.Role}})
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Package config carga la configuración tipada de la aplicación.
+//
+// Precedencia (de menor a mayor): valores por defecto, archivo YAML o TOML
+// (-config o CONFIG_FILE), variables de entorno y flags de línea de comandos.
+// Cada campo declara en sus tags de dónde puede venir:
+//
+//	env:"PORT"      variable de entorno
+//	flag:"port"     flag de línea de comandos
+//	secret:"true"   se enmascara al imprimir (secret:"url" oculta solo la contraseña)
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Config es la configuración completa de la aplicación
+type Config struct {
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	Auth     AuthConfig     `yaml:"auth" toml:"auth"`
+	Log      LogConfig      `yaml:"log" toml:"log"`
+	Trace    TraceConfig    `yaml:"trace" toml:"trace"`
+	Webhooks WebhookConfig  `yaml:"webhooks" toml:"webhooks"`
+	Events   EventsConfig   `yaml:"events" toml:"events"`
+	Outbox   OutboxConfig   `yaml:"outbox" toml:"outbox"`
+	Trash    TrashConfig    `yaml:"trash" toml:"trash"`
+
+	// File es el archivo de configuración usado, si hubo alguno
+	File string `yaml:"-" toml:"-"`
+	// PrintConfig pide imprimir la configuración efectiva y salir
+	PrintConfig bool `yaml:"-" toml:"-"`
+}
+
+type ServerConfig struct {
+	Port            int           `yaml:"port" toml:"port" env:"PORT" flag:"port"`
+	GRPCPort        int           `yaml:"grpc_port" toml:"grpc_port" env:"GRPC_PORT" flag:"grpc-port"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"SERVER_READ_TIMEOUT" flag:"read-timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" flag:"write-timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" flag:"idle-timeout"`
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay" toml:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY" flag:"shutdown-delay"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout"`
+	HealthTimeout   time.Duration `yaml:"health_timeout" toml:"health_timeout" env:"HEALTH_TIMEOUT" flag:"health-timeout"`
+	MaxBodyBytes    int           `yaml:"max_body_bytes" toml:"max_body_bytes" env:"SERVER_MAX_BODY_BYTES" flag:"max-body-bytes"`
+}
+
+type DatabaseConfig struct {
+	URL          string `yaml:"url" toml:"url" env:"DB_URL" flag:"db-url" secret:"url"`
+	AutoMigrate  bool   `yaml:"auto_migrate" toml:"auto_migrate" env:"DB_AUTO_MIGRATE" flag:"db-auto-migrate"`
+	MaxOpenConns int    `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" flag:"db-max-open-conns"`
+}
+
+type AuthConfig struct {
+	JWTSecret       string        `yaml:"jwt_secret" toml:"jwt_secret" env:"JWT_SECRET" flag:"jwt-secret" secret:"true"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl" toml:"access_token_ttl" env:"JWT_ACCESS_TTL" flag:"jwt-access-ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" toml:"refresh_token_ttl" env:"JWT_REFRESH_TTL" flag:"jwt-refresh-ttl"`
+	BcryptCost      int           `yaml:"bcrypt_cost" toml:"bcrypt_cost" env:"BCRYPT_COST" flag:"bcrypt-cost"`
+}
+
+type LogConfig struct {
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT" flag:"log-format"`
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL" flag:"log-level"`
+}
+
+type TraceConfig struct {
+	Exporter string `yaml:"exporter" toml:"exporter" env:"TRACE_EXPORTER" flag:"trace-exporter"`
+	File     string `yaml:"file" toml:"file" env:"TRACE_FILE" flag:"trace-file"`
+}
+
+type WebhookConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL" flag:"webhook-poll-interval"`
+	Timeout      time.Duration `yaml:"timeout" toml:"timeout" env:"WEBHOOK_TIMEOUT" flag:"webhook-timeout"`
+	MaxAttempts  int           `yaml:"max_attempts" toml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" flag:"webhook-max-attempts"`
+	// AllowPrivate permite webhooks a direcciones privadas o de loopback;
+	// solo para desarrollo local
+	AllowPrivate bool `yaml:"allow_private" toml:"allow_private" env:"WEBHOOK_ALLOW_PRIVATE" flag:"webhook-allow-private"`
+}
+
+type EventsConfig struct {
+	ReplaySize   int `yaml:"replay_size" toml:"replay_size" env:"EVENTS_REPLAY_SIZE" flag:"events-replay-size"`
+	ClientBuffer int `yaml:"client_buffer" toml:"client_buffer" env:"EVENTS_CLIENT_BUFFER" flag:"events-client-buffer"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" flag:"outbox-poll-interval"`
+	Retention    time.Duration `yaml:"retention" toml:"retention" env:"OUTBOX_RETENTION" flag:"outbox-retention"`
+}
+
+// TrashConfig es cuánto se guardan los libros y usuarios borrados antes de
+// borrarlos definitivamente, y cada cuánto se revisa
+type TrashConfig struct {
+	Retention     time.Duration `yaml:"retention" toml:"retention" env:"TRASH_RETENTION" flag:"trash-retention"`
+	PurgeInterval time.Duration `yaml:"purge_interval" toml:"purge_interval" env:"TRASH_PURGE_INTERVAL" flag:"trash-purge-interval"`
+}
+
+// minJWTSecret es el largo mínimo de la clave HS256 (256 bits)
+const minJWTSecret = 32
+
+// Default devuelve la configuración por defecto. JWTSecret queda vacío a
+// propósito: es obligatorio definirlo.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            8080,
+			GRPCPort:        9090,
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownDelay:   5 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+			HealthTimeout:   2 * time.Second,
+			MaxBodyBytes:    1 << 20,
+		},
+		Database: DatabaseConfig{
+			URL:          "sqlite3://./data.db",
+			AutoMigrate:  true,
+			MaxOpenConns: 10,
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+			BcryptCost:      bcrypt.DefaultCost,
+		},
+		Log:      LogConfig{Format: "json", Level: "info"},
+		Trace:    TraceConfig{Exporter: "none", File: "traces.jsonl"},
+		Webhooks: WebhookConfig{PollInterval: 5 * time.Second, Timeout: 10 * time.Second, MaxAttempts: 8},
+		Events:   EventsConfig{ReplaySize: 1000, ClientBuffer: 64},
+		Outbox:   OutboxConfig{PollInterval: time.Second, Retention: 7 * 24 * time.Hour},
+		Trash:    TrashConfig{Retention: 30 * 24 * time.Hour, PurgeInterval: time.Hour},
+	}
+}
+
+// Validate revisa todos los valores y devuelve todos los errores juntos
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port debe estar entre 1 y 65535")
+	check(c.Server.GRPCPort >= 0 && c.Server.GRPCPort <= 65535, "server.grpc_port debe estar entre 0 (deshabilitado) y 65535")
+	check(c.Server.GRPCPort != c.Server.Port, "server.grpc_port no puede ser igual a server.port")
+	check(c.Server.ReadTimeout > 0, "server.read_timeout debe ser mayor a cero")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout debe ser mayor a cero")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout debe ser mayor a cero")
+	check(c.Server.ShutdownDelay >= 0, "server.shutdown_delay no puede ser negativo")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout debe ser mayor a cero")
+	check(c.Server.HealthTimeout > 0, "server.health_timeout debe ser mayor a cero")
+	check(c.Server.MaxBodyBytes > 0, "server.max_body_bytes debe ser mayor a cero")
+
+	check(strings.TrimSpace(c.Database.URL) != "", "database.url es obligatorio (DB_URL)")
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns no puede ser negativo")
+
+	check(c.Auth.JWTSecret != "", "auth.jwt_secret es obligatorio (JWT_SECRET)")
+	check(c.Auth.JWTSecret == "" || len(c.Auth.JWTSecret) >= minJWTSecret, "auth.jwt_secret debe tener al menos %d caracteres", minJWTSecret)
+	check(c.Auth.AccessTokenTTL > 0, "auth.access_token_ttl debe ser mayor a cero")
+	check(c.Auth.RefreshTokenTTL > c.Auth.AccessTokenTTL, "auth.refresh_token_ttl debe ser mayor que auth.access_token_ttl")
+	check(c.Auth.BcryptCost >= bcrypt.MinCost && c.Auth.BcryptCost <= bcrypt.MaxCost, "auth.bcrypt_cost debe estar entre %d y %d", bcrypt.MinCost, bcrypt.MaxCost)
+
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format debe ser json o text")
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "log.level debe ser debug, info, warn o error")
+
+	check(oneOf(c.Trace.Exporter, "none", "stdout", "file"), "trace.exporter debe ser none, stdout o file")
+	check(c.Trace.Exporter != "file" || c.Trace.File != "", "trace.file es obligatorio con el exportador file")
+
+	check(c.Webhooks.PollInterval > 0, "webhooks.poll_interval debe ser mayor a cero")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout debe ser mayor a cero")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts debe ser mayor a cero")
+
+	check(c.Events.ReplaySize >= 0, "events.replay_size no puede ser negativo")
+	check(c.Events.ClientBuffer > 0, "events.client_buffer debe ser mayor a cero")
+
+	check(c.Outbox.PollInterval > 0, "outbox.poll_interval debe ser mayor a cero")
+	check(c.Outbox.Retention > 0, "outbox.retention debe ser mayor a cero")
+
+	check(c.Trash.Retention > 0, "trash.retention debe ser mayor a cero")
+	check(c.Trash.PurgeInterval > 0, "trash.purge_interval debe ser mayor a cero")
+
+	return errors.Join(errs...)
+}
+
+func oneOf(v string, options ...string) bool {
+	for _, o := range options {
+		if strings.EqualFold(v, o) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// clearEnv deja sin definir todas las variables que lee la configuración,
+// para que el entorno de quien corre los tests no cambie el resultado
+func clearEnv(t *testing.T) {
+	t.Helper()
+	names := []string{"CONFIG_FILE"}
+	eachField(Default(), func(_ string, f reflect.StructField, _ reflect.Value) {
+		if name := f.Tag.Get("env"); name != "" {
+			names = append(names, name)
+		}
+	})
+	for _, name := range names {
+		t.Setenv(name, "") // registra la restauración al terminar el test
+		os.Unsetenv(name)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Precedencia: default < archivo < entorno < flags
+func TestParsePrecedence(t *testing.T) {
+	yamlFile := "server:\n  port: 7000\nlog:\n  level: debug\n"
+	tomlFile := "[server]\nport = 7000\n\n[log]\nlevel = \"debug\"\n"
+
+	tests := []struct {
+		name       string
+		file       string // nombre del archivo; vacío = sin archivo
+		content    string
+		viaEnv     bool // el archivo se indica con CONFIG_FILE en lugar de -config
+		env        map[string]string
+		args       []string
+		wantPort   int
+		wantLevel  string
+		wantFormat string
+	}{
+		{name: "default", wantPort: 8080, wantLevel: "info", wantFormat: "json"},
+		{name: "archivo yaml", file: "app.yaml", content: yamlFile, wantPort: 7000, wantLevel: "debug", wantFormat: "json"},
+		{name: "archivo toml", file: "app.toml", content: tomlFile, wantPort: 7000, wantLevel: "debug", wantFormat: "json"},
+		{name: "archivo por CONFIG_FILE", file: "app.yaml", content: yamlFile, viaEnv: true, wantPort: 7000, wantLevel: "debug", wantFormat: "json"},
+		{
+			name: "entorno sobre archivo", file: "app.yaml", content: yamlFile,
+			env:      map[string]string{"PORT": "7100", "LOG_FORMAT": "text"},
+			wantPort: 7100, wantLevel: "debug", wantFormat: "text",
+		},
+		{
+			name: "flag sobre entorno y archivo", file: "app.yaml", content: yamlFile,
+			env:      map[string]string{"PORT": "7100", "LOG_LEVEL": "warn"},
+			args:     []string{"-port", "7200"},
+			wantPort: 7200, wantLevel: "warn", wantFormat: "json",
+		},
+		{name: "flag sin archivo", args: []string{"-port=7200", "-log-level", "error"}, wantPort: 7200, wantLevel: "error", wantFormat: "json"},
+		{name: "entorno sin archivo", env: map[string]string{"PORT": "7100"}, wantPort: 7100, wantLevel: "info", wantFormat: "json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			args := tt.args
+			if tt.file != "" {
+				path := writeFile(t, tt.file, tt.content)
+				if tt.viaEnv {
+					t.Setenv("CONFIG_FILE", path)
+				} else {
+					args = append([]string{"-config", path}, args...)
+				}
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Parse(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Server.Port != tt.wantPort || cfg.Log.Level != tt.wantLevel || cfg.Log.Format != tt.wantFormat {
+				t.Errorf("port = %d, level = %s, format = %s; se esperaba %d, %s, %s",
+					cfg.Server.Port, cfg.Log.Level, cfg.Log.Format, tt.wantPort, tt.wantLevel, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		args    []string
+		want    string
+	}{
+		{name: "clave desconocida en yaml", file: "app.yaml", content: "server:\n  puerto: 1\n", want: "puerto"},
+		{name: "clave desconocida en toml", file: "app.toml", content: "[server]\npuerto = 1\n", want: "claves desconocidas"},
+		{name: "extensión no soportada", file: "app.json", content: "{}", want: "formato no soportado"},
+		{name: "entorno inválido", env: map[string]string{"PORT": "ocho"}, want: "variable PORT"},
+		{name: "flag inválido", args: []string{"-webhook-timeout", "10"}, want: "flag -webhook-timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, tt.file, tt.content)}, args...)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := Parse(args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, se esperaba que mencione %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDumpMasksSecrets(t *testing.T) {
+	const secret = "una-clave-de-prueba-bien-larga-123456"
+
+	tests := []struct {
+		name    string
+		jwt     string
+		dbURL   string
+		wantJWT string
+		wantURL string
+	}{
+		{name: "secretos definidos", jwt: secret, dbURL: "postgres://app:hunter2@db:5432/libros", wantJWT: mask, wantURL: "postgres://app:xxxxx@db:5432/libros"},
+		{name: "url sin contraseña", jwt: secret, dbURL: "sqlite3://./data.db", wantJWT: mask, wantURL: "sqlite3://./data.db"},
+		{name: "secreto vacío", jwt: "", dbURL: "sqlite3://./data.db", wantJWT: "", wantURL: "sqlite3://./data.db"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Auth.JWTSecret = tt.jwt
+			cfg.Database.URL = tt.dbURL
+
+			m := cfg.Map()
+			if m["auth.jwt_secret"] != tt.wantJWT {
+				t.Errorf("auth.jwt_secret = %q, se esperaba %q", m["auth.jwt_secret"], tt.wantJWT)
+			}
+			if m["database.url"] != tt.wantURL {
+				t.Errorf("database.url = %q, se esperaba %q", m["database.url"], tt.wantURL)
+			}
+
+			dump := cfg.Dump()
+			if strings.Contains(dump, secret) || strings.Contains(dump, "hunter2") {
+				t.Errorf("Dump muestra un secreto:\n%s", dump)
+			}
+			if !strings.Contains(dump, "auth.jwt_secret = "+tt.wantJWT+"\n") {
+				t.Errorf("Dump no tiene la clave enmascarada:\n%s", dump)
+			}
+		})
+	}
+}
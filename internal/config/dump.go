@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// mask es lo que se muestra en lugar de un secreto
+const mask = "****"
+
+// Dump devuelve la configuración efectiva, una clave por línea, con los
+// secretos enmascarados. Es seguro escribirla en logs.
+func (c *Config) Dump() string {
+	var b strings.Builder
+	if c.File != "" {
+		fmt.Fprintf(&b, "# archivo: %s\n", c.File)
+	}
+	eachField(c, func(key string, f reflect.StructField, v reflect.Value) {
+		fmt.Fprintf(&b, "%s = %s\n", key, maskValue(f.Tag.Get("secret"), v))
+	})
+	return b.String()
+}
+
+// Map devuelve la configuración efectiva enmascarada como mapa plano
+func (c *Config) Map() map[string]string {
+	m := make(map[string]string)
+	eachField(c, func(key string, f reflect.StructField, v reflect.Value) {
+		m[key] = maskValue(f.Tag.Get("secret"), v)
+	})
+	return m
+}
+
+func maskValue(secret string, v reflect.Value) string {
+	s := fmt.Sprint(v.Interface())
+	switch {
+	case secret == "true" && s != "":
+		return mask
+	case secret == "url":
+		return maskURL(s)
+	default:
+		return s
+	}
+}
+
+// maskURL oculta la contraseña de una URL de conexión (user:pass@host)
+func maskURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Load arma la configuración a partir de todas las fuentes y la valida.
+// args son los argumentos de línea de comandos sin el nombre del programa.
+func Load(args []string) (*Config, error) {
+	cfg, err := Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuración inválida:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// Parse arma la configuración sin validarla. Lo usan las herramientas que
+// solo necesitan una parte (ej. la CLI no necesita JWT_SECRET).
+func Parse(args []string) (*Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("practica-go", flag.ContinueOnError)
+	configFile := fs.String("config", "", "archivo de configuración YAML o TOML")
+	printConfig := fs.Bool("print-config", false, "imprime la configuración efectiva y termina")
+	flagValues := make(map[string]*string)
+	eachField(cfg, func(_ string, f reflect.StructField, _ reflect.Value) {
+		if name := f.Tag.Get("flag"); name != "" {
+			flagValues[name] = fs.String(name, "", "env "+f.Tag.Get("env"))
+		}
+	})
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// 1. archivo
+	cfg.File = *configFile
+	if cfg.File == "" {
+		cfg.File = os.Getenv("CONFIG_FILE")
+	}
+	if cfg.File != "" {
+		if err := loadFile(cfg, cfg.File); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. variables de entorno
+	var err error
+	eachField(cfg, func(key string, f reflect.StructField, v reflect.Value) {
+		name := f.Tag.Get("env")
+		if name == "" || err != nil {
+			return
+		}
+		if raw, ok := os.LookupEnv(name); ok {
+			if setErr := setValue(v, raw); setErr != nil {
+				err = fmt.Errorf("variable %s: %w", name, setErr)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. flags: solo los que se pasaron explícitamente
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	eachField(cfg, func(key string, f reflect.StructField, v reflect.Value) {
+		name := f.Tag.Get("flag")
+		if name == "" || !set[name] || err != nil {
+			return
+		}
+		if setErr := setValue(v, *flagValues[name]); setErr != nil {
+			err = fmt.Errorf("flag -%s: %w", name, setErr)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.PrintConfig = *printConfig
+	return cfg, nil
+}
+
+// loadFile lee un archivo YAML o TOML según su extensión.
+// Las claves desconocidas son un error para detectar errores de tipeo.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el archivo de configuración: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: claves desconocidas: %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("%s: formato no soportado (usar .yaml, .yml o .toml)", path)
+	}
+	return nil
+}
+
+// eachField recorre los campos de las secciones de cfg. key es "seccion.campo"
+// usando los nombres del tag yaml.
+func eachField(cfg *Config, fn func(key string, f reflect.StructField, v reflect.Value)) {
+	root := reflect.ValueOf(cfg).Elem()
+	for i := range root.NumField() {
+		section := root.Type().Field(i)
+		if section.Type.Kind() != reflect.Struct {
+			continue
+		}
+		sv := root.Field(i)
+		for j := range sv.NumField() {
+			f := sv.Type().Field(j)
+			fn(yamlName(section)+"."+yamlName(f), f, sv.Field(j))
+		}
+	}
+}
+
+func yamlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue convierte raw al tipo del campo
+func setValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("duración inválida %q (ej. 5s, 1m)", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("número inválido %q", raw)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("booleano inválido %q", raw)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("tipo no soportado %s", v.Type())
+	}
+	return nil
+}
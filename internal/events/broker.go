@@ -0,0 +1,184 @@
+// Package events es un pub/sub en memoria para avisar de los cambios del
+// catálogo a los clientes conectados (GET /events). Guarda los últimos
+// eventos para que un cliente que se reconecta retome desde el último que
+// recibió.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"practica-go/internal/metrics"
+	"practica-go/internal/model"
+	"sync"
+	"time"
+)
+
+// Valores por defecto de Default
+const (
+	DefaultReplaySize   = 1000
+	DefaultClientBuffer = 64
+)
+
+// Default es el broker que alimenta el relay del outbox y lee GET /events
+var Default = NewBroker(DefaultReplaySize, DefaultClientBuffer)
+
+// SetDefault reemplaza el broker por defecto. Se llama al arrancar, antes de
+// crear el servidor y el relay.
+func SetDefault(b *Broker) {
+	Default = b
+}
+
+// Event es un evento publicado. Los ID son crecientes dentro del proceso.
+type Event struct {
+	ID         uint64          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Broker reparte cada evento a los suscriptores sin bloquear al que publica:
+// cada suscriptor tiene un buffer propio y si se llena (el cliente no lee a
+// tiempo) se lo desconecta. Al reconectarse con el último ID recibido
+// recupera lo que se perdió desde el buffer de repetición.
+type Broker struct {
+	mu           sync.Mutex
+	nextID       uint64
+	replay       []Event // buffer circular con los últimos eventos
+	start        int     // posición del más viejo en replay
+	subs         map[*Subscription]struct{}
+	closed       bool
+	replaySize   int
+	clientBuffer int
+}
+
+// NewBroker crea un broker que recuerda los últimos replaySize eventos y da
+// a cada suscriptor un buffer de clientBuffer eventos
+func NewBroker(replaySize, clientBuffer int) *Broker {
+	return &Broker{
+		// Los ID arrancan en el instante de creación para que un Last-Event-ID
+		// de una ejecución anterior quede antes del buffer y no se confunda
+		// con un evento nuevo
+		nextID:       uint64(time.Now().UnixMicro()),
+		subs:         make(map[*Subscription]struct{}),
+		replaySize:   replaySize,
+		clientBuffer: clientBuffer,
+	}
+}
+
+// Subscription recibe los eventos publicados en C. Si el broker la
+// desconecta por lenta, cierra C y Dropped devuelve true.
+type Subscription struct {
+	C       <-chan Event
+	c       chan Event
+	dropped bool
+	broker  *Broker
+}
+
+// Publish agrega un evento ocurrido en at al buffer de repetición y lo envía
+// a los suscriptores. Nunca bloquea.
+func (b *Broker) Publish(typ string, at time.Time, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	e := Event{ID: b.nextID, Type: typ, OccurredAt: at.UTC(), Data: raw}
+	if len(b.replay) < b.replaySize {
+		b.replay = append(b.replay, e)
+	} else if b.replaySize > 0 {
+		b.replay[b.start] = e
+		b.start = (b.start + 1) % b.replaySize
+	}
+
+	for s := range b.subs {
+		select {
+		case s.c <- e:
+		default:
+			s.dropped = true
+			b.removeLocked(s)
+			metrics.EventsSlowClients.Inc()
+		}
+	}
+	return e, nil
+}
+
+// HandleEvent es el handler del outbox: publica el evento para los clientes
+// de /events
+func (b *Broker) HandleEvent(ctx context.Context, e *model.OutboxEvent) error {
+	_, err := b.Publish(e.Event, e.CreatedAt, e.Payload)
+	return err
+}
+
+// Subscribe registra un suscriptor. Si lastID no es cero devuelve además los
+// eventos posteriores a lastID que quedan en el buffer; complete es false si
+// algunos ya salieron del buffer y el cliente tiene que recargar el estado.
+func (b *Broker) Subscribe(lastID uint64) (sub *Subscription, missed []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := make(chan Event, b.clientBuffer)
+	sub = &Subscription{C: c, c: c, broker: b}
+	if b.closed {
+		close(c)
+		return sub, nil, true
+	}
+	b.subs[sub] = struct{}{}
+
+	if lastID == 0 {
+		return sub, nil, true
+	}
+	// Los ID son consecutivos: el más viejo del buffer es nextID-len+1
+	oldest := b.nextID - uint64(len(b.replay)) + 1
+	complete = lastID <= b.nextID && lastID+1 >= oldest
+	for i := range b.replay {
+		if e := b.replay[(b.start+i)%len(b.replay)]; e.ID > lastID {
+			missed = append(missed, e)
+		}
+	}
+	return sub, missed, complete
+}
+
+// Close da de baja la suscripción
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.removeLocked(s)
+}
+
+// Dropped indica si el broker cerró la suscripción porque el cliente no leía
+// a tiempo
+func (s *Subscription) Dropped() bool {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.dropped
+}
+
+func (b *Broker) removeLocked(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+// Close desconecta a todos los suscriptores; los que se suscriban después
+// reciben C ya cerrado. Se usa al apagar el servidor para que los streams
+// abiertos no lo demoren.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.subs {
+		b.removeLocked(s)
+	}
+}
+
+// Subscribers devuelve la cantidad de suscriptores conectados
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
@@ -0,0 +1,153 @@
+package events
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// publish publica n eventos y devuelve sus ID
+func publish(t *testing.T, b *Broker, n int) []uint64 {
+	t.Helper()
+	ids := make([]uint64, n)
+	for i := range ids {
+		e, err := b.Publish("book.updated", time.Now(), map[string]int{"id": i})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func eventIDs(events []Event) []uint64 {
+	ids := make([]uint64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+// Con Last-Event-ID dentro del buffer se reenvía exactamente lo posterior
+func TestSubscribeReplay(t *testing.T) {
+	b := NewBroker(10, 4)
+	ids := publish(t, b, 5)
+
+	tests := []struct {
+		name   string
+		lastID uint64
+		want   []uint64
+	}{
+		{name: "último recibido", lastID: ids[4], want: nil},
+		{name: "a mitad del buffer", lastID: ids[1], want: ids[2:]},
+		{name: "justo antes del más viejo", lastID: ids[0] - 1, want: ids},
+		{name: "sin Last-Event-ID", lastID: 0, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed, complete := b.Subscribe(tt.lastID)
+			defer sub.Close()
+			if !complete {
+				t.Error("complete = false, no se perdió ningún evento")
+			}
+			if got := eventIDs(missed); !slices.Equal(got, tt.want) {
+				t.Errorf("reenviados = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Si los eventos que faltan ya salieron del buffer (o el ID es de otra
+// ejecución) se reenvía lo que queda y se pide recargar el estado
+func TestSubscribeResync(t *testing.T) {
+	b := NewBroker(3, 4)
+	ids := publish(t, b, 6) // el buffer guarda ids[3:]
+
+	tests := []struct {
+		name   string
+		lastID uint64
+		want   []uint64
+	}{
+		{name: "fuera del buffer", lastID: ids[1], want: ids[3:]},
+		{name: "ejecución anterior", lastID: 1, want: ids[3:]},
+		{name: "ID del futuro", lastID: ids[5] + 100, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed, complete := b.Subscribe(tt.lastID)
+			defer sub.Close()
+			if complete {
+				t.Error("complete = true, se esperaba un resync")
+			}
+			if got := eventIDs(missed); !slices.Equal(got, tt.want) {
+				t.Errorf("reenviados = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+
+	// Justo en el borde del buffer no falta nada
+	sub, missed, complete := b.Subscribe(ids[2])
+	defer sub.Close()
+	if !complete || !slices.Equal(eventIDs(missed), ids[3:]) {
+		t.Errorf("borde: complete = %v, reenviados = %v", complete, eventIDs(missed))
+	}
+}
+
+// Un suscriptor que no lee se desconecta sin bloquear al que publica ni
+// afectar a los demás
+func TestSlowSubscriberDropped(t *testing.T) {
+	b := NewBroker(10, 2)
+	slow, _, _ := b.Subscribe(0)
+	fast, _, _ := b.Subscribe(0)
+	defer fast.Close()
+
+	var received []uint64
+	for i := range 3 {
+		ids := publish(t, b, 1) // Publish no puede bloquear aunque slow no lea
+		select {
+		case e := <-fast.C:
+			received = append(received, e.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("evento %d no llegó al suscriptor que lee", i)
+		}
+		if received[i] != ids[0] {
+			t.Fatalf("recibido %d, se esperaba %d", received[i], ids[0])
+		}
+	}
+
+	if !slow.Dropped() {
+		t.Fatal("el suscriptor lento no se marcó como desconectado")
+	}
+	if fast.Dropped() {
+		t.Error("se desconectó al suscriptor que lee")
+	}
+	// C queda cerrado después de los eventos que alcanzaron a entrar
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("el suscriptor lento recibió %d eventos antes del corte, se esperaban 2", n)
+	}
+	if got := b.Subscribers(); got != 1 {
+		t.Errorf("Subscribers = %d, se esperaba 1", got)
+	}
+	slow.Close() // cerrar una suscripción ya desconectada no hace nada
+}
+
+// Después de Close los suscriptores nuevos reciben C cerrado
+func TestBrokerClose(t *testing.T) {
+	b := NewBroker(10, 2)
+	before, _, _ := b.Subscribe(0)
+	b.Close()
+	after, _, _ := b.Subscribe(0)
+
+	for _, sub := range []*Subscription{before, after} {
+		if _, ok := <-sub.C; ok {
+			t.Error("se esperaba C cerrado")
+		}
+	}
+	if b.Subscribers() != 0 {
+		t.Errorf("Subscribers = %d", b.Subscribers())
+	}
+}
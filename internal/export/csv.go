@@ -0,0 +1,29 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"practica-go/internal/model"
+)
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSV(w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (c *csvWriter) Write(b *model.Book) error {
+	return c.w.Write(record(b))
+}
+
+// Close vacía el buffer; csv.Writer acumula filas hasta llenarlo
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
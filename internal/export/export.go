@@ -0,0 +1,101 @@
+// Package export escribe el catálogo en formatos de planilla (CSV, JSON Lines
+// y XLSX) o como mensaje ONIX, libro por libro, sin necesitar la lista
+// completa en memoria.
+package export
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"practica-go/internal/model"
+	"practica-go/internal/onix"
+	"strconv"
+	"strings"
+)
+
+// Formatos soportados
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+	FormatONIX  = "onix"
+)
+
+// Formats lista los formatos en el orden en que se documentan
+var Formats = []string{FormatCSV, FormatJSONL, FormatXLSX, FormatONIX}
+
+// onixSender es el emisor que figura en la cabecera de los mensajes ONIX
+const onixSender = "practica-go"
+
+// columns son los encabezados de CSV y XLSX. Coinciden con los que reconoce
+// la importación CSV, así un archivo exportado se puede volver a importar.
+var columns = []string{"id", "title", "author", "year", "tags", "isbn", "publisher"}
+
+// Writer recibe los libros de a uno. Close completa el archivo (en XLSX
+// escribe el cierre del zip) pero no cierra el io.Writer de destino.
+type Writer interface {
+	Write(b *model.Book) error
+	Close() error
+}
+
+// New crea el Writer del formato indicado sobre w
+func New(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSV(w)
+	case FormatJSONL:
+		return newJSONL(w), nil
+	case FormatXLSX:
+		return newXLSX(w)
+	case FormatONIX:
+		return onix.NewWriter(w, onixSender)
+	default:
+		return nil, fmt.Errorf("formato de exportación desconocido %q (usar %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// ContentType devuelve el tipo MIME de cada formato
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatONIX:
+		return "application/xml"
+	}
+	return "application/octet-stream"
+}
+
+// Extension devuelve la extensión de archivo de cada formato
+func Extension(format string) string {
+	if format == FormatONIX {
+		return "xml"
+	}
+	return format
+}
+
+// FormatFromFile deduce el formato por la extensión del archivo ("" si no se reconoce)
+func FormatFromFile(name string) string {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	switch ext {
+	case "csv", "xlsx", "jsonl":
+		return ext
+	case "ndjson":
+		return FormatJSONL
+	case "xml", "onix":
+		return FormatONIX
+	}
+	return ""
+}
+
+// record arma la fila de texto de un libro; las etiquetas van separadas por ';'
+func record(b *model.Book) []string {
+	year := ""
+	if b.Anio != 0 {
+		year = strconv.Itoa(b.Anio)
+	}
+	return []string{strconv.Itoa(b.ID), b.Titulo, b.Autor, year, strings.Join(b.Etiquetas, ";"), b.ISBN, b.Editorial}
+}
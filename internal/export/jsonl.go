@@ -0,0 +1,24 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"practica-go/internal/model"
+)
+
+// jsonlWriter escribe un libro por línea con el mismo JSON que la API
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONL(w io.Writer) *jsonlWriter {
+	return &jsonlWriter{enc: json.NewEncoder(w)}
+}
+
+func (j *jsonlWriter) Write(b *model.Book) error {
+	return j.enc.Encode(b)
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package health agrupa los chequeos de salud de la aplicación.
+// Liveness solo indica que el proceso responde; readiness ejecuta los
+// chequeos registrados (base de datos, migraciones, subsistemas) y pasa a
+// falso cuando empieza el apagado ordenado.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CheckFunc verifica una dependencia; devuelve nil si está sana
+type CheckFunc func(ctx context.Context) error
+
+// Result es el resultado de un chequeo
+type Result struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Report es el resultado de todos los chequeos
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Estados posibles de un chequeo o del reporte
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Checker ejecuta los chequeos de readiness
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	names  []string
+	checks map[string]CheckFunc
+
+	shuttingDown atomic.Bool
+}
+
+// New crea un Checker; timeout limita la duración de cada chequeo
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout, checks: make(map[string]CheckFunc)}
+}
+
+// Register agrega un chequeo de readiness con el nombre indicado
+func (c *Checker) Register(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.checks[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = fn
+}
+
+// SetShuttingDown hace que readiness falle a partir de ahora, para que el
+// balanceador deje de enviar tráfico antes de cerrar el servidor
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready ejecuta todos los chequeos en paralelo y devuelve el reporte
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	names := append([]string(nil), c.names...)
+	checks := make([]CheckFunc, len(names))
+	for i, n := range names {
+		checks[i] = c.checks[n]
+	}
+	c.mu.RUnlock()
+
+	results := make([]Result, len(names))
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = c.run(ctx, names[i], checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	if c.shuttingDown.Load() {
+		results = append(results, Result{Name: "shutdown", Status: StatusFail, Error: "el servidor se está apagando"})
+	}
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, r := range results {
+		if r.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, name string, fn CheckFunc) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	res := Result{Name: name, Status: StatusOK, DurationMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			res.Error = "tiempo de espera agotado"
+		}
+	}
+	return res
+}
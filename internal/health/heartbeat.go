@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat lo actualiza un proceso en segundo plano (relay del outbox,
+// dispatcher de webhooks) cada vez que completa una vuelta. Sirve para
+// detectar un proceso colgado o que no logra leer su cola.
+type Heartbeat struct {
+	last atomic.Int64 // UnixNano de la última vuelta; 0 = ninguna
+}
+
+// Beat registra una vuelta completada ahora
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Last es el momento de la última vuelta (cero si todavía no hubo ninguna)
+func (h *Heartbeat) Last() time.Time {
+	n := h.last.Load()
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// Check devuelve un chequeo que falla si la última vuelta fue hace más de
+// maxAge o si todavía no hubo ninguna
+func (h *Heartbeat) Check(maxAge time.Duration) CheckFunc {
+	return func(context.Context) error {
+		last := h.Last()
+		if last.IsZero() {
+			return errors.New("todavía no completó ninguna vuelta")
+		}
+		if age := time.Since(last); age > maxAge {
+			return fmt.Errorf("la última vuelta fue hace %s (máximo %s)", age.Round(time.Second), maxAge)
+		}
+		return nil
+	}
+}
+
+// DialCheck devuelve un chequeo que abre y cierra una conexión a addr, para
+// verificar que un listener (ej. el del servidor gRPC) sigue aceptando
+func DialCheck(network, addr string) CheckFunc {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatCheck(t *testing.T) {
+	ctx := context.Background()
+	var h Heartbeat
+	check := h.Check(time.Minute)
+
+	if err := check(ctx); err == nil || !strings.Contains(err.Error(), "ninguna vuelta") {
+		t.Fatalf("sin vueltas: err = %v", err)
+	}
+	h.Beat()
+	if err := check(ctx); err != nil {
+		t.Fatalf("recién actualizado: err = %v", err)
+	}
+	h.last.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	if err := check(ctx); err == nil || !strings.Contains(err.Error(), "máximo 1m0s") {
+		t.Fatalf("vencido: err = %v", err)
+	}
+}
+
+func TestDialCheck(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	check := DialCheck("tcp", addr)
+
+	if err := check(context.Background()); err != nil {
+		t.Fatalf("listener abierto: err = %v", err)
+	}
+	lis.Close()
+	if err := check(context.Background()); err == nil {
+		t.Fatal("listener cerrado: se esperaba un error")
+	}
+}
+
+// Los chequeos registrados aparecen en el reporte de readiness con su nombre
+func TestReadyHeartbeat(t *testing.T) {
+	c := New(time.Second)
+	var ok, stale Heartbeat
+	ok.Beat()
+	c.Register("outbox", ok.Check(time.Minute))
+	c.Register("webhooks", stale.Check(time.Minute))
+
+	report := c.Ready(context.Background())
+	if report.Status != StatusFail {
+		t.Fatalf("status = %s, se esperaba %s", report.Status, StatusFail)
+	}
+	if report.Checks[0].Status != StatusOK || report.Checks[1].Status != StatusFail {
+		t.Errorf("chequeos = %+v", report.Checks)
+	}
+}
@@ -0,0 +1,86 @@
+// Package logger configura log/slog para toda la aplicación: formato JSON o
+// texto, nivel configurable, datos de la petición tomados del context y
+// ocultamiento de campos sensibles.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"practica-go/internal/reqctx"
+	"practica-go/internal/tracing"
+	"strings"
+)
+
+// redacted reemplaza el valor de los campos sensibles
+const redacted = "[REDACTED]"
+
+// sensitiveKeys son los atributos que nunca se escriben en claro
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"authorization": true,
+	"cookie":        true,
+	"secret":        true,
+}
+
+// New crea un logger con el formato ("json" o "text") y nivel indicados
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("nivel de log inválido %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redact}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("formato de log inválido %q (json o text)", format)
+	}
+	return slog.New(&contextHandler{Handler: h}), nil
+}
+
+// redact oculta los atributos cuya clave es sensible
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+// contextHandler agrega request_id, user_id y trace_id a cada registro a partir del context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if info := reqctx.From(ctx); info != nil {
+		if info.RequestID != "" {
+			r.AddAttrs(slog.String("request_id", info.RequestID))
+		}
+		if info.UserID != 0 {
+			r.AddAttrs(slog.Int("user_id", info.UserID))
+		}
+	}
+	if span := tracing.SpanFromContext(ctx); span != nil {
+		sc := span.SpanContext()
+		r.AddAttrs(slog.String("trace_id", sc.TraceID.String()), slog.String("span_id", sc.SpanID.String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
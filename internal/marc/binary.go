@@ -0,0 +1,126 @@
+package marc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Separadores del formato ISO 2709
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+)
+
+// leaderLen es el largo fijo de la cabecera (leader) de cada registro
+const leaderLen = 24
+
+// readBinary lee registros ISO 2709 consecutivos. Como cada registro declara
+// su largo, un registro dañado se informa a fn y se sigue con el próximo.
+func readBinary(r *bufio.Reader, fn func(n int, rec *Record, err error) error) error {
+	for n := 1; ; n++ {
+		// Algunos exportadores separan los registros con saltos de línea
+		if err := skipSpace(r); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		prefix, err := r.Peek(5)
+		if err != nil {
+			return fmt.Errorf("registro %d: archivo truncado", n)
+		}
+		length, err := strconv.Atoi(string(prefix))
+		if err != nil || length < leaderLen+1 {
+			return fmt.Errorf("registro %d: largo de registro inválido %q", n, prefix)
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("registro %d: archivo truncado", n)
+		}
+
+		rec, recErr := parseBinary(data)
+		if err := fn(n, rec, recErr); err != nil {
+			return err
+		}
+	}
+}
+
+// parseBinary decodifica un registro completo, terminador incluido
+func parseBinary(data []byte) (*Record, error) {
+	if data[len(data)-1] != recordTerminator {
+		return nil, errors.New("falta el terminador de registro")
+	}
+	if !utf8.Valid(data) {
+		return nil, errors.New("el registro no está en UTF-8 (MARC-8 no está soportado)")
+	}
+
+	rec := &Record{Leader: string(data[:leaderLen])}
+	base, err := strconv.Atoi(rec.Leader[12:17])
+	if err != nil || base <= leaderLen || base > len(data) {
+		return nil, errors.New("dirección base de datos inválida")
+	}
+
+	// El directorio son entradas de 12 bytes: etiqueta(3) largo(4) inicio(5)
+	dir := data[leaderLen : base-1]
+	if len(dir)%12 != 0 {
+		return nil, errors.New("directorio inválido")
+	}
+	for i := 0; i < len(dir); i += 12 {
+		tag := string(dir[i : i+3])
+		length, err1 := strconv.Atoi(string(dir[i+3 : i+7]))
+		start, err2 := strconv.Atoi(string(dir[i+7 : i+12]))
+		if err1 != nil || err2 != nil || base+start+length > len(data) || length < 1 {
+			return nil, fmt.Errorf("entrada de directorio inválida para el campo %s", tag)
+		}
+		// Se descarta el terminador de campo
+		field := data[base+start : base+start+length-1]
+
+		if tag < "010" {
+			rec.ControlFields = append(rec.ControlFields, ControlField{Tag: tag, Value: string(field)})
+			continue
+		}
+		if len(field) < 2 {
+			return nil, fmt.Errorf("campo %s sin indicadores", tag)
+		}
+		rec.DataFields = append(rec.DataFields, DataField{
+			Tag:       tag,
+			Ind1:      field[0],
+			Ind2:      field[1],
+			Subfields: splitSubfields(field[2:]),
+		})
+	}
+	return rec, nil
+}
+
+// splitSubfields separa "\x1faValor\x1fbOtro" en subcampos
+func splitSubfields(data []byte) []Subfield {
+	var out []Subfield
+	start := -1
+	for i := 0; i <= len(data); i++ {
+		if i < len(data) && data[i] != subfieldDelimiter {
+			continue
+		}
+		if start >= 0 && i > start {
+			out = append(out, Subfield{Code: data[start], Value: string(data[start+1 : i])})
+		}
+		start = i + 1
+	}
+	return out
+}
+
+func skipSpace(r *bufio.Reader) error {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b != '\n' && b != '\r' && b != ' ' && b != '\t' {
+			return r.UnreadByte()
+		}
+	}
+}
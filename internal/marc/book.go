@@ -0,0 +1,136 @@
+package marc
+
+import (
+	"practica-go/internal/model"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// roleAuthor es el rol ONIX que se usa para los autores (ver model.Contributor)
+const roleAuthor = "A01"
+
+// relatorRoles traduce los códigos y términos de función de MARC ($4 / $e
+// del campo 700) a roles ONIX. Sin función indicada se asume autor.
+var relatorRoles = map[string]string{
+	"aut": "A01", "autor": "A01", "author": "A01",
+	"edt": "B01", "editor": "B01",
+	"trl": "B06", "traductor": "B06", "translator": "B06",
+	"ill": "A12", "ilustrador": "A12", "illustrator": "A12",
+	"aui": "A24", "prologuista": "A15",
+}
+
+// Book mapea el registro a un libro:
+//
+//	245 $a $b   título y subtítulo
+//	100 / 700   autor principal y otros autores o colaboradores
+//	020 $a      ISBN
+//	264 / 260   $b editorial, $c año de publicación
+//	650 $a      materias, como etiquetas
+//
+// No valida el resultado: de eso se encarga el servicio.
+func (r *Record) Book() *model.Book {
+	b := &model.Book{}
+
+	if f := r.Fields("245"); len(f) > 0 {
+		b.Titulo = clean(f[0].Subfield('a'))
+		if sub := clean(f[0].Subfield('b')); sub != "" {
+			b.Titulo += ": " + sub
+		}
+	}
+
+	var authors []string
+	for _, f := range r.Fields("100") {
+		if name := personName(f); name != "" {
+			authors = append(authors, name)
+			b.Colaboradores = append(b.Colaboradores, model.Contributor{Nombre: name, Rol: roleAuthor})
+		}
+	}
+	for _, f := range r.Fields("700") {
+		name := personName(f)
+		if name == "" {
+			continue
+		}
+		role := relatorRole(f)
+		if role == roleAuthor {
+			authors = append(authors, name)
+		}
+		b.Colaboradores = append(b.Colaboradores, model.Contributor{Nombre: name, Rol: role})
+	}
+	b.Autor = strings.Join(authors, ", ")
+
+	for _, f := range r.Fields("020") {
+		// "$a 9789500430586 (rústica)": el ISBN es la primera palabra
+		if fields := strings.Fields(f.Subfield('a')); len(fields) > 0 {
+			b.ISBN = fields[0]
+			break
+		}
+	}
+
+	if f, ok := r.publication(); ok {
+		b.Editorial = clean(f.Subfield('b'))
+		b.Anio = year(f.Subfield('c'))
+	}
+
+	for _, f := range r.Fields("650") {
+		if tag := clean(f.Subfield('a')); tag != "" {
+			b.Etiquetas = append(b.Etiquetas, tag)
+		}
+	}
+	return b
+}
+
+// publication elige el 264 de publicación (indicador 2 = 1) o, en registros
+// anteriores a RDA, el 260
+func (r *Record) publication() (DataField, bool) {
+	for _, f := range r.Fields("264") {
+		if f.Ind2 == '1' {
+			return f, true
+		}
+	}
+	if f := r.Fields("260"); len(f) > 0 {
+		return f[0], true
+	}
+	return DataField{}, false
+}
+
+// personName devuelve el nombre en orden natural: con el primer indicador en 1
+// el $a viene como "Apellido, Nombre" y se invierte.
+func personName(f DataField) string {
+	name := clean(f.Subfield('a'))
+	if f.Ind1 == '1' {
+		if last, first, ok := strings.Cut(name, ", "); ok {
+			name = strings.TrimSpace(first) + " " + last
+		}
+	}
+	return name
+}
+
+// relatorRole busca la función en $4 y después en $e
+func relatorRole(f DataField) string {
+	for _, code := range []byte{'4', 'e'} {
+		for _, v := range f.SubfieldsOf(code) {
+			if role, ok := relatorRoles[strings.ToLower(clean(v))]; ok {
+				return role
+			}
+		}
+	}
+	return roleAuthor
+}
+
+// year extrae el primer año de cuatro dígitos ("c1960.", "[1960?]")
+func year(s string) int {
+	run := 0
+	for i, r := range s {
+		if !unicode.IsDigit(r) {
+			run = 0
+			continue
+		}
+		run++
+		if run == 4 {
+			y, _ := strconv.Atoi(s[i-3 : i+1])
+			return y
+		}
+	}
+	return 0
+}
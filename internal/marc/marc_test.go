@@ -0,0 +1,127 @@
+package marc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"practica-go/internal/model"
+	"reflect"
+	"testing"
+)
+
+// catalogo.mrc y catalogo.xml tienen los mismos dos registros, uno en cada
+// formato: los dos decodificadores tienen que dar los mismos libros
+var wantCatalog = []*model.Book{
+	{
+		// 245 $a/$b, 100 invertido, 700 con función en $e, 264 ind2=1 antes
+		// que el de copyright y ISBN seguido de la calificación
+		Titulo: "Rayuela: novela",
+		Autor:  "Julio Cortázar",
+		Colaboradores: []model.Contributor{
+			{Nombre: "Julio Cortázar", Rol: "A01"},
+			{Nombre: "Adolfo Bioy Casares", Rol: "A15"},
+		},
+		ISBN:      "9789500430586",
+		Editorial: "Sudamericana",
+		Anio:      1963,
+		Etiquetas: []string{"Novela argentina"},
+	},
+	{
+		// 100 sin invertir (ind1=0), 700 sin función (autor) y con $4 trl, 260
+		Titulo: "Ficciones",
+		Autor:  "Borges, Jorge Luis, Adolfo Bioy Casares",
+		Colaboradores: []model.Contributor{
+			{Nombre: "Borges, Jorge Luis", Rol: "A01"},
+			{Nombre: "Adolfo Bioy Casares", Rol: "A01"},
+			{Nombre: "Anthony Kerrigan", Rol: "B06"},
+		},
+		ISBN:      "9788437604947",
+		Editorial: "Sur",
+		Anio:      1944,
+	},
+}
+
+func TestReadFixtures(t *testing.T) {
+	for _, name := range []string{"catalogo.mrc", "catalogo.xml"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			var books []*model.Book
+			var ids []string
+			err = Read(f, func(n int, rec *Record, err error) error {
+				if err != nil {
+					t.Fatalf("registro %d: %v", n, err)
+				}
+				for _, c := range rec.ControlFields {
+					if c.Tag == "001" {
+						ids = append(ids, c.Value)
+					}
+				}
+				books = append(books, rec.Book())
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(ids, []string{"ar-001", "ar-002"}) {
+				t.Errorf("campos 001 = %v", ids)
+			}
+			if len(books) != len(wantCatalog) {
+				t.Fatalf("se leyeron %d registros, se esperaban %d", len(books), len(wantCatalog))
+			}
+			for i, want := range wantCatalog {
+				if !reflect.DeepEqual(books[i], want) {
+					t.Errorf("registro %d:\n got %+v\nwant %+v", i+1, books[i], want)
+				}
+			}
+		})
+	}
+}
+
+// Un registro binario dañado se informa y se sigue con el próximo
+func TestReadBinaryBadRecord(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "catalogo.mrc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Se rompe la dirección base del primer registro (posiciones 12-16)
+	copy(data[12:17], "99999")
+
+	var errs, ok int
+	err = Read(bytes.NewReader(data), func(n int, rec *Record, err error) error {
+		if err != nil {
+			errs++
+			return nil
+		}
+		ok++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs != 1 || ok != 1 {
+		t.Errorf("errores = %d, registros leídos = %d; se esperaba 1 y 1", errs, ok)
+	}
+}
+
+func TestYear(t *testing.T) {
+	tests := map[string]int{
+		"1960":     1960,
+		"c1960.":   1960,
+		"[1960?]":  1960,
+		"©2001":    2001,
+		"19--":     0,
+		"":         0,
+		"p2003, c": 2003,
+	}
+	for in, want := range tests {
+		if got := year(in); got != want {
+			t.Errorf("year(%q) = %d, se esperaba %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package marc
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Read decodifica registros MARC21 y llama a fn con cada uno a medida que se
+// leen. Detecta solo el formato: si el contenido empieza con '<' es MARCXML,
+// si no ISO 2709. Los errores de un registro puntual llegan a fn en err (y
+// rec es nil); los que impiden seguir leyendo los devuelve Read.
+func Read(r io.Reader, fn func(n int, rec *Record, err error) error) error {
+	br := bufio.NewReader(r)
+	if err := skipSpace(br); err == io.EOF {
+		return errors.New("el archivo está vacío")
+	} else if err != nil {
+		return err
+	}
+
+	first, err := br.Peek(1)
+	if err != nil {
+		return err
+	}
+	if first[0] == '<' {
+		return readXML(br, fn)
+	}
+	return readBinary(br, fn)
+}
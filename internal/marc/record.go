@@ -0,0 +1,79 @@
+// Package marc decodifica registros bibliográficos MARC21, tanto en el
+// formato binario ISO 2709 (.mrc) como en MARCXML, que es como exportan sus
+// catálogos la mayoría de las bibliotecas.
+package marc
+
+import (
+	"strings"
+)
+
+// Record es un registro MARC ya decodificado
+type Record struct {
+	Leader        string
+	ControlFields []ControlField
+	DataFields    []DataField
+}
+
+// ControlField es un campo 001-009: solo tiene valor, sin subcampos
+type ControlField struct {
+	Tag   string
+	Value string
+}
+
+// DataField es un campo 010-999 con indicadores y subcampos
+type DataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+// Subfield es un subcampo ($a, $b, ...) de un DataField
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// Fields devuelve los campos de datos con la etiqueta indicada, en orden
+func (r *Record) Fields(tag string) []DataField {
+	var out []DataField
+	for _, f := range r.DataFields {
+		if f.Tag == tag {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Subfield devuelve el primer subcampo con ese código ("" si no está)
+func (f DataField) Subfield(code byte) string {
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// SubfieldsOf devuelve todos los subcampos con ese código
+func (f DataField) SubfieldsOf(code byte) []string {
+	var out []string
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			out = append(out, s.Value)
+		}
+	}
+	return out
+}
+
+// clean quita la puntuación ISBD que MARC deja al final de cada subcampo
+// (ej. "El hacedor /" o "Buenos Aires :")
+func clean(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimRight(s, " /:;,=")
+	// El punto final se quita salvo que cierre una inicial ("Borges, J. L.")
+	if strings.HasSuffix(s, ".") && !(len(s) >= 3 && s[len(s)-3] == ' ') {
+		s = strings.TrimSuffix(s, ".")
+	}
+	return strings.TrimSpace(s)
+}
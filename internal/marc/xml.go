@@ -0,0 +1,89 @@
+package marc
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// xmlRecord es un <record> de MARCXML (http://www.loc.gov/MARC21/slim)
+type xmlRecord struct {
+	Leader   string `xml:"leader"`
+	Controls []struct {
+		Tag   string `xml:"tag,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"controlfield"`
+	Data []struct {
+		Tag       string `xml:"tag,attr"`
+		Ind1      string `xml:"ind1,attr"`
+		Ind2      string `xml:"ind2,attr"`
+		Subfields []struct {
+			Code  string `xml:"code,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"subfield"`
+	} `xml:"datafield"`
+}
+
+// readXML recorre los <record> de un documento MARCXML de a uno
+func readXML(r io.Reader, fn func(n int, rec *Record, err error) error) error {
+	dec := xml.NewDecoder(r)
+	n := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("XML inválido: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		n++
+		var x xmlRecord
+		if err := dec.DecodeElement(&x, &start); err != nil {
+			return fmt.Errorf("registro %d: %w", n, err)
+		}
+		rec, recErr := x.record()
+		if err := fn(n, rec, recErr); err != nil {
+			return err
+		}
+	}
+	if n == 0 {
+		return errors.New("el documento no tiene registros MARC")
+	}
+	return nil
+}
+
+// record pasa el registro XML al mismo Record que el formato binario
+func (x xmlRecord) record() (*Record, error) {
+	rec := &Record{Leader: x.Leader}
+	for _, c := range x.Controls {
+		rec.ControlFields = append(rec.ControlFields, ControlField{Tag: c.Tag, Value: c.Value})
+	}
+	for _, d := range x.Data {
+		if len(d.Tag) != 3 {
+			return nil, fmt.Errorf("etiqueta de campo inválida %q", d.Tag)
+		}
+		df := DataField{Tag: d.Tag, Ind1: indicator(d.Ind1), Ind2: indicator(d.Ind2)}
+		for _, s := range d.Subfields {
+			if len(s.Code) != 1 {
+				return nil, fmt.Errorf("código de subcampo inválido %q en el campo %s", s.Code, d.Tag)
+			}
+			df.Subfields = append(df.Subfields, Subfield{Code: s.Code[0], Value: s.Value})
+		}
+		rec.DataFields = append(rec.DataFields, df)
+	}
+	return rec, nil
+}
+
+// indicator toma el indicador de un atributo; vacío equivale a blanco
+func indicator(s string) byte {
+	if s == "" {
+		return ' '
+	}
+	return s[0]
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+)
+
+// Métricas de la aplicación registradas en Default
+var (
+	HTTPRequests = Default.NewCounterVec(
+		"http_requests_total",
+		"Cantidad de peticiones HTTP por ruta, método y código de estado.",
+		"route", "method", "status")
+
+	HTTPDuration = Default.NewHistogramVec(
+		"http_request_duration_seconds",
+		"Latencia de las peticiones HTTP por ruta, método y código de estado.",
+		nil, "route", "method", "status")
+
+	StoreQueryDuration = Default.NewHistogramVec(
+		"store_query_duration_seconds",
+		"Latencia de las consultas a la base por repositorio y método.",
+		nil, "store", "method")
+
+	LoginAttempts = Default.NewCounterVec(
+		"auth_login_attempts_total",
+		"Intentos de login por resultado (success o failure).",
+		"result")
+
+	WebhookDeliveries = Default.NewCounterVec(
+		"webhook_deliveries_total",
+		"Intentos de entrega de webhooks por resultado (delivered, retry o failed).",
+		"result")
+
+	OutboxEvents = Default.NewCounterVec(
+		"outbox_events_total",
+		"Eventos del outbox procesados por resultado (delivered o failed).",
+		"result")
+
+	EventsSlowClients = Default.NewCounterVec(
+		"events_slow_clients_total",
+		"Clientes de /events desconectados por no leer los eventos a tiempo.")
+
+	TrashPurged = Default.NewCounterVec(
+		"trash_purged_total",
+		"Filas borradas definitivamente al vencer la retención, por entidad (book o user).",
+		"entity")
+)
+
+// Handler expone el registro por defecto
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// ObserveStore registra la duración de una consulta desde start.
+// Se usa con defer: defer metrics.ObserveStore("BookStore", "GetAll", time.Now())
+func ObserveStore(store, method string, start time.Time) {
+	StoreQueryDuration.Observe(time.Since(start).Seconds(), store, method)
+}
+
+// RegisterDBStats expone las estadísticas del pool de conexiones de db
+func RegisterDBStats(db *sql.DB) {
+	stat := func(f func(sql.DBStats) float64) func() float64 {
+		return func() float64 { return f(db.Stats()) }
+	}
+
+	Default.NewGaugeFunc("db_max_open_connections", "Máximo de conexiones abiertas permitidas.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	Default.NewGaugeFunc("db_open_connections", "Conexiones abiertas (en uso y ociosas).",
+		stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	Default.NewGaugeFunc("db_in_use_connections", "Conexiones en uso.",
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	Default.NewGaugeFunc("db_idle_connections", "Conexiones ociosas.",
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	Default.NewCounterFunc("db_wait_count_total", "Cantidad total de esperas por una conexión.",
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	Default.NewCounterFunc("db_wait_duration_seconds_total", "Tiempo total esperando una conexión.",
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	Default.NewCounterFunc("db_max_idle_closed_total", "Conexiones cerradas por SetMaxIdleConns.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	Default.NewCounterFunc("db_max_idle_time_closed_total", "Conexiones cerradas por SetConnMaxIdleTime.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }))
+	Default.NewCounterFunc("db_max_lifetime_closed_total", "Conexiones cerradas por SetConnMaxLifetime.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
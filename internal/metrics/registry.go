@@ -0,0 +1,126 @@
+// Package metrics implementa métricas compatibles con el formato de
+// exposición de texto de Prometheus sin depender de librerías externas.
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// family es una métrica con nombre que sabe escribirse en formato texto
+type family interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry agrupa las métricas que se exponen en /metrics
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+// NewRegistry crea un registro vacío
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// Default es el registro usado por las métricas del paquete
+var Default = NewRegistry()
+
+// register agrega una métrica; registrar dos veces el mismo nombre es un error de programación
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[f.name()]; ok {
+		panic("metrics: métrica registrada dos veces: " + f.name())
+	}
+	r.families[f.name()] = f
+}
+
+// Write escribe todas las métricas ordenadas por nombre
+func (r *Registry) Write(out io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for n := range r.families {
+		names = append(names, n)
+	}
+	families := make([]family, 0, len(names))
+	sort.Strings(names)
+	for _, n := range names {
+		families = append(families, r.families[n])
+	}
+	r.mu.Unlock()
+
+	w := bufio.NewWriter(out)
+	for _, f := range families {
+		f.write(w)
+	}
+	return w.Flush()
+}
+
+// Handler expone el registro en formato de texto de Prometheus
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// writeHeader escribe las líneas # HELP y # TYPE de una métrica
+func writeHeader(w *bufio.Writer, name, help, typ string) {
+	w.WriteString("# HELP " + name + " " + escapeHelp(help) + "\n")
+	w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// writeSample escribe una muestra con sus etiquetas
+func writeSample(w *bufio.Writer, name string, labels, values []string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(l + `="` + escapeLabel(values[i]) + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
+
+// labelKey identifica una combinación de valores de etiquetas
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefBuckets son los límites de histograma por defecto, en segundos
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// CounterVec es un contador que solo crece, separado por etiquetas
+type CounterVec struct {
+	fname  string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labels []string
+	v      float64
+}
+
+// NewCounterVec crea y registra un contador en el registro indicado
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{fname: name, help: help, labels: labels, values: make(map[string]*counterValue)}
+	r.register(c)
+	return c
+}
+
+// Add suma delta a la serie con los valores de etiquetas indicados
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if len(values) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: %s espera %d etiquetas", c.fname, len(c.labels)))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := labelKey(values)
+	cv, ok := c.values[key]
+	if !ok {
+		cv = &counterValue{labels: append([]string(nil), values...)}
+		c.values[key] = cv
+	}
+	cv.v += delta
+}
+
+// Inc suma uno a la serie indicada
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+func (c *CounterVec) name() string { return c.fname }
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	writeHeader(w, c.fname, c.help, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		cv := c.values[key]
+		writeSample(w, c.fname, c.labels, cv.labels, cv.v)
+	}
+}
+
+// HistogramVec acumula observaciones en buckets acumulativos, separado por etiquetas
+type HistogramVec struct {
+	fname   string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labels []string
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec crea y registra un histograma; buckets nil usa DefBuckets
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	h := &HistogramVec{fname: name, help: help, labels: labels, buckets: buckets, values: make(map[string]*histogramValue)}
+	r.register(h)
+	return h
+}
+
+// Observe registra un valor en la serie con los valores de etiquetas indicados
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	if len(values) != len(h.labels) {
+		panic(fmt.Sprintf("metrics: %s espera %d etiquetas", h.fname, len(h.labels)))
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := labelKey(values)
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{labels: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hv
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			hv.counts[i]++
+		}
+	}
+	hv.sum += v
+	hv.count++
+}
+
+func (h *HistogramVec) name() string { return h.fname }
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	writeHeader(w, h.fname, h.help, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	labels := append(append([]string(nil), h.labels...), "le")
+	for _, key := range sortedKeys(h.values) {
+		hv := h.values[key]
+		values := append(append([]string(nil), hv.labels...), "")
+		for i, b := range h.buckets {
+			values[len(values)-1] = formatFloat(b)
+			writeSample(w, h.fname+"_bucket", labels, values, float64(hv.counts[i]))
+		}
+		values[len(values)-1] = "+Inf"
+		writeSample(w, h.fname+"_bucket", labels, values, float64(hv.count))
+		writeSample(w, h.fname+"_sum", h.labels, hv.labels, hv.sum)
+		writeSample(w, h.fname+"_count", h.labels, hv.labels, float64(hv.count))
+	}
+}
+
+// funcMetric es una métrica sin etiquetas cuyo valor se calcula al exponerla
+type funcMetric struct {
+	fname string
+	help  string
+	typ   string
+	fn    func() float64
+}
+
+// NewGaugeFunc registra un gauge cuyo valor lo devuelve fn
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{fname: name, help: help, typ: "gauge", fn: fn})
+}
+
+// NewCounterFunc registra un contador acumulado por otro componente (ej. sql.DB)
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{fname: name, help: help, typ: "counter", fn: fn})
+}
+
+func (f *funcMetric) name() string { return f.fname }
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	writeHeader(w, f.fname, f.help, f.typ)
+	writeSample(w, f.fname, nil, nil, f.fn())
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
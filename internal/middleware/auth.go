@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"practica-go/internal/reqctx"
+	"practica-go/internal/security"
+	"practica-go/internal/transport"
+	"strings"
+)
+
+// Auth valida el token de la cabecera Authorization (Bearer) y completa los
+// datos del usuario en el context. Las peticiones sin token siguen como
+// anónimas; un token inválido o expirado responde 401.
+func Auth(tokens *security.TokenIssuer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				transport.WriteError(w, http.StatusUnauthorized, "cabecera Authorization inválida")
+				return
+			}
+			claims, err := tokens.Verify(token, security.TokenAccess)
+			if err != nil {
+				transport.WriteError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			ctx := r.Context()
+			info := reqctx.From(ctx)
+			if info == nil {
+				ctx, info = reqctx.New(ctx, "")
+				r = r.WithContext(ctx)
+			}
+			info.UserID = claims.Subject
+			info.Role = claims.Role
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole deja pasar solo a usuarios autenticados con el rol indicado.
+// Con role vacío alcanza con estar autenticado.
+func RequireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := reqctx.From(r.Context())
+		if info == nil || info.UserID == 0 {
+			transport.WriteError(w, http.StatusUnauthorized, "se requiere autenticación")
+			return
+		}
+		if role != "" && info.Role != role {
+			transport.WriteError(w, http.StatusForbidden, "no tenés permisos para esta operación")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"practica-go/internal/metrics"
+	"strconv"
+	"time"
+)
+
+// Metrics cuenta las peticiones y mide su latencia por ruta y código de estado.
+// La ruta es el patrón registrado en el ServeMux (ej. "/books/"), no la URL
+// concreta, para no crear una serie por cada ID.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := newResponseRecorder(w)
+
+		next.ServeHTTP(rec, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(rec.status)
+		metrics.HTTPRequests.Inc(route, r.Method, status)
+		metrics.HTTPDuration.Observe(time.Since(start).Seconds(), route, r.Method, status)
+	})
+}
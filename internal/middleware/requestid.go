@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"practica-go/internal/reqctx"
+)
+
+// RequestIDHeader es la cabecera usada para propagar el ID de la petición
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID asigna un ID a cada petición (o reutiliza el que envía el cliente),
+// lo devuelve en la respuesta y lo deja disponible en el context
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx, _ := reqctx.New(r.Context(), id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
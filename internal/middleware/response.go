@@ -0,0 +1,33 @@
+// Package middleware contiene funcionalidades transversales a todos los
+// handlers HTTP: métricas, logs, autenticación, etc.
+package middleware
+
+import "net/http"
+
+// responseRecorder envuelve un ResponseWriter para conocer el código de
+// estado y la cantidad de bytes escritos por el handler
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap permite a http.ResponseController acceder al writer original (Flush, etc.)
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
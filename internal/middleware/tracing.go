@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"practica-go/internal/tracing"
+)
+
+// Tracing crea un span de servidor por petición. Si el cliente envía una
+// cabecera traceparent válida, el span continúa esa traza.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !tracing.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		if sc, err := tracing.ParseTraceParent(r.Header.Get(tracing.TraceParentHeader)); err == nil {
+			ctx = tracing.ContextWithRemoteParent(ctx, sc)
+		}
+		ctx, span := tracing.StartKind(ctx, r.Method, tracing.KindServer,
+			tracing.String("http.request.method", r.Method),
+			tracing.String("url.path", r.URL.Path),
+		)
+		defer span.End()
+
+		rec := newResponseRecorder(w)
+		r = r.WithContext(ctx)
+		next.ServeHTTP(rec, r)
+
+		// El patrón de la ruta solo se conoce después de que el mux la resolvió
+		if r.Pattern != "" {
+			span.Name = r.Method + " " + r.Pattern
+			span.SetAttr(tracing.String("http.route", r.Pattern))
+		}
+		span.SetAttr(tracing.Int("http.response.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.ErrorMsg = http.StatusText(rec.status)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"practica-go/internal/openapi"
+	"practica-go/internal/transport"
+	"sort"
+	"strings"
+)
+
+// Validate revisa el cuerpo de las peticiones contra el documento OpenAPI
+// antes de que lleguen al handler:
+//
+//   - el Content-Type tiene que ser uno de los documentados (415 si no)
+//   - los cuerpos JSON no pueden superar maxBody bytes (413)
+//   - los cuerpos JSON tienen que cumplir el esquema: sin campos
+//     desconocidos, con los tipos correctos y los obligatorios (400)
+//
+// Los cuerpos que no son JSON (CSV, XML, MARC) los limita cada handler.
+func Validate(maxBody int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, ok := openapi.FindRequestBody(r.Method, r.URL.Path)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			schema, mediaType, ok := body.Schema(r.Header.Get("Content-Type"))
+			if !ok {
+				types := body.MediaTypes()
+				sort.Strings(types)
+				transport.WriteError(w, http.StatusUnsupportedMediaType,
+					"Content-Type no soportado, se espera "+strings.Join(types, " o "))
+				return
+			}
+			if !openapi.IsJSON(mediaType) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+			if err != nil {
+				var tooBig *http.MaxBytesError
+				if errors.As(err, &tooBig) {
+					transport.WriteError(w, http.StatusRequestEntityTooLarge,
+						fmt.Sprintf("el cuerpo supera el máximo de %d bytes", maxBody))
+					return
+				}
+				transport.WriteError(w, http.StatusBadRequest, "no se pudo leer el cuerpo")
+				return
+			}
+			if len(bytes.TrimSpace(data)) == 0 {
+				if body.Required {
+					transport.WriteError(w, http.StatusBadRequest, "el cuerpo es obligatorio")
+					return
+				}
+			} else if err := openapi.ValidateJSON(schema, data); err != nil {
+				var verr *openapi.ValidationError
+				if errors.As(err, &verr) {
+					transport.WriteJSON(w, http.StatusBadRequest, map[string]any{
+						"error":   "payload inválido",
+						"details": verr.Fields,
+					})
+					return
+				}
+				transport.WriteError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			// El handler vuelve a leer el cuerpo ya validado
+			r.Body = io.NopCloser(bytes.NewReader(data))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Acciones que quedan en el registro de auditoría
+const (
+	AuditCreate        = "create"
+	AuditUpdate        = "update"
+	AuditDelete        = "delete"
+	AuditRestore       = "restore"
+	AuditResetPassword = "reset_password"
+)
+
+// Tipos de entidad auditados
+const (
+	EntityBook = "book"
+	EntityUser = "user"
+)
+
+// AuditEntry es un cambio registrado: quién lo hizo, sobre qué entidad y
+// cómo quedó. ActorID es 0 cuando no hay usuario (CLI, tareas internas).
+// Before y After son la entidad completa; Diff solo los campos que cambiaron.
+type AuditEntry struct {
+	ID         int               `json:"id"`
+	ActorID    int               `json:"actor_id"`
+	ActorRole  string            `json:"actor_role,omitempty"`
+	Action     string            `json:"action"`
+	EntityType string            `json:"entity_type"`
+	EntityID   int               `json:"entity_id"`
+	Before     json.RawMessage   `json:"before,omitempty"`
+	After      json.RawMessage   `json:"after,omitempty"`
+	Diff       map[string]Change `json:"diff,omitempty"`
+	RequestID  string            `json:"request_id,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// Change es el valor anterior y el nuevo de un campo
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
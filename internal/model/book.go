@@ -1,7 +1,34 @@
 package model
 
+import "time"
+
+// Book es un libro del catálogo. El tag openapi marca restricciones para el
+// esquema de la API (ver internal/openapi).
 type Book struct {
-	ID     int    `json:"id"`
-	Titulo string `json:"title"`
-	Autor  string `json:"author"`
+	ID            int           `json:"id" openapi:"readonly"`
+	Titulo        string        `json:"title" openapi:"required"`
+	Autor         string        `json:"author" openapi:"required"`
+	Anio          int           `json:"year,omitempty"`
+	Etiquetas     []string      `json:"tags,omitempty"`
+	ISBN          string        `json:"isbn,omitempty"`
+	Editorial     string        `json:"publisher,omitempty"`
+	Colaboradores []Contributor `json:"contributors,omitempty"`
+	Precios       []Price       `json:"prices,omitempty"`
+	Version       int           `json:"version" openapi:"readonly"`
+	DeletedAt     *time.Time    `json:"deleted_at,omitempty" openapi:"readonly"`
+}
+
+// Contributor es una persona o entidad que participó en el libro.
+// Rol usa los códigos de ONIX (lista 17): A01 autor, B01 editor, B06 traductor...
+type Contributor struct {
+	Nombre string `json:"name" openapi:"required"`
+	Rol    string `json:"role" openapi:"required"`
+}
+
+// Price es un precio de venta. Tipo usa los códigos de ONIX (lista 58),
+// por ejemplo 01 precio sin impuestos o 02 precio con impuestos.
+type Price struct {
+	Monto  float64 `json:"amount" openapi:"required"`
+	Moneda string  `json:"currency" openapi:"required"`
+	Tipo   string  `json:"type,omitempty"`
 }
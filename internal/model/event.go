@@ -0,0 +1,34 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Eventos de dominio que se guardan en el outbox
+const (
+	EventBookCreated    = "book.created"
+	EventBookUpdated    = "book.updated"
+	EventBookDeleted    = "book.deleted"
+	EventBookRestored   = "book.restored"
+	EventUserRegistered = "user.registered"
+	EventUserUpdated    = "user.updated"
+	EventUserDeleted    = "user.deleted"
+	EventUserRestored   = "user.restored"
+)
+
+// Events son todos los eventos, en el orden en que se documentan
+var Events = []string{
+	EventBookCreated, EventBookUpdated, EventBookDeleted, EventBookRestored,
+	EventUserRegistered, EventUserUpdated, EventUserDeleted, EventUserRestored,
+}
+
+// OutboxEvent es un evento de dominio guardado junto con la escritura que lo
+// generó. Payload son los datos del evento (el libro, el usuario, ...).
+type OutboxEvent struct {
+	ID        int
+	Event     string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+	Attempts  int
+}
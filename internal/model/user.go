@@ -1,9 +1,25 @@
 package model
 
+import (
+	"log/slog"
+	"time"
+)
+
 type User struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Role     string `json:"role"`
+	ID        int        `json:"id" openapi:"readonly"`
+	Username  string     `json:"username" openapi:"required"`
+	Email     string     `json:"email" openapi:"required"`
+	Password  string     `json:"password" openapi:"required,writeonly"`
+	Role      string     `json:"role" openapi:"readonly"`
+	Version   int        `json:"version" openapi:"readonly"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" openapi:"readonly"`
+}
+
+// LogValue hace que slog nunca escriba la contraseña del usuario
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("id", u.ID),
+		slog.String("username", u.Username),
+		slog.String("role", u.Role),
+	)
 }
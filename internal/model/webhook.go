@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook es la suscripción de un sistema externo a eventos del catálogo.
+// Secret firma las entregas; solo se devuelve al crear la suscripción.
+type Webhook struct {
+	ID        int       `json:"id" openapi:"readonly"`
+	URL       string    `json:"url" openapi:"required"`
+	Events    []string  `json:"events" openapi:"required"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at" openapi:"readonly"`
+}
+
+// Estados de una entrega de webhook
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed" // se agotaron los reintentos
+)
+
+// WebhookDelivery es el envío de un evento a un webhook, con el resultado
+// del último intento
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,167 @@
+// Package onix lee y escribe mensajes ONIX 3.0 (etiquetas de referencia),
+// el formato en que editoriales y distribuidores intercambian metadatos.
+//
+// Solo se mapean los bloques que tienen equivalente en model.Book: ISBN,
+// título, colaboradores, materias (como etiquetas), editorial, fecha de
+// publicación y precios. El resto del Product se ignora al leer.
+package onix
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Namespace es el espacio de nombres de ONIX 3.0 con etiquetas de referencia
+const Namespace = "http://ns.editeur.org/onix/3.0/reference"
+
+// Códigos de las listas de ONIX que se usan al leer y escribir
+const (
+	idProprietary = "01" // lista 5: identificador propio
+	idISBN10      = "02"
+	idISBN13      = "15"
+
+	notificationConfirmed = "03" // lista 1
+	NotificationDelete    = "05"
+
+	compositionSingle = "00" // lista 2
+	formBook          = "BA" // lista 150: libro sin especificar
+
+	titleDistinctive  = "01" // lista 15
+	titleLevelProduct = "01" // lista 149
+
+	RoleAuthor = "A01" // lista 17
+
+	subjectKeywords = "20" // lista 27: palabras clave separadas por ';'
+
+	publisherRole      = "01" // lista 45
+	publishingDateRole = "01" // lista 163: fecha de publicación
+	dateFormatYear     = "05" // lista 55: YYYY
+
+	supplierPublisher = "01" // lista 93
+	availableToOrder  = "20" // lista 65
+)
+
+// Product es la parte de <Product> que se mapea a un libro. Los mismos tipos
+// sirven para leer y para escribir; el orden de los campos es el del esquema.
+type Product struct {
+	RecordReference  string              `xml:"RecordReference"`
+	NotificationType string              `xml:"NotificationType"`
+	Identifiers      []productIdentifier `xml:"ProductIdentifier"`
+	Descriptive      descriptiveDetail   `xml:"DescriptiveDetail"`
+	Publishing       *publishingDetail   `xml:"PublishingDetail,omitempty"`
+	Supply           *productSupply      `xml:"ProductSupply,omitempty"`
+}
+
+type productIdentifier struct {
+	Type     string `xml:"ProductIDType"`
+	TypeName string `xml:"IDTypeName,omitempty"`
+	Value    string `xml:"IDValue"`
+}
+
+type descriptiveDetail struct {
+	Composition  string        `xml:"ProductComposition,omitempty"`
+	Form         string        `xml:"ProductForm,omitempty"`
+	Titles       []titleDetail `xml:"TitleDetail"`
+	Contributors []contributor `xml:"Contributor"`
+	Subjects     []subject     `xml:"Subject"`
+}
+
+type titleDetail struct {
+	Type     string         `xml:"TitleType"`
+	Elements []titleElement `xml:"TitleElement"`
+}
+
+type titleElement struct {
+	Level              string `xml:"TitleElementLevel"`
+	TitleText          string `xml:"TitleText,omitempty"`
+	TitlePrefix        string `xml:"TitlePrefix,omitempty"`
+	TitleWithoutPrefix string `xml:"TitleWithoutPrefix,omitempty"`
+}
+
+// text arma el título completo, con o sin artículo separado
+func (t titleElement) text() string {
+	if t.TitleText != "" {
+		return strings.TrimSpace(t.TitleText)
+	}
+	return strings.TrimSpace(t.TitlePrefix + " " + t.TitleWithoutPrefix)
+}
+
+type contributor struct {
+	SequenceNumber int      `xml:"SequenceNumber,omitempty"`
+	Roles          []string `xml:"ContributorRole"`
+	PersonName     string   `xml:"PersonName,omitempty"`
+	NamesBeforeKey string   `xml:"NamesBeforeKey,omitempty"`
+	KeyNames       string   `xml:"KeyNames,omitempty"`
+	CorporateName  string   `xml:"CorporateName,omitempty"`
+}
+
+// name elige la forma del nombre más completa disponible
+func (c contributor) name() string {
+	switch {
+	case c.PersonName != "":
+		return strings.TrimSpace(c.PersonName)
+	case c.KeyNames != "":
+		return strings.TrimSpace(c.NamesBeforeKey + " " + c.KeyNames)
+	default:
+		return strings.TrimSpace(c.CorporateName)
+	}
+}
+
+type subject struct {
+	Scheme  string `xml:"SubjectSchemeIdentifier"`
+	Heading string `xml:"SubjectHeadingText"`
+}
+
+type publishingDetail struct {
+	Publishers []publisher      `xml:"Publisher"`
+	Dates      []publishingDate `xml:"PublishingDate"`
+}
+
+type publisher struct {
+	Role string `xml:"PublishingRole"`
+	Name string `xml:"PublisherName"`
+}
+
+type publishingDate struct {
+	Role string   `xml:"PublishingDateRole"`
+	Date onixDate `xml:"Date"`
+}
+
+type onixDate struct {
+	Format string `xml:"dateformat,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+// year toma el año de una fecha ONIX (YYYY, YYYYMM, YYYYMMDD, ...)
+func (d publishingDate) year() int {
+	date := strings.TrimSpace(d.Date.Value)
+	if len(date) < 4 {
+		return 0
+	}
+	y, err := strconv.Atoi(date[:4])
+	if err != nil {
+		return 0
+	}
+	return y
+}
+
+type productSupply struct {
+	Details []supplyDetail `xml:"SupplyDetail"`
+}
+
+type supplyDetail struct {
+	Supplier     *supplier `xml:"Supplier,omitempty"`
+	Availability string    `xml:"ProductAvailability,omitempty"`
+	Prices       []price   `xml:"Price"`
+}
+
+type supplier struct {
+	Role string `xml:"SupplierRole"`
+	Name string `xml:"SupplierName"`
+}
+
+type price struct {
+	Type     string `xml:"PriceType,omitempty"`
+	Amount   string `xml:"PriceAmount"`
+	Currency string `xml:"CurrencyCode,omitempty"`
+}
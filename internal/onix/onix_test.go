@@ -0,0 +1,158 @@
+package onix
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"practica-go/internal/model"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// update reescribe los archivos golden con la salida actual:
+// go test ./internal/onix -update
+var update = flag.Bool("update", false, "reescribe los archivos golden de testdata")
+
+// readResult es lo que se compara contra el golden de cada mensaje leído
+type readResult struct {
+	Products []readProduct `json:"products"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type readProduct struct {
+	N         int         `json:"n"`
+	Reference string      `json:"record_reference"`
+	Deleted   bool        `json:"deleted,omitempty"`
+	Book      *model.Book `json:"book"`
+	Error     string      `json:"error,omitempty"`
+}
+
+func TestReadGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, ".golden.xml") {
+			continue
+		}
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			var got readResult
+			err = Read(f, func(n int, p *Product) error {
+				book, err := p.Book()
+				row := readProduct{N: n, Reference: p.RecordReference, Deleted: p.Deleted(), Book: book}
+				if err != nil {
+					row.Error = err.Error()
+				}
+				got.Products = append(got.Products, row)
+				return nil
+			})
+			if err != nil {
+				got.Error = err.Error()
+			}
+
+			data, err := json.MarshalIndent(got, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, strings.TrimSuffix(file, ".xml")+".golden.json", append(data, '\n'))
+		})
+	}
+}
+
+// sentDateTime es la fecha de envío de la cabecera, que cambia en cada export
+var sentDateTime = regexp.MustCompile(`<SentDateTime>[^<]*</SentDateTime>`)
+
+func TestWriterGolden(t *testing.T) {
+	got := writeBooks(t, readBooks(t, filepath.Join("testdata", "export.json")))
+	got = sentDateTime.ReplaceAll(got, []byte("<SentDateTime>20240101T0000Z</SentDateTime>"))
+	golden(t, filepath.Join("testdata", "export.golden.xml"), got)
+}
+
+// Lo que escribe Writer tiene que volver a leerse como los mismos libros
+func TestWriterRoundTrip(t *testing.T) {
+	books := readBooks(t, filepath.Join("testdata", "export.json"))
+	out := writeBooks(t, books)
+
+	var got []*model.Book
+	err := Read(bytes.NewReader(out), func(n int, p *Product) error {
+		b, err := p.Book()
+		got = append(got, b)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(books) {
+		t.Fatalf("se leyeron %d productos, se esperaban %d", len(got), len(books))
+	}
+	for i, want := range books {
+		want.ID = 0
+		if len(want.Colaboradores) == 0 {
+			want.Colaboradores = []model.Contributor{{Nombre: want.Autor, Rol: RoleAuthor}}
+		}
+		w, _ := json.Marshal(want)
+		g, _ := json.Marshal(got[i])
+		if !bytes.Equal(w, g) {
+			t.Errorf("libro %d:\n got  %s\n want %s", i, g, w)
+		}
+	}
+}
+
+func readBooks(t *testing.T, file string) []*model.Book {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var books []*model.Book
+	if err := json.Unmarshal(data, &books); err != nil {
+		t.Fatal(err)
+	}
+	return books
+}
+
+func writeBooks(t *testing.T, books []*model.Book) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "practica-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range books {
+		if err := w.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// golden compara got con el archivo golden, o lo reescribe con -update
+func golden(t *testing.T, file string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("falta el golden (correr con -update): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("la salida no coincide con %s\n--- got\n%s\n--- want\n%s", file, got, want)
+	}
+}
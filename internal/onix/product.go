@@ -0,0 +1,169 @@
+package onix
+
+import (
+	"fmt"
+	"practica-go/internal/model"
+	"strconv"
+	"strings"
+)
+
+// recordPrefix antecede al ID del libro en el RecordReference exportado
+const recordPrefix = "practica-go.libro."
+
+// ISBN devuelve el ISBN-13 del producto, o el ISBN-10 si es el único que trae
+func (p *Product) ISBN() string {
+	isbn := ""
+	for _, id := range p.Identifiers {
+		switch id.Type {
+		case idISBN13:
+			return strings.TrimSpace(id.Value)
+		case idISBN10:
+			isbn = strings.TrimSpace(id.Value)
+		}
+	}
+	return isbn
+}
+
+// Deleted indica si el emisor pide dar de baja el producto
+func (p *Product) Deleted() bool {
+	return p.NotificationType == NotificationDelete
+}
+
+// Book convierte el producto a un libro. No valida los datos: de eso se
+// encarga el servicio igual que con cualquier otro alta.
+func (p *Product) Book() (*model.Book, error) {
+	b := &model.Book{ISBN: p.ISBN()}
+
+	for _, t := range p.Descriptive.Titles {
+		if t.Type != titleDistinctive {
+			continue
+		}
+		for _, e := range t.Elements {
+			if e.Level == titleLevelProduct || b.Titulo == "" {
+				b.Titulo = e.text()
+			}
+		}
+	}
+
+	var authors []string
+	for _, c := range p.Descriptive.Contributors {
+		name := c.name()
+		if name == "" {
+			continue
+		}
+		for _, role := range c.Roles {
+			b.Colaboradores = append(b.Colaboradores, model.Contributor{Nombre: name, Rol: role})
+			if role == RoleAuthor {
+				authors = append(authors, name)
+			}
+		}
+	}
+	b.Autor = strings.Join(authors, ", ")
+	if b.Autor == "" && len(b.Colaboradores) > 0 {
+		// Obras sin autor (antologías, manuales): se usa el primer colaborador
+		b.Autor = b.Colaboradores[0].Nombre
+	}
+
+	for _, s := range p.Descriptive.Subjects {
+		if s.Scheme != subjectKeywords {
+			continue
+		}
+		for _, kw := range strings.Split(s.Heading, ";") {
+			if kw = strings.TrimSpace(kw); kw != "" {
+				b.Etiquetas = append(b.Etiquetas, kw)
+			}
+		}
+	}
+
+	if pd := p.Publishing; pd != nil {
+		for _, pub := range pd.Publishers {
+			if pub.Role == publisherRole || b.Editorial == "" {
+				b.Editorial = strings.TrimSpace(pub.Name)
+			}
+		}
+		for _, d := range pd.Dates {
+			if d.Role == publishingDateRole || b.Anio == 0 {
+				b.Anio = d.year()
+			}
+		}
+	}
+
+	if ps := p.Supply; ps != nil {
+		for _, sd := range ps.Details {
+			for _, pr := range sd.Prices {
+				amount, err := strconv.ParseFloat(strings.TrimSpace(pr.Amount), 64)
+				if err != nil {
+					return b, fmt.Errorf("precio inválido %q", pr.Amount)
+				}
+				b.Precios = append(b.Precios, model.Price{Monto: amount, Moneda: pr.Currency, Tipo: pr.Type})
+			}
+		}
+	}
+	return b, nil
+}
+
+// productFromBook arma el Product que representa a un libro del catálogo
+func productFromBook(b *model.Book, sender string) *Product {
+	id := strconv.Itoa(b.ID)
+	p := &Product{
+		RecordReference:  recordPrefix + id,
+		NotificationType: notificationConfirmed,
+		Identifiers:      []productIdentifier{{Type: idProprietary, TypeName: sender, Value: id}},
+		Descriptive: descriptiveDetail{
+			Composition: compositionSingle,
+			Form:        formBook,
+			Titles: []titleDetail{{
+				Type:     titleDistinctive,
+				Elements: []titleElement{{Level: titleLevelProduct, TitleText: b.Titulo}},
+			}},
+		},
+	}
+	if b.ISBN != "" {
+		p.Identifiers = append(p.Identifiers, productIdentifier{Type: idISBN13, Value: b.ISBN})
+	}
+
+	contributors := b.Colaboradores
+	if len(contributors) == 0 {
+		contributors = []model.Contributor{{Nombre: b.Autor, Rol: RoleAuthor}}
+	}
+	for i, c := range contributors {
+		p.Descriptive.Contributors = append(p.Descriptive.Contributors, contributor{
+			SequenceNumber: i + 1,
+			Roles:          []string{c.Rol},
+			PersonName:     c.Nombre,
+		})
+	}
+
+	if len(b.Etiquetas) > 0 {
+		p.Descriptive.Subjects = []subject{{Scheme: subjectKeywords, Heading: strings.Join(b.Etiquetas, "; ")}}
+	}
+
+	if b.Editorial != "" || b.Anio != 0 {
+		p.Publishing = &publishingDetail{}
+		if b.Editorial != "" {
+			p.Publishing.Publishers = []publisher{{Role: publisherRole, Name: b.Editorial}}
+		}
+		if b.Anio != 0 {
+			p.Publishing.Dates = []publishingDate{{
+				Role: publishingDateRole,
+				Date: onixDate{Format: dateFormatYear, Value: strconv.Itoa(b.Anio)},
+			}}
+		}
+	}
+
+	if len(b.Precios) > 0 {
+		sd := supplyDetail{
+			Supplier:     &supplier{Role: supplierPublisher, Name: sender},
+			Availability: availableToOrder,
+		}
+		for _, pr := range b.Precios {
+			sd.Prices = append(sd.Prices, price{
+				Type:     pr.Tipo,
+				Amount:   strconv.FormatFloat(pr.Monto, 'f', 2, 64),
+				Currency: pr.Moneda,
+			})
+		}
+		p.Supply = &productSupply{Details: []supplyDetail{sd}}
+	}
+	return p
+}
@@ -0,0 +1,87 @@
+package onix
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Read recorre un mensaje ONIX 3.0 y llama a fn con cada <Product> a medida
+// que se decodifica, sin cargar el archivo completo. n es la posición del
+// producto en el mensaje, empezando en 1.
+//
+// Si la cabecera trae DefaultCurrencyCode se aplica a los precios sin moneda.
+func Read(r io.Reader, fn func(n int, p *Product) error) error {
+	dec := xml.NewDecoder(r)
+	var defaultCurrency string
+	n := 0
+	root := false
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("XML inválido: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "ONIXMessage":
+			root = true
+			if release := attr(start, "release"); release != "" && release[0] != '3' {
+				return fmt.Errorf("se esperaba ONIX 3.0 y el mensaje es release %s", release)
+			}
+		case "ONIXmessage":
+			return errors.New("el mensaje usa etiquetas cortas; solo se admiten etiquetas de referencia")
+		case "Header":
+			var h struct {
+				DefaultCurrency string `xml:"DefaultCurrencyCode"`
+			}
+			if err := dec.DecodeElement(&h, &start); err != nil {
+				return fmt.Errorf("cabecera inválida: %w", err)
+			}
+			defaultCurrency = h.DefaultCurrency
+		case "Product":
+			if !root {
+				return errors.New("falta el elemento raíz ONIXMessage")
+			}
+			n++
+			p := &Product{}
+			if err := dec.DecodeElement(p, &start); err != nil {
+				return fmt.Errorf("producto %d: %w", n, err)
+			}
+			if p.Supply != nil {
+				for i := range p.Supply.Details {
+					for j, pr := range p.Supply.Details[i].Prices {
+						if pr.Currency == "" {
+							p.Supply.Details[i].Prices[j].Currency = defaultCurrency
+						}
+					}
+				}
+			}
+			if err := fn(n, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !root {
+		return errors.New("el archivo no es un mensaje ONIX")
+	}
+	return nil
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
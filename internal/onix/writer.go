@@ -0,0 +1,52 @@
+package onix
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"practica-go/internal/model"
+	"time"
+)
+
+// Writer escribe un mensaje ONIX 3.0 producto por producto. Cumple con
+// export.Writer, así se usa igual que los demás formatos de exportación.
+type Writer struct {
+	w      *bufio.Writer
+	enc    *xml.Encoder
+	sender string
+}
+
+// NewWriter escribe la cabecera del mensaje con sender como emisor
+func NewWriter(w io.Writer, sender string) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<ONIXMessage release="3.0" xmlns="` + Namespace + `">` + "\n")
+
+	enc := xml.NewEncoder(bw)
+	enc.Indent("  ", "  ")
+	h := header{SenderName: sender, SentDateTime: time.Now().UTC().Format("20060102T1504Z")}
+	if err := enc.EncodeElement(h, xml.StartElement{Name: xml.Name{Local: "Header"}}); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw, enc: enc, sender: sender}, nil
+}
+
+// header es la cabecera obligatoria del mensaje
+type header struct {
+	SenderName   string `xml:"Sender>SenderName"`
+	SentDateTime string `xml:"SentDateTime"`
+}
+
+// Write agrega el libro como un <Product>
+func (w *Writer) Write(b *model.Book) error {
+	return w.enc.EncodeElement(productFromBook(b, w.sender), xml.StartElement{Name: xml.Name{Local: "Product"}})
+}
+
+// Close cierra el elemento raíz y vacía el buffer
+func (w *Writer) Close() error {
+	if err := w.enc.Flush(); err != nil {
+		return err
+	}
+	w.w.WriteString("\n</ONIXMessage>\n")
+	return w.w.Flush()
+}
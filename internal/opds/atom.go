@@ -0,0 +1,193 @@
+package opds
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Tipos MIME de OPDS 1.2
+const (
+	TypeNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	TypeAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	TypeOpenSearch  = "application/opensearchdescription+xml"
+)
+
+type atomFeed struct {
+	XMLName      xml.Name    `xml:"feed"`
+	Xmlns        string      `xml:"xmlns,attr"`
+	XmlnsOPDS    string      `xml:"xmlns:opds,attr"`
+	XmlnsDC      string      `xml:"xmlns:dc,attr"`
+	XmlnsSearch  string      `xml:"xmlns:opensearch,attr"`
+	ID           string      `xml:"id"`
+	Title        string      `xml:"title"`
+	Updated      string      `xml:"updated"`
+	TotalResults int         `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage int         `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex   int         `xml:"opensearch:startIndex,omitempty"`
+	Links        []atomLink  `xml:"link"`
+	Entries      []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel    string      `xml:"rel,attr"`
+	Href   string      `xml:"href,attr"`
+	Type   string      `xml:"type,attr,omitempty"`
+	Title  string      `xml:"title,attr,omitempty"`
+	Prices []atomPrice `xml:"opds:price"`
+}
+
+type atomPrice struct {
+	Currency string `xml:"currencycode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomAuthor   `xml:"author"`
+	Identifier string         `xml:"dc:identifier,omitempty"`
+	Publisher  string         `xml:"dc:publisher,omitempty"`
+	Issued     string         `xml:"dc:issued,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    *atomContent   `xml:"content,omitempty"`
+	Links      []atomLink     `xml:"link"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// AtomType devuelve el tipo MIME OPDS 1.2 de un feed de esta clase
+func (k Kind) AtomType() string {
+	if k == Acquisition {
+		return TypeAcquisition
+	}
+	return TypeNavigation
+}
+
+// WriteAtom escribe f como feed OPDS 1.2
+func (c Catalog) WriteAtom(w io.Writer, f *Feed) error {
+	updated := time.Now().UTC().Format(time.RFC3339)
+	self := c.href(atomRoot, f.Path, f.query(f.Page))
+
+	feed := atomFeed{
+		Xmlns:       "http://www.w3.org/2005/Atom",
+		XmlnsOPDS:   "http://opds-spec.org/2010/catalog",
+		XmlnsDC:     "http://purl.org/dc/terms/",
+		XmlnsSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		ID:          self,
+		Title:       f.Title,
+		Updated:     updated,
+		Links: []atomLink{
+			{Rel: "self", Href: self, Type: f.Kind.AtomType()},
+			{Rel: "start", Href: c.href(atomRoot, "", nil), Type: TypeNavigation},
+			{Rel: "search", Href: c.href(atomRoot, "opensearch.xml", nil), Type: TypeOpenSearch},
+		},
+	}
+	if f.PageSize > 0 {
+		feed.TotalResults = f.Total
+		feed.ItemsPerPage = f.PageSize
+		feed.StartIndex = (f.Page-1)*f.PageSize + 1
+		for _, l := range f.pageLinks() {
+			feed.Links = append(feed.Links, atomLink{Rel: l.rel, Href: c.href(atomRoot, f.Path, f.query(l.page)), Type: f.Kind.AtomType()})
+		}
+	}
+
+	for _, e := range f.Entries {
+		rel := e.Rel
+		if rel == "" {
+			rel = relSubsection
+		}
+		href := c.href(atomRoot, e.Path, nil)
+		entry := atomEntry{
+			Title:   e.Title,
+			ID:      href,
+			Updated: updated,
+			Links:   []atomLink{{Rel: rel, Href: href, Type: e.Kind.AtomType()}},
+		}
+		if e.Summary != "" {
+			entry.Content = &atomContent{Type: "text", Text: e.Summary}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	for _, b := range f.Books {
+		entry := atomEntry{
+			Title:     b.Titulo,
+			ID:        bookURN(b),
+			Updated:   updated,
+			Publisher: b.Editorial,
+		}
+		if b.ISBN != "" {
+			entry.Identifier = "urn:isbn:" + b.ISBN
+		}
+		if b.Anio != 0 {
+			entry.Issued = strconv.Itoa(b.Anio)
+		}
+		for _, name := range authors(b) {
+			entry.Authors = append(entry.Authors, atomAuthor{Name: name})
+		}
+		for _, t := range b.Etiquetas {
+			entry.Categories = append(entry.Categories, atomCategory{Term: t, Label: t})
+		}
+		link := atomLink{Rel: acquisitionRel(b), Href: c.bookHref(b), Type: "application/json"}
+		for _, p := range b.Precios {
+			link.Prices = append(link.Prices, atomPrice{Currency: p.Moneda, Value: strconv.FormatFloat(p.Monto, 'f', 2, 64)})
+		}
+		entry.Links = []atomLink{link}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
+
+type openSearchDescription struct {
+	XMLName        xml.Name        `xml:"OpenSearchDescription"`
+	Xmlns          string          `xml:"xmlns,attr"`
+	ShortName      string          `xml:"ShortName"`
+	Description    string          `xml:"Description"`
+	InputEncoding  string          `xml:"InputEncoding"`
+	OutputEncoding string          `xml:"OutputEncoding"`
+	URLs           []openSearchURL `xml:"Url"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// WriteOpenSearch escribe la descripción OpenSearch de la búsqueda por
+// título o autor; los clientes OPDS reemplazan {searchTerms} y {startPage?}
+func (c Catalog) WriteOpenSearch(w io.Writer) error {
+	desc := openSearchDescription{
+		Xmlns:          "http://a9.com/-/spec/opensearch/1.1/",
+		ShortName:      c.Title,
+		Description:    "Buscar libros por título o autor",
+		InputEncoding:  "UTF-8",
+		OutputEncoding: "UTF-8",
+		URLs: []openSearchURL{{
+			Type:     TypeAcquisition,
+			Template: c.href(atomRoot, "search", nil) + "?q={searchTerms}&page={startPage?}",
+		}},
+	}
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(desc)
+}
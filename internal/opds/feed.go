@@ -0,0 +1,157 @@
+// Package opds genera catálogos OPDS para lectores de libros electrónicos y
+// apps de bibliotecas: OPDS 1.2 (Atom) y OPDS 2.0 (JSON) a partir del mismo
+// modelo de feed, más la descripción OpenSearch para la búsqueda.
+package opds
+
+import (
+	"net/url"
+	"practica-go/internal/model"
+	"strconv"
+)
+
+// Kind distingue los feeds de navegación de los de adquisición (libros)
+type Kind int
+
+const (
+	Navigation Kind = iota
+	Acquisition
+)
+
+// Feed es un feed independiente del formato. Las rutas son relativas a la
+// raíz del catálogo (ej. "new", "authors/Borges"), así el mismo Feed se
+// escribe en Atom bajo /opds y en JSON bajo /opds/v2.
+type Feed struct {
+	Title   string
+	Kind    Kind
+	Path    string
+	Query   url.Values // parámetros extra del feed (ej. la búsqueda)
+	Entries []NavEntry
+	Books   []*model.Book
+
+	// Paginación; PageSize 0 significa que el feed no está paginado
+	Page     int
+	PageSize int
+	Total    int
+}
+
+// NavEntry es una entrada de un feed de navegación
+type NavEntry struct {
+	Title   string
+	Path    string
+	Summary string
+	Kind    Kind   // tipo del feed al que apunta
+	Rel     string // "" = subsection
+}
+
+// Rels de OPDS que se usan en las entradas de navegación
+const (
+	RelSortNew    = "http://opds-spec.org/sort/new"
+	relSubsection = "subsection"
+)
+
+// Catalog escribe los feeds con enlaces absolutos a partir de Base
+// (esquema y host, ej. "https://libros.example.com").
+type Catalog struct {
+	Title string
+	Base  string
+}
+
+// Rutas de cada versión del catálogo
+const (
+	atomRoot = "/opds"
+	jsonRoot = "/opds/v2"
+)
+
+// href arma un enlace absoluto a path bajo root con los parámetros indicados
+func (c Catalog) href(root, path string, query url.Values) string {
+	u := c.Base + root
+	if path != "" {
+		u += "/" + path
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// bookHref es el recurso JSON del libro en la API
+func (c Catalog) bookHref(b *model.Book) string {
+	return c.Base + "/books/" + strconv.Itoa(b.ID)
+}
+
+// pageLink es un enlace de paginación (first, previous, next, last)
+type pageLink struct {
+	rel  string
+	page int
+}
+
+// pageLinks calcula los enlaces de paginación de f
+func (f *Feed) pageLinks() []pageLink {
+	if f.PageSize == 0 {
+		return nil
+	}
+	last := f.lastPage()
+	links := []pageLink{{"first", 1}}
+	if f.Page > 1 {
+		links = append(links, pageLink{"previous", min(f.Page-1, last)})
+	}
+	if f.Page < last {
+		links = append(links, pageLink{"next", f.Page + 1})
+	}
+	return append(links, pageLink{"last", last})
+}
+
+func (f *Feed) lastPage() int {
+	if f.Total == 0 {
+		return 1
+	}
+	return (f.Total + f.PageSize - 1) / f.PageSize
+}
+
+// query devuelve los parámetros del feed para la página indicada
+func (f *Feed) query(page int) url.Values {
+	q := url.Values{}
+	for k, v := range f.Query {
+		q[k] = v
+	}
+	if f.PageSize > 0 && page > 1 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	return q
+}
+
+// PathSegment escapa un valor para usarlo como segmento de ruta de un feed
+func PathSegment(s string) string {
+	return url.PathEscape(s)
+}
+
+// bookURN identifica al libro: por ISBN si lo tiene, si no por ID
+func bookURN(b *model.Book) string {
+	if b.ISBN != "" {
+		return "urn:isbn:" + b.ISBN
+	}
+	return "urn:practica-go:libro:" + strconv.Itoa(b.ID)
+}
+
+// authors devuelve los autores del libro: los colaboradores con rol de autor
+// o, si no hay, el campo autor
+func authors(b *model.Book) []string {
+	var out []string
+	for _, c := range b.Colaboradores {
+		if c.Rol == "A01" {
+			out = append(out, c.Nombre)
+		}
+	}
+	if len(out) == 0 && b.Autor != "" {
+		out = []string{b.Autor}
+	}
+	return out
+}
+
+// acquisitionRel es "buy" si el libro tiene precio y adquisición genérica si no
+func acquisitionRel(b *model.Book) string {
+	if len(b.Precios) > 0 {
+		return "http://opds-spec.org/acquisition/buy"
+	}
+	return "http://opds-spec.org/acquisition"
+}
@@ -0,0 +1,111 @@
+package opds
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// TypeJSON es el tipo MIME de OPDS 2.0
+const TypeJSON = "application/opds+json"
+
+type jsonFeed struct {
+	Metadata     jsonFeedMetadata  `json:"metadata"`
+	Links        []jsonLink        `json:"links"`
+	Navigation   []jsonLink        `json:"navigation,omitempty"`
+	Publications []jsonPublication `json:"publications,omitempty"`
+}
+
+type jsonFeedMetadata struct {
+	Title         string `json:"title"`
+	NumberOfItems int    `json:"numberOfItems,omitempty"`
+	ItemsPerPage  int    `json:"itemsPerPage,omitempty"`
+	CurrentPage   int    `json:"currentPage,omitempty"`
+}
+
+type jsonLink struct {
+	Rel        string          `json:"rel,omitempty"`
+	Href       string          `json:"href"`
+	Type       string          `json:"type,omitempty"`
+	Title      string          `json:"title,omitempty"`
+	Templated  bool            `json:"templated,omitempty"`
+	Properties *jsonProperties `json:"properties,omitempty"`
+}
+
+type jsonProperties struct {
+	Price *jsonPrice `json:"price,omitempty"`
+}
+
+type jsonPrice struct {
+	Value    float64 `json:"value"`
+	Currency string  `json:"currency"`
+}
+
+type jsonPublication struct {
+	Metadata jsonPublicationMetadata `json:"metadata"`
+	Links    []jsonLink              `json:"links"`
+}
+
+type jsonPublicationMetadata struct {
+	Type       string   `json:"@type"`
+	Title      string   `json:"title"`
+	Identifier string   `json:"identifier"`
+	Author     []string `json:"author,omitempty"`
+	Publisher  string   `json:"publisher,omitempty"`
+	Published  string   `json:"published,omitempty"`
+	Subject    []string `json:"subject,omitempty"`
+}
+
+// WriteJSON escribe f como feed OPDS 2.0
+func (c Catalog) WriteJSON(w io.Writer, f *Feed) error {
+	self := c.href(jsonRoot, f.Path, f.query(f.Page))
+	feed := jsonFeed{
+		Metadata: jsonFeedMetadata{Title: f.Title},
+		Links: []jsonLink{
+			{Rel: "self", Href: self, Type: TypeJSON},
+			{Rel: "start", Href: c.href(jsonRoot, "", nil), Type: TypeJSON},
+			{Rel: "search", Href: c.href(jsonRoot, "search", nil) + "{?query}", Type: TypeJSON, Templated: true},
+		},
+	}
+	if f.PageSize > 0 {
+		feed.Metadata.NumberOfItems = f.Total
+		feed.Metadata.ItemsPerPage = f.PageSize
+		feed.Metadata.CurrentPage = f.Page
+		for _, l := range f.pageLinks() {
+			feed.Links = append(feed.Links, jsonLink{Rel: l.rel, Href: c.href(jsonRoot, f.Path, f.query(l.page)), Type: TypeJSON})
+		}
+	}
+
+	for _, e := range f.Entries {
+		title := e.Title
+		if e.Summary != "" {
+			title += " (" + e.Summary + ")"
+		}
+		feed.Navigation = append(feed.Navigation, jsonLink{Rel: e.Rel, Href: c.href(jsonRoot, e.Path, nil), Type: TypeJSON, Title: title})
+	}
+
+	for _, b := range f.Books {
+		pub := jsonPublication{
+			Metadata: jsonPublicationMetadata{
+				Type:       "http://schema.org/Book",
+				Title:      b.Titulo,
+				Identifier: bookURN(b),
+				Author:     authors(b),
+				Publisher:  b.Editorial,
+				Subject:    b.Etiquetas,
+			},
+		}
+		if b.Anio != 0 {
+			pub.Metadata.Published = strconv.Itoa(b.Anio)
+		}
+		acq := jsonLink{Rel: acquisitionRel(b), Href: c.bookHref(b), Type: "application/json"}
+		if len(b.Precios) > 0 {
+			// OPDS 2.0 admite un precio por enlace: se usa el primero
+			acq.Properties = &jsonProperties{Price: &jsonPrice{Value: b.Precios[0].Monto, Currency: b.Precios[0].Moneda}}
+		}
+		pub.Links = []jsonLink{acq}
+		feed.Publications = append(feed.Publications, pub)
+	}
+
+	return json.NewEncoder(w).Encode(feed)
+}
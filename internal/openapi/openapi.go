@@ -0,0 +1,121 @@
+// Package openapi sirve el documento OpenAPI 3.1 de la API y verifica que
+// describa todas las rutas registradas en el servidor.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+var (
+	buildOnce sync.Once
+	spec      map[string]any
+	specJSON  []byte
+	specErr   error
+)
+
+// load arma el documento una sola vez: lee el YAML embebido y agrega los
+// esquemas generados desde los tipos Go
+func load() (map[string]any, []byte, error) {
+	buildOnce.Do(func() {
+		if specErr = yaml.Unmarshal(specYAML, &spec); specErr != nil {
+			specErr = fmt.Errorf("openapi.yaml inválido: %w", specErr)
+			return
+		}
+		components, _ := spec["components"].(map[string]any)
+		schemas, _ := components["schemas"].(map[string]any)
+		if schemas == nil {
+			specErr = fmt.Errorf("openapi.yaml no tiene components.schemas")
+			return
+		}
+		for name, t := range schemaTypes {
+			schemas[name] = Schema(t)
+		}
+		specJSON, specErr = json.MarshalIndent(spec, "", "  ")
+	})
+	return spec, specJSON, specErr
+}
+
+// JSON devuelve el documento completo serializado
+func JSON() ([]byte, error) {
+	_, data, err := load()
+	return data, err
+}
+
+// CheckRoutes verifica que cada patrón registrado en el ServeMux esté en el
+// documento, ya sea como path o como x-mux-pattern de un path
+func CheckRoutes(patterns []string) error {
+	doc, _, err := load()
+	if err != nil {
+		return err
+	}
+	documented := make(map[string]bool)
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		documented[path] = true
+		if m, ok := item.(map[string]any); ok {
+			if p, ok := m["x-mux-pattern"].(string); ok {
+				documented[p] = true
+			}
+		}
+	}
+
+	var missing []string
+	for _, p := range patterns {
+		if !documented[p] {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("rutas sin documentar en openapi.yaml: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Handler sirve el documento en /openapi.json
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := JSON()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// docsPage carga Swagger UI desde un CDN apuntando a /openapi.json
+const docsPage = `<!DOCTYPE html>
+<html lang="es">
+<head>
+  <meta charset="utf-8">
+  <title>Books-Store API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+// DocsHandler sirve la documentación interactiva en /docs
+func DocsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(docsPage))
+	})
+}
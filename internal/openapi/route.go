@@ -0,0 +1,98 @@
+package openapi
+
+import (
+	"mime"
+	"strings"
+)
+
+// RequestBody describe el cuerpo que acepta una operación del documento
+type RequestBody struct {
+	Required bool
+	// Content son los esquemas por tipo de contenido (application/json, text/csv, ...)
+	Content map[string]map[string]any
+}
+
+// MediaTypes lista los tipos de contenido aceptados, para los mensajes de error
+func (b *RequestBody) MediaTypes() []string {
+	types := make([]string, 0, len(b.Content))
+	for t := range b.Content {
+		types = append(types, t)
+	}
+	return types
+}
+
+// IsJSON indica si el tipo de contenido se valida como JSON
+func IsJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// FindRequestBody busca la operación que corresponde al método y la ruta y
+// devuelve su requestBody. Las rutas del documento con parámetros
+// (/books/{id}) aceptan cualquier valor en ese segmento.
+func FindRequestBody(method, path string) (*RequestBody, bool) {
+	doc, _, err := load()
+	if err != nil {
+		return nil, false
+	}
+	paths, _ := doc["paths"].(map[string]any)
+	item, ok := paths[path].(map[string]any)
+	if !ok {
+		item = matchTemplate(paths, path)
+	}
+	if item == nil {
+		return nil, false
+	}
+
+	op, _ := item[strings.ToLower(method)].(map[string]any)
+	body, _ := op["requestBody"].(map[string]any)
+	content, _ := body["content"].(map[string]any)
+	if len(content) == 0 {
+		return nil, false
+	}
+
+	rb := &RequestBody{Content: make(map[string]map[string]any)}
+	rb.Required, _ = body["required"].(bool)
+	for mediaType, c := range content {
+		m, _ := c.(map[string]any)
+		schema, _ := m["schema"].(map[string]any)
+		rb.Content[mediaType] = schema
+	}
+	return rb, true
+}
+
+// Schema devuelve el esquema para el Content-Type recibido, o false si ese
+// tipo no está entre los aceptados
+func (b *RequestBody) Schema(contentType string) (map[string]any, string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, "", false
+	}
+	schema, ok := b.Content[mediaType]
+	return schema, mediaType, ok
+}
+
+// matchTemplate compara la ruta con los paths que tienen parámetros
+func matchTemplate(paths map[string]any, path string) map[string]any {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for tmpl, item := range paths {
+		if !strings.Contains(tmpl, "{") {
+			continue
+		}
+		parts := strings.Split(strings.Trim(tmpl, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		match := true
+		for i, p := range parts {
+			if !strings.HasPrefix(p, "{") && p != segments[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			m, _ := item.(map[string]any)
+			return m
+		}
+	}
+	return nil
+}
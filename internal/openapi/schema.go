@@ -0,0 +1,110 @@
+package openapi
+
+import (
+	"encoding/json"
+	"practica-go/internal/health"
+	"practica-go/internal/model"
+	"practica-go/internal/query"
+	"practica-go/internal/security"
+	"practica-go/internal/service"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaTypes son los tipos Go cuyos esquemas se generan en
+// components.schemas. El documento los referencia por nombre.
+var schemaTypes = map[string]reflect.Type{
+	"Book":            reflect.TypeFor[model.Book](),
+	"User":            reflect.TypeFor[model.User](),
+	"ImportReport":    reflect.TypeFor[service.ImportReport](),
+	"TokenPair":       reflect.TypeFor[security.TokenPair](),
+	"HealthReport":    reflect.TypeFor[health.Report](),
+	"QueryError":      reflect.TypeFor[query.Error](),
+	"Webhook":         reflect.TypeFor[model.Webhook](),
+	"WebhookDelivery": reflect.TypeFor[model.WebhookDelivery](),
+	"AuditEntry":      reflect.TypeFor[model.AuditEntry](),
+}
+
+// Schema genera el JSON Schema de un tipo Go a partir de sus tags json.
+// El tag openapi agrega restricciones que el tipo no expresa:
+//
+//	openapi:"required"   el campo es obligatorio en las peticiones
+//	openapi:"readonly"   solo aparece en respuestas (ej. id)
+//	openapi:"writeonly"  solo se envía, nunca se devuelve (ej. password)
+//
+// Los objetos no admiten propiedades desconocidas.
+func Schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Tipos con su propio formato JSON
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeFor[json.RawMessage]():
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": Schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": Schema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := Schema(f.Type)
+		for _, opt := range strings.Split(f.Tag.Get("openapi"), ",") {
+			switch opt {
+			case "required":
+				required = append(required, name)
+			case "readonly":
+				prop["readOnly"] = true
+			case "writeonly":
+				prop["writeOnly"] = true
+			}
+		}
+		props[name] = prop
+	}
+
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
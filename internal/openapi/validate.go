@@ -0,0 +1,210 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// FieldError es un problema de validación en un campo del cuerpo
+type FieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+// ValidationError agrupa todos los problemas encontrados en un cuerpo
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Error
+	}
+	return "payload inválido: " + strings.Join(msgs, "; ")
+}
+
+// ValidateJSON valida data contra un esquema del documento. Soporta el
+// subconjunto de JSON Schema que usa la API: type, properties, required,
+// additionalProperties, items, enum, minimum y $ref a components. Las
+// propiedades readOnly se aceptan (los clientes suelen reenviar el id) pero
+// el handler las ignora.
+func ValidateJSON(schema map[string]any, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return &ValidationError{Fields: []FieldError{{Field: "$", Error: "JSON inválido: " + err.Error()}}}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return &ValidationError{Fields: []FieldError{{Field: "$", Error: "hay contenido después del objeto JSON"}}}
+	}
+
+	doc, _, err := load()
+	if err != nil {
+		return err
+	}
+	vr := &validator{doc: doc}
+	vr.value("$", schema, v)
+	if len(vr.errs) == 0 {
+		return nil
+	}
+	sort.Slice(vr.errs, func(i, j int) bool { return vr.errs[i].Field < vr.errs[j].Field })
+	return &ValidationError{Fields: vr.errs}
+}
+
+type validator struct {
+	doc  map[string]any
+	errs []FieldError
+}
+
+func (vr *validator) fail(path, format string, args ...any) {
+	vr.errs = append(vr.errs, FieldError{Field: path, Error: fmt.Sprintf(format, args...)})
+}
+
+// resolve sigue un $ref local (#/components/schemas/Book)
+func (vr *validator) resolve(schema map[string]any) map[string]any {
+	for {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		var node any = vr.doc
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, _ := node.(map[string]any)
+			node = m[part]
+		}
+		next, ok := node.(map[string]any)
+		if !ok {
+			return map[string]any{}
+		}
+		schema = next
+	}
+}
+
+func (vr *validator) value(path string, schema map[string]any, v any) {
+	schema = vr.resolve(schema)
+
+	if enum, ok := schema["enum"].([]any); ok && !inEnum(enum, v) {
+		vr.fail(path, "valor no permitido")
+		return
+	}
+
+	typ := schema["type"]
+	// OpenAPI 3.1 admite una lista de tipos, ej. [string, "null"]
+	if types, ok := typ.([]any); ok {
+		typ = nil
+		for _, t := range types {
+			if t == "null" {
+				if v == nil {
+					return
+				}
+				continue
+			}
+			typ = t
+		}
+	}
+
+	switch typ {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			vr.fail(path, "se esperaba un objeto")
+			return
+		}
+		vr.object(path, schema, obj)
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			vr.fail(path, "se esperaba una lista")
+			return
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range arr {
+			vr.value(fmt.Sprintf("%s[%d]", path, i), items, item)
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			vr.fail(path, "se esperaba un texto")
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			vr.fail(path, "se esperaba true o false")
+		}
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			vr.fail(path, "se esperaba un número")
+			return
+		}
+		if typ == "integer" {
+			if _, err := n.Int64(); err != nil {
+				vr.fail(path, "se esperaba un número entero")
+				return
+			}
+		}
+		if min, ok := schema["minimum"].(int); ok {
+			if f, _ := n.Float64(); f < float64(min) {
+				vr.fail(path, "debe ser mayor o igual a %d", min)
+			}
+		}
+	}
+}
+
+func (vr *validator) object(path string, schema map[string]any, obj map[string]any) {
+	props, _ := schema["properties"].(map[string]any)
+	for _, name := range requiredNames(schema) {
+		if _, ok := obj[name]; !ok {
+			vr.fail(join(path, name), "es obligatorio")
+		}
+	}
+	for name, v := range obj {
+		prop, ok := props[name].(map[string]any)
+		if !ok {
+			if extra, ok := schema["additionalProperties"].(map[string]any); ok {
+				vr.value(join(path, name), extra, v)
+			} else if schema["additionalProperties"] == false {
+				vr.fail(join(path, name), "campo desconocido")
+			}
+			continue
+		}
+		vr.value(join(path, name), prop, v)
+	}
+}
+
+// requiredNames lee "required", que según el origen es []string o []any
+func requiredNames(schema map[string]any) []string {
+	switch r := schema["required"].(type) {
+	case []string:
+		return r
+	case []any:
+		names := make([]string, 0, len(r))
+		for _, n := range r {
+			if s, ok := n.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func join(path, name string) string {
+	if path == "$" {
+		return name
+	}
+	return path + "." + name
+}
+
+func inEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+// Package outbox reparte los eventos de dominio que los repositorios guardan
+// en la tabla outbox dentro de la misma transacción que cada escritura. Así
+// un evento se publica si y solo si el cambio se confirmó, aunque el proceso
+// se caiga justo después del commit.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"practica-go/internal/health"
+	"practica-go/internal/metrics"
+	"practica-go/internal/model"
+	"practica-go/internal/store"
+	"time"
+)
+
+const (
+	// batchSize es cuántos eventos se leen por consulta
+	batchSize = 100
+	// purgeEvery es cada cuánto se borran los eventos ya entregados
+	purgeEvery = time.Hour
+	// maxErrorLen recorta el error guardado en la fila
+	maxErrorLen = 500
+)
+
+// Handler procesa un evento (lo encola para los webhooks, lo publica en
+// /events, ...). La entrega es al menos una vez: si un handler falla el
+// evento se reintenta con todos, así que un handler puede ver duplicados.
+type Handler func(ctx context.Context, e *model.OutboxEvent) error
+
+type namedHandler struct {
+	name string
+	fn   Handler
+}
+
+// Relay lee los eventos pendientes en orden y se los pasa a los handlers
+// registrados. Un evento queda entregado cuando todos lo procesaron; si uno
+// falla, el relay se detiene en ese evento y lo reintenta en la próxima
+// pasada, para no entregar un evento posterior antes que uno anterior.
+type Relay struct {
+	store     store.OutboxStore
+	handlers  []namedHandler
+	retention time.Duration
+	heartbeat health.Heartbeat
+}
+
+// New crea un relay; los eventos entregados se borran después de retention
+func New(st store.OutboxStore, retention time.Duration) *Relay {
+	return &Relay{store: st, retention: retention}
+}
+
+// Register agrega un handler. Se llama antes de Run.
+func (r *Relay) Register(name string, h Handler) {
+	r.handlers = append(r.handlers, namedHandler{name: name, fn: h})
+}
+
+// Check es el chequeo de readiness del relay: falla si no pudo leer el
+// outbox en los últimos maxAge
+func (r *Relay) Check(maxAge time.Duration) health.CheckFunc {
+	return r.heartbeat.Check(maxAge)
+}
+
+// Run revisa el outbox cada interval hasta que se cancele ctx
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	purge := time.NewTicker(purgeEvery)
+	defer purge.Stop()
+
+	for {
+		r.flush(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-purge.C:
+			r.purge(ctx)
+		}
+	}
+}
+
+// flush entrega todos los eventos pendientes, de a batchSize
+func (r *Relay) flush(ctx context.Context) {
+	for ctx.Err() == nil {
+		pending, err := r.store.Pending(ctx, batchSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "no se pudo leer el outbox", slog.Any("error", err))
+			return
+		}
+		r.heartbeat.Beat()
+		for _, e := range pending {
+			if !r.deliver(ctx, e) {
+				return
+			}
+		}
+		if len(pending) < batchSize {
+			return
+		}
+	}
+}
+
+// deliver pasa el evento por todos los handlers y devuelve si quedó entregado
+func (r *Relay) deliver(ctx context.Context, e *model.OutboxEvent) bool {
+	for _, h := range r.handlers {
+		if err := h.fn(ctx, e); err != nil {
+			metrics.OutboxEvents.Inc("failed")
+			slog.WarnContext(ctx, "no se pudo procesar el evento del outbox",
+				slog.Int("outbox_id", e.ID),
+				slog.String("event", e.Event),
+				slog.String("handler", h.name),
+				slog.Int("attempt", e.Attempts+1),
+				slog.Any("error", err),
+			)
+			reason := truncate(fmt.Sprintf("%s: %v", h.name, err))
+			if err := r.store.RecordFailure(ctx, e.ID, reason); err != nil {
+				slog.ErrorContext(ctx, "no se pudo guardar el fallo del outbox", slog.Int("outbox_id", e.ID), slog.Any("error", err))
+			}
+			return false
+		}
+	}
+
+	// Si esto falla el evento se vuelve a entregar en la próxima pasada
+	if err := r.store.MarkDelivered(ctx, e.ID, time.Now()); err != nil {
+		slog.ErrorContext(ctx, "no se pudo marcar el evento como entregado", slog.Int("outbox_id", e.ID), slog.Any("error", err))
+		return false
+	}
+	metrics.OutboxEvents.Inc("delivered")
+	return true
+}
+
+func (r *Relay) purge(ctx context.Context) {
+	n, err := r.store.Purge(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		slog.ErrorContext(ctx, "no se pudo purgar el outbox", slog.Any("error", err))
+		return
+	}
+	if n > 0 {
+		slog.InfoContext(ctx, "outbox purgado", slog.Int("events", n))
+	}
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLen {
+		return s[:maxErrorLen]
+	}
+	return s
+}
@@ -0,0 +1,274 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Operation es una operación de JSON Patch. From solo se usa en move y copy;
+// Value en add, replace y test.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ErrTestFailed es el error de una operación test cuyo valor no coincide
+var ErrTestFailed = errors.New("la operación test no coincide con el valor actual")
+
+// ApplyJSONPatch aplica un JSON Patch (RFC 6902). Las operaciones se aplican
+// en orden y si una falla no se aplica ninguna.
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, errors.New("el parche tiene que ser una lista de operaciones")
+	}
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		if target, err = apply(target, op); err != nil {
+			return nil, fmt.Errorf("operación %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+// apply aplica una operación y devuelve el documento resultante (cambia
+// entero si la ruta es la raíz)
+func apply(doc any, op Operation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("falta value")
+		}
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, errors.New("value no es JSON válido")
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if doc, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		return remove(doc, path)
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, errors.New("no se puede mover un valor adentro de sí mismo")
+			}
+			if doc, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			// El valor copiado no puede compartir mapas ni slices con el original
+			value = clone(value)
+		}
+		return add(doc, path, value)
+
+	default:
+		return nil, fmt.Errorf("operación desconocida %q", op.Op)
+	}
+}
+
+// parsePointer separa un JSON Pointer (RFC 6901) en sus segmentos, ya sin
+// los escapes ~1 (/) y ~0 (~)
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("la ruta %q tiene que empezar con /", p)
+	}
+	parts := strings.Split(p[1:], "/")
+	for i, part := range parts {
+		parts[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+	}
+	return parts, nil
+}
+
+// get devuelve el valor en path
+func get(doc any, path []string) (any, error) {
+	for _, key := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("no existe el campo %q", key)
+			}
+			doc = v
+		case []any:
+			i, err := index(key, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("%q no está dentro de un objeto ni de una lista", key)
+		}
+	}
+	return doc, nil
+}
+
+// add agrega value en path: en un objeto agrega o reemplaza el campo y en
+// una lista lo inserta en esa posición ("-" agrega al final)
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[key] = value
+		return doc, nil
+	case []any:
+		i := len(node)
+		if key != "-" {
+			if i, err = index(key, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		node = append(node[:i], append([]any{value}, node[i:]...)...)
+		return setParent(doc, path, node)
+	default:
+		return nil, fmt.Errorf("%q no está dentro de un objeto ni de una lista", key)
+	}
+}
+
+// remove borra el valor en path, que tiene que existir
+func remove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		if _, ok := node[key]; !ok {
+			return nil, fmt.Errorf("no existe el campo %q", key)
+		}
+		delete(node, key)
+		return doc, nil
+	case []any:
+		i, err := index(key, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node[:i:i], node[i+1:]...)
+		return setParent(doc, path, node)
+	default:
+		return nil, fmt.Errorf("%q no está dentro de un objeto ni de una lista", key)
+	}
+}
+
+// setParent reemplaza la lista que contiene el último segmento de path,
+// porque al insertar o borrar el slice puede cambiar
+func setParent(doc any, path []string, list []any) (any, error) {
+	parentPath := path[:len(path)-1]
+	if len(parentPath) == 0 {
+		return list, nil
+	}
+	grand, err := get(doc, parentPath[:len(parentPath)-1])
+	if err != nil {
+		return nil, err
+	}
+	key := parentPath[len(parentPath)-1]
+	switch node := grand.(type) {
+	case map[string]any:
+		node[key] = list
+	case []any:
+		i, _ := index(key, len(node)-1)
+		node[i] = list
+	}
+	return doc, nil
+}
+
+// index lee el índice de una lista, entre 0 y max
+func index(key string, max int) (int, error) {
+	if key == "-" || (len(key) > 1 && key[0] == '0') {
+		return 0, fmt.Errorf("índice inválido %q", key)
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("índice inválido %q", key)
+	}
+	if i > max {
+		return 0, fmt.Errorf("el índice %d está fuera de la lista", i)
+	}
+	return i, nil
+}
+
+// isPrefix indica si prefix es el comienzo de path
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// clone copia en profundidad un valor decodificado de JSON
+func clone(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for k, e := range v {
+			c[k] = clone(e)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, e := range v {
+			c[i] = clone(e)
+		}
+		return c
+	default:
+		return v
+	}
+}
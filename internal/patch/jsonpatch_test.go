@@ -0,0 +1,137 @@
+package patch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string
+	}{
+		// Apéndice A del RFC 6902
+		{"add en objeto", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add en lista", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"remove en objeto", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove en lista", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move entre objetos",
+			`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move dentro de una lista", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"test que coincide", `{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{"add de un objeto anidado", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"campos desconocidos en la operación", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`},
+		{"test con ~01", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{"add de una lista al final", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+
+		// Escapes de JSON Pointer
+		{"~1 es una barra", `{"a/b":1}`, `[{"op":"replace","path":"/a~1b","value":2}]`, `{"a/b":2}`},
+		{"~0 es una tilde", `{"m~n":1,"x":2}`, `[{"op":"remove","path":"/m~0n"}]`, `{"x":2}`},
+		{"escapes en from", `{"a/b":1}`, `[{"op":"move","from":"/a~1b","path":"/c~0d"}]`, `{"c~d":1}`},
+
+		// Índices en los bordes de la lista
+		{"add en el primer índice", `{"l":[1,2]}`, `[{"op":"add","path":"/l/0","value":0}]`, `{"l":[0,1,2]}`},
+		{"add en el índice igual al largo", `{"l":[1,2]}`, `[{"op":"add","path":"/l/2","value":3}]`, `{"l":[1,2,3]}`},
+		{"add en una lista vacía", `{"l":[]}`, `[{"op":"add","path":"/l/0","value":1}]`, `{"l":[1]}`},
+		{"remove del último", `{"l":[1,2]}`, `[{"op":"remove","path":"/l/1"}]`, `{"l":[1]}`},
+		{"lista anidada en lista", `{"l":[[1,2],[3]]}`, `[{"op":"add","path":"/l/1/0","value":0}]`, `{"l":[[1,2],[0,3]]}`},
+
+		// copy y move
+		{"copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{"copy no comparte el valor", `{"a":{"b":1}}`,
+			`[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`,
+			`{"a":{"b":1},"c":{"b":2}}`},
+		{"copy a una lista", `{"a":1,"l":[0]}`, `[{"op":"copy","from":"/a","path":"/l/-"}]`, `{"a":1,"l":[0,1]}`},
+		{"move sobre sí mismo", `{"a":1}`, `[{"op":"move","from":"/a","path":"/a"}]`, `{"a":1}`},
+		{"move reemplaza el destino", `{"a":1,"b":2}`, `[{"op":"move","from":"/a","path":"/b"}]`, `{"b":1}`},
+
+		// La raíz
+		{"replace de la raíz", `{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+		{"test de la raíz", `{"a":1}`, `[{"op":"test","path":"","value":{"a":1}}]`, `{"a":1}`},
+		{"sin operaciones", `{"a":1}`, `[]`, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			equalJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, wantErr string
+	}{
+		{"no es una lista", `{}`, `{"op":"add"}`, "el parche tiene que ser una lista de operaciones"},
+		{"operación desconocida", `{}`, `[{"op":"merge","path":"/a"}]`, `operación desconocida "merge"`},
+		{"falta value", `{}`, `[{"op":"add","path":"/a"}]`, "falta value"},
+		{"ruta sin barra", `{"a":1}`, `[{"op":"remove","path":"a"}]`, `la ruta "a" tiene que empezar con /`},
+		{"from sin barra", `{"a":1}`, `[{"op":"copy","from":"a","path":"/b"}]`, "from: la ruta"},
+
+		// RFC 6902 A.9, A.12 y A.15
+		{"test que no coincide", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ErrTestFailed.Error()},
+		{"add con el padre inexistente", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, `no existe el campo "baz"`},
+		{"test de número contra texto", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":"10"}]`, ErrTestFailed.Error()},
+
+		// Rutas que no existen
+		{"remove de un campo inexistente", `{"a":1}`, `[{"op":"remove","path":"/b"}]`, `no existe el campo "b"`},
+		{"replace de un campo inexistente", `{"a":1}`, `[{"op":"replace","path":"/b","value":1}]`, `no existe el campo "b"`},
+		{"test de un campo inexistente", `{"a":1}`, `[{"op":"test","path":"/b","value":1}]`, `no existe el campo "b"`},
+		{"move desde un campo inexistente", `{"a":1}`, `[{"op":"move","from":"/b","path":"/c"}]`, `from: no existe el campo "b"`},
+		{"ruta dentro de un escalar", `{"a":1}`, `[{"op":"add","path":"/a/b","value":1}]`, `"b" no está dentro de un objeto ni de una lista`},
+		{"~1 no es una barra literal", `{"a":{"b":1}}`, `[{"op":"remove","path":"/a~1b"}]`, `no existe el campo "a/b"`},
+
+		// Índices fuera de rango o mal escritos
+		{"add después del largo", `{"l":[1,2]}`, `[{"op":"add","path":"/l/3","value":3}]`, "el índice 3 está fuera de la lista"},
+		{"remove en el largo", `{"l":[1,2]}`, `[{"op":"remove","path":"/l/2"}]`, "el índice 2 está fuera de la lista"},
+		{"replace fuera de rango", `{"l":[1,2]}`, `[{"op":"replace","path":"/l/5","value":0}]`, "el índice 5 está fuera de la lista"},
+		{"test fuera de rango", `{"l":[1,2]}`, `[{"op":"test","path":"/l/2","value":0}]`, "el índice 2 está fuera de la lista"},
+		{"remove en una lista vacía", `{"l":[]}`, `[{"op":"remove","path":"/l/0"}]`, "el índice 0 está fuera de la lista"},
+		{"índice negativo", `{"l":[1,2]}`, `[{"op":"remove","path":"/l/-1"}]`, `índice inválido "-1"`},
+		{"índice con cero adelante", `{"l":[1,2]}`, `[{"op":"replace","path":"/l/01","value":0}]`, `índice inválido "01"`},
+		{"índice no numérico", `{"l":[1,2]}`, `[{"op":"remove","path":"/l/a"}]`, `índice inválido "a"`},
+		{"- solo vale para add", `{"l":[1,2]}`, `[{"op":"remove","path":"/l/-"}]`, `índice inválido "-"`},
+		{"copy desde fuera de rango", `{"l":[1]}`, `[{"op":"copy","from":"/l/1","path":"/a"}]`, "from: el índice 1 está fuera de la lista"},
+
+		{"move adentro de sí mismo", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`, "no se puede mover un valor adentro de sí mismo"},
+		{"el error indica la operación", `{"a":1}`, `[{"op":"replace","path":"/a","value":2},{"op":"remove","path":"/b"}]`, "operación 1 (remove /b)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if err == nil {
+				t.Fatalf("resultado = %s, se esperaba el error %q", got, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, se esperaba %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Un test fallido se puede distinguir con errors.Is, y si una operación falla
+// el documento original no cambia
+func TestApplyJSONPatchAtomic(t *testing.T) {
+	doc := []byte(`{"title":"Ficciones","tags":["cuentos"]}`)
+	original := string(doc)
+
+	_, err := ApplyJSONPatch(doc, []byte(`[
+		{"op":"add","path":"/tags/-","value":"borges"},
+		{"op":"replace","path":"/title","value":"El Aleph"},
+		{"op":"test","path":"/title","value":"Ficciones"}
+	]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("err = %v, se esperaba ErrTestFailed", err)
+	}
+	if string(doc) != original {
+		t.Errorf("el documento cambió: %s", doc)
+	}
+}
@@ -0,0 +1,83 @@
+// Package patch aplica parches a documentos JSON para las modificaciones
+// parciales (PATCH) de la API, en los dos formatos estándar:
+//
+//   - JSON Merge Patch (RFC 7396): un objeto con los campos a cambiar; null
+//     borra el campo. {"title": "Ficciones", "year": null}
+//   - JSON Patch (RFC 6902): una lista de operaciones sobre rutas JSON
+//     Pointer. [{"op": "replace", "path": "/title", "value": "Ficciones"}]
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Tipos de contenido de cada formato
+const (
+	MergePatch = "application/merge-patch+json"
+	JSONPatch  = "application/json-patch+json"
+)
+
+// Apply aplica a doc el parche en el formato indicado por mediaType
+// (MergePatch o JSONPatch) y devuelve el documento resultante
+func Apply(mediaType string, doc, patch []byte) ([]byte, error) {
+	switch mediaType {
+	case MergePatch:
+		return Merge(doc, patch)
+	case JSONPatch:
+		return ApplyJSONPatch(doc, patch)
+	default:
+		return nil, fmt.Errorf("formato de parche no soportado: %s", mediaType)
+	}
+}
+
+// Merge aplica un JSON Merge Patch (RFC 7396)
+func Merge(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, errors.New("el parche no es JSON válido")
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+// mergeValue es el algoritmo de la sección 2 del RFC: los objetos se
+// combinan campo a campo y cualquier otro valor reemplaza al anterior
+func mergeValue(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergeValue(t[k], v)
+	}
+	return t
+}
+
+// decode lee un único valor JSON conservando los números tal como vienen
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("hay contenido después del valor JSON")
+	}
+	return v, nil
+}
@@ -0,0 +1,96 @@
+package patch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// equalJSON compara dos documentos JSON por su valor, sin importar el orden
+// de los campos ni los espacios
+func equalJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	g, err := decode(got)
+	if err != nil {
+		t.Fatalf("el resultado no es JSON válido: %s", got)
+	}
+	w, err := decode([]byte(want))
+	if err != nil {
+		t.Fatalf("el valor esperado no es JSON válido: %s", want)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("resultado = %s, se esperaba %s", got, want)
+	}
+}
+
+// Ejemplos del apéndice A del RFC 7396
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		// Los números se conservan tal como vienen
+		{`{"price":10.50}`, `{"year":1949}`, `{"price":10.50,"year":1949}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.doc+" "+tt.patch, func(t *testing.T) {
+			got, err := Merge([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			equalJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, wantErr string
+	}{
+		{"parche inválido", `{"a":1}`, `{"a":`, "el parche no es JSON válido"},
+		{"contenido de más", `{"a":1}`, `{"a":2} {"b":3}`, "el parche no es JSON válido"},
+		{"documento inválido", `{"a"`, `{"a":2}`, "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Merge([]byte(tt.doc), []byte(tt.patch))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, se esperaba %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	doc := []byte(`{"title":"Ficciones","year":1944}`)
+
+	got, err := Apply(MergePatch, doc, []byte(`{"year":null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalJSON(t, got, `{"title":"Ficciones"}`)
+
+	got, err = Apply(JSONPatch, doc, []byte(`[{"op":"replace","path":"/year","value":1945}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalJSON(t, got, `{"title":"Ficciones","year":1945}`)
+
+	if _, err := Apply("application/json", doc, []byte(`{}`)); err == nil || !strings.Contains(err.Error(), "formato de parche no soportado") {
+		t.Fatalf("err = %v, se esperaba formato no soportado", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Package query implementa un lenguaje de búsqueda avanzada de libros.
+//
+// Ejemplo: author:"Borges" title:aleph -tag:ensayo year:1940..1960
+//
+// Los términos separados por espacios se combinan con AND, la palabra OR
+// combina alternativas, "-" niega un término y los paréntesis agrupan.
+// Un término sin campo busca en el título y en el autor.
+package query
+
+import "fmt"
+
+// Campos soportados por el lenguaje
+const (
+	FieldAny    = ""
+	FieldTitle  = "title"
+	FieldAuthor = "author"
+	FieldTag    = "tag"
+	FieldYear   = "year"
+	FieldID     = "id"
+)
+
+// numericFields son los campos que aceptan números y rangos
+var numericFields = map[string]bool{FieldYear: true, FieldID: true}
+
+// textFields son los campos que aceptan texto
+var textFields = map[string]bool{FieldAny: true, FieldTitle: true, FieldAuthor: true, FieldTag: true}
+
+// Expr es un nodo del árbol de sintaxis
+type Expr interface {
+	exprNode()
+}
+
+// And se cumple cuando se cumplen todos sus hijos
+type And struct {
+	Items []Expr
+}
+
+// Or se cumple cuando se cumple alguno de sus hijos
+type Or struct {
+	Items []Expr
+}
+
+// Not niega la expresión que contiene
+type Not struct {
+	X Expr
+}
+
+// Term compara un campo con un valor textual o numérico exacto
+type Term struct {
+	Field string
+	Value string
+	Pos   int
+}
+
+// Range compara un campo numérico con un intervalo cerrado.
+// Un extremo nil significa que el intervalo está abierto por ese lado.
+type Range struct {
+	Field    string
+	From, To *int
+	Pos      int
+}
+
+func (*And) exprNode()   {}
+func (*Or) exprNode()    {}
+func (*Not) exprNode()   {}
+func (*Term) exprNode()  {}
+func (*Range) exprNode() {}
+
+// Error describe un problema de sintaxis señalando el token que lo provocó
+type Error struct {
+	Pos   int    `json:"position"`
+	Token string `json:"token"`
+	Msg   string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("posición %d: %s", e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("posición %d (%q): %s", e.Pos, e.Token, e.Msg)
+}
@@ -0,0 +1,144 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokNeg
+	tokAtom
+)
+
+// token es una unidad léxica. Para los átomos Field contiene el nombre
+// del campo (vacío si no hay) y Value el valor ya sin comillas.
+type token struct {
+	kind   tokenKind
+	field  string
+	value  string
+	quoted bool
+	pos    int
+	text   string
+}
+
+// lex convierte la consulta en una lista de tokens
+func lex(input string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			toks = append(toks, token{kind: tokLParen, pos: i, text: "("})
+			i += size
+		case r == ')':
+			toks = append(toks, token{kind: tokRParen, pos: i, text: ")"})
+			i += size
+		case r == '-' && startsAtom(input[i+size:]):
+			toks = append(toks, token{kind: tokNeg, pos: i, text: "-"})
+			i += size
+		case r == '-':
+			return nil, &Error{Pos: i, Token: "-", Msg: "la negación debe ir pegada a un término"}
+		default:
+			tok, next, err := lexAtom(input, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, tok)
+			i = next
+		}
+	}
+	toks = append(toks, token{kind: tokEOF, pos: len(input)})
+	return toks, nil
+}
+
+// startsAtom indica si el resto de la entrada empieza con un término
+func startsAtom(rest string) bool {
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest != "" && !unicode.IsSpace(r) && r != ')' && r != '-'
+}
+
+// lexAtom lee un término de la forma campo:valor, "frase" o palabra
+func lexAtom(input string, start int) (token, int, error) {
+	tok := token{kind: tokAtom, pos: start}
+	i := start
+
+	if input[i] != '"' {
+		word := readWord(input, i, true)
+		i += len(word)
+		if i < len(input) && input[i] == ':' {
+			if word == "" {
+				return tok, 0, &Error{Pos: start, Token: ":", Msg: "falta el nombre del campo"}
+			}
+			tok.field = strings.ToLower(word)
+			i++
+		} else {
+			tok.value = word
+			tok.text = input[start:i]
+			return tok, i, nil
+		}
+	}
+
+	if i < len(input) && input[i] == '"' {
+		value, next, err := readQuoted(input, i)
+		if err != nil {
+			return tok, 0, err
+		}
+		tok.value = value
+		tok.quoted = true
+		i = next
+	} else {
+		value := readWord(input, i, false)
+		i += len(value)
+		tok.value = value
+	}
+
+	tok.text = input[start:i]
+	if tok.value == "" {
+		return tok, 0, &Error{Pos: start, Token: tok.text, Msg: "falta el valor del campo"}
+	}
+	return tok, i, nil
+}
+
+// readWord lee caracteres hasta un espacio, un paréntesis o una comilla.
+// Si stopAtColon es true también se detiene en ':' para separar el campo.
+func readWord(input string, start int, stopAtColon bool) string {
+	i := start
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' || (stopAtColon && r == ':') {
+			break
+		}
+		i += size
+	}
+	return input[start:i]
+}
+
+// readQuoted lee una frase entre comillas dobles; \" escapa una comilla
+func readQuoted(input string, start int) (string, int, error) {
+	var b strings.Builder
+	i := start + 1
+	for i < len(input) {
+		switch input[i] {
+		case '\\':
+			if i+1 < len(input) {
+				b.WriteByte(input[i+1])
+				i += 2
+				continue
+			}
+		case '"':
+			return b.String(), i + 1, nil
+		}
+		b.WriteByte(input[i])
+		i++
+	}
+	return "", 0, &Error{Pos: start, Token: input[start:], Msg: "faltan las comillas de cierre"}
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"practica-go/internal/model"
+	"strconv"
+	"strings"
+)
+
+// Match evalúa la expresión contra un libro en memoria.
+// Sirve para los almacenamientos que no usan SQL y replica la semántica
+// de ToSQL: texto sin distinguir mayúsculas (solo las ASCII, como LIKE en
+// SQLite), etiquetas por igualdad y un año vacío como 0.
+func Match(e Expr, b *model.Book) bool {
+	switch n := e.(type) {
+	case *And:
+		for _, item := range n.Items {
+			if !Match(item, b) {
+				return false
+			}
+		}
+		return true
+	case *Or:
+		for _, item := range n.Items {
+			if Match(item, b) {
+				return true
+			}
+		}
+		return false
+	case *Not:
+		return !Match(n.X, b)
+	case *Term:
+		return matchTerm(n, b)
+	case *Range:
+		v := numericValue(n.Field, b)
+		return (n.From == nil || v >= *n.From) && (n.To == nil || v <= *n.To)
+	default:
+		return false
+	}
+}
+
+// Filter devuelve los libros que cumplen la expresión
+func Filter(e Expr, books []*model.Book) []*model.Book {
+	var out []*model.Book
+	for _, b := range books {
+		if Match(e, b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+func matchTerm(t *Term, b *model.Book) bool {
+	switch t.Field {
+	case FieldAny:
+		return containsFold(b.Titulo, t.Value) || containsFold(b.Autor, t.Value)
+	case FieldTitle:
+		return containsFold(b.Titulo, t.Value)
+	case FieldAuthor:
+		return containsFold(b.Autor, t.Value)
+	case FieldTag:
+		for _, tag := range b.Etiquetas {
+			if lowerASCII(tag) == lowerASCII(t.Value) {
+				return true
+			}
+		}
+		return false
+	case FieldYear, FieldID:
+		n, err := strconv.Atoi(t.Value)
+		return err == nil && numericValue(t.Field, b) == n
+	default:
+		return false
+	}
+}
+
+func numericValue(field string, b *model.Book) int {
+	if field == FieldID {
+		return b.ID
+	}
+	return b.Anio
+}
+
+func containsFold(s, sub string) bool {
+	return strings.Contains(lowerASCII(s), lowerASCII(sub))
+}
+
+// lowerASCII pasa a minúsculas solo las letras ASCII: LIKE de SQLite no
+// iguala "Á" con "á", así que Match tampoco
+func lowerASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,133 @@
+package query_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"practica-go/internal/model"
+	"practica-go/internal/query"
+	"practica-go/internal/store"
+	"slices"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// catalog cubre los casos donde Match y ToSQL podrían diferir: mayúsculas,
+// acentos, comodines de LIKE, libros sin año o sin etiquetas y etiquetas que
+// son prefijo de otras
+var catalog = []*model.Book{
+	{Titulo: "Ficciones", Autor: "Jorge Luis Borges", Anio: 1944, Etiquetas: []string{"cuentos", "Fantástico"}},
+	{Titulo: "El Aleph", Autor: "Jorge Luis Borges", Anio: 1949, Etiquetas: []string{"cuentos"}},
+	{Titulo: "Otras inquisiciones", Autor: "Jorge Luis Borges", Anio: 1952, Etiquetas: []string{"ensayo"}},
+	{Titulo: "Rayuela", Autor: "Julio Cortázar", Anio: 1963, Etiquetas: []string{"novela"}},
+	{Titulo: "Bestiario", Autor: "JULIO CORTÁZAR", Anio: 1951, Etiquetas: []string{"cuentos", "cuento"}},
+	{Titulo: "La invención de Morel", Autor: "Adolfo Bioy Casares", Anio: 1940, Etiquetas: []string{"novela", "ciencia ficción"}},
+	{Titulo: "Sin fecha ni etiquetas", Autor: "Anónimo"},
+	{Titulo: "100% Borges", Autor: "Varios_autores", Anio: 2000},
+	{Titulo: "ÁLGEBRA", Autor: "Aurelio Baldor", Anio: 1941, Etiquetas: []string{"Matemática"}},
+}
+
+var queries = []string{
+	"borges",
+	"BORGES",
+	"author:borges",
+	"title:aleph",
+	"title:ALEPH",
+	`"el aleph"`,
+	`author:"jorge luis"`,
+	"cortázar",
+	"CORTÁZAR",
+	"title:álgebra",
+	"title:ÁLGEBRA",
+	"tag:cuentos",
+	"tag:CUENTOS",
+	"tag:cuento",
+	"tag:fantástico",
+	"tag:Fantástico",
+	`tag:"ciencia ficción"`,
+	"tag:matemática",
+	"-tag:cuentos",
+	"year:1949",
+	"year:1940..1950",
+	"year:1950..",
+	"year:..1944",
+	"-year:1949",
+	"-year:1940..1950",
+	"year:0",
+	"id:3",
+	"id:2..4",
+	"100%",
+	"title:%",
+	"_",
+	"varios_autores",
+	"borges -tag:ensayo",
+	"borges OR cortázar",
+	"author:borges year:1940..1950 OR tag:novela",
+	"-(borges OR tag:novela)",
+	"(tag:cuentos OR tag:ensayo) -year:..1945",
+	`author:"Borges" title:aleph -tag:ensayo year:1940..1960`,
+	"inexistente",
+}
+
+// Match (en memoria) y ToSQL (en la base) tienen que devolver los mismos
+// libros para cada consulta
+func TestMatchAgreesWithSQL(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	st := store.New(db)
+	if _, err := st.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range catalog {
+		if _, err := st.BookStorage.Create(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Match se evalúa sobre los libros tal como vuelven de la base
+	all, err := st.BookStorage.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matchedSome := 0
+	for _, q := range queries {
+		t.Run(q, func(t *testing.T) {
+			expr, err := query.Parse(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromSQL, err := st.BookStorage.Query(ctx, expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, got := ids(fromSQL), ids(query.Filter(expr, all))
+			if !slices.Equal(got, want) {
+				t.Errorf("Match = %v, SQL = %v", got, want)
+			}
+			if len(want) > 0 && len(want) < len(all) {
+				matchedSome++
+			}
+		})
+	}
+	// Si casi todas las consultas dieran todo o nada, el test no probaría mucho
+	if matchedSome < len(queries)*2/3 {
+		t.Errorf("solo %d de %d consultas filtran algo", matchedSome, len(queries))
+	}
+}
+
+func ids(books []*model.Book) []int {
+	out := make([]int, 0, len(books))
+	for _, b := range books {
+		out = append(out, b.ID)
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,179 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Parse analiza una consulta y devuelve su árbol de sintaxis.
+// Los errores devueltos son siempre de tipo *Error.
+func Parse(input string) (Expr, error) {
+	toks, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	if p.peek().kind == tokEOF {
+		return nil, &Error{Pos: 0, Msg: "la consulta está vacía"}
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &Error{Pos: t.pos, Token: t.text, Msg: "token inesperado"}
+	}
+	return expr, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword indica si el token es la palabra clave indicada (sin campo ni comillas)
+func isKeyword(t token, kw string) bool {
+	return t.kind == tokAtom && t.field == "" && !t.quoted && t.value == kw
+}
+
+// parseOr: and ("OR" and)*
+func (p *parser) parseOr() (Expr, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	items := []Expr{first}
+	for isKeyword(p.peek(), "OR") {
+		p.next()
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, next)
+	}
+	if len(items) == 1 {
+		return first, nil
+	}
+	return &Or{Items: items}, nil
+}
+
+// parseAnd: unary+ ; "AND" explícito es opcional
+func (p *parser) parseAnd() (Expr, error) {
+	var items []Expr
+	for {
+		t := p.peek()
+		if t.kind == tokEOF || t.kind == tokRParen || isKeyword(t, "OR") {
+			break
+		}
+		if isKeyword(t, "AND") {
+			if len(items) == 0 {
+				return nil, &Error{Pos: t.pos, Token: t.text, Msg: "AND necesita un término a su izquierda"}
+			}
+			p.next()
+			continue
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, expr)
+	}
+
+	if len(items) == 0 {
+		t := p.peek()
+		return nil, &Error{Pos: t.pos, Token: t.text, Msg: "se esperaba un término"}
+	}
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	return &And{Items: items}, nil
+}
+
+// parseUnary: "-" unary | "(" or ")" | átomo
+func (p *parser) parseUnary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNeg:
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, &Error{Pos: t.pos, Token: t.text, Msg: "falta el paréntesis de cierre"}
+		}
+		return expr, nil
+	case tokAtom:
+		return atomExpr(t)
+	default:
+		return nil, &Error{Pos: t.pos, Token: t.text, Msg: "se esperaba un término"}
+	}
+}
+
+// atomExpr valida un término y lo convierte en Term o Range
+func atomExpr(t token) (Expr, error) {
+	switch {
+	case textFields[t.field]:
+		return &Term{Field: t.field, Value: t.value, Pos: t.pos}, nil
+	case numericFields[t.field]:
+		return numericExpr(t)
+	default:
+		return nil, &Error{Pos: t.pos, Token: t.text, Msg: "campo desconocido: " + t.field}
+	}
+}
+
+// numericExpr interpreta N, N..M, N.. y ..M
+func numericExpr(t token) (Expr, error) {
+	from, to, isRange := strings.Cut(t.value, "..")
+	if !isRange {
+		if _, err := strconv.Atoi(t.value); err != nil {
+			return nil, &Error{Pos: t.pos, Token: t.text, Msg: "se esperaba un número"}
+		}
+		return &Term{Field: t.field, Value: t.value, Pos: t.pos}, nil
+	}
+
+	r := &Range{Field: t.field, Pos: t.pos}
+	bound := func(s string) (*int, error) {
+		if s == "" {
+			return nil, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, &Error{Pos: t.pos, Token: t.text, Msg: "el rango debe tener la forma N..M"}
+		}
+		return &n, nil
+	}
+
+	var err error
+	if r.From, err = bound(from); err != nil {
+		return nil, err
+	}
+	if r.To, err = bound(to); err != nil {
+		return nil, err
+	}
+	if r.From == nil && r.To == nil {
+		return nil, &Error{Pos: t.pos, Token: t.text, Msg: "el rango necesita al menos un extremo"}
+	}
+	if r.From != nil && r.To != nil && *r.From > *r.To {
+		return nil, &Error{Pos: t.pos, Token: t.text, Msg: "el inicio del rango es mayor que el final"}
+	}
+	return r, nil
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// show escribe el árbol en forma prefija para comparar la estructura:
+// (AND ...), (OR ...), -x, campo:"valor" y campo:desde..hasta
+func show(e Expr) string {
+	switch n := e.(type) {
+	case *And:
+		return "(AND " + showAll(n.Items) + ")"
+	case *Or:
+		return "(OR " + showAll(n.Items) + ")"
+	case *Not:
+		return "-" + show(n.X)
+	case *Term:
+		if n.Field == FieldAny {
+			return fmt.Sprintf("%q", n.Value)
+		}
+		return fmt.Sprintf("%s:%q", n.Field, n.Value)
+	case *Range:
+		bound := func(p *int) string {
+			if p == nil {
+				return ""
+			}
+			return fmt.Sprint(*p)
+		}
+		return n.Field + ":" + bound(n.From) + ".." + bound(n.To)
+	default:
+		return fmt.Sprintf("%T", e)
+	}
+}
+
+func showAll(items []Expr) string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = show(item)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"término suelto", "borges", `"borges"`},
+		{"AND implícito", "borges aleph", `(AND "borges" "aleph")`},
+		{"AND explícito", "borges AND aleph", `(AND "borges" "aleph")`},
+		{"AND antes que OR", "a b OR c", `(OR (AND "a" "b") "c")`},
+		{"AND antes que OR a la derecha", "a OR b c", `(OR "a" (AND "b" "c"))`},
+		{"OR de varios AND", "a AND b OR c AND d", `(OR (AND "a" "b") (AND "c" "d"))`},
+		{"OR encadenado", "a OR b OR c", `(OR "a" "b" "c")`},
+		{"paréntesis", "a (b OR c)", `(AND "a" (OR "b" "c"))`},
+		{"paréntesis anidados", "((a))", `"a"`},
+		{"negación de término", "-tag:ensayo borges", `(AND -tag:"ensayo" "borges")`},
+		{"negación de grupo", "-(a OR b) c", `(AND -(OR "a" "b") "c")`},
+		{"negación antes que AND", "-a b", `(AND -"a" "b")`},
+		{"campo en mayúsculas", "Author:borges", `author:"borges"`},
+		{"frase", `"el aleph"`, `"el aleph"`},
+		{"frase con campo", `author:"Jorge Luis Borges" title:aleph`, `(AND author:"Jorge Luis Borges" title:"aleph")`},
+		{"comillas escapadas", `title:"dijo \"hola\""`, `title:"dijo \"hola\""`},
+		{"palabra clave entre comillas", `a "OR" b`, `(AND "a" "OR" "b")`},
+		{"palabra clave con campo", "title:AND", `title:"AND"`},
+		{"or en minúsculas es un término", "a or b", `(AND "a" "or" "b")`},
+		{"guion dentro de la palabra", "bioy-casares", `"bioy-casares"`},
+		{"número", "year:1949", `year:"1949"`},
+		{"id", "id:7", `id:"7"`},
+		{"rango", "year:1940..1960", "year:1940..1960"},
+		{"rango abierto al final", "year:1940..", "year:1940.."},
+		{"rango abierto al inicio", "year:..1960", "year:..1960"},
+		{"rango de un año", "year:1949..1949", "year:1949..1949"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			if got := show(expr); got != tt.want {
+				t.Errorf("Parse(%q) = %s, se esperaba %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		pos   int
+		token string
+		msg   string
+	}{
+		{"vacía", "", 0, "", "la consulta está vacía"},
+		{"solo espacios", "   ", 0, "", "la consulta está vacía"},
+		{"negación suelta", "a - b", 2, "-", "la negación debe ir pegada a un término"},
+		{"doble negación", "--a", 0, "-", "la negación debe ir pegada a un término"},
+		{"sin nombre de campo", "a :x", 2, ":", "falta el nombre del campo"},
+		{"sin valor", "author:", 0, "author:", "falta el valor del campo"},
+		{"comillas sin cerrar", `a title:"sin cierre`, 8, `"sin cierre`, "faltan las comillas de cierre"},
+		{"AND al inicio", "AND a", 0, "AND", "AND necesita un término a su izquierda"},
+		{"OR al final", "a OR", 4, "", "se esperaba un término"},
+		{"OR al inicio", "OR a", 0, "OR", "se esperaba un término"},
+		{"paréntesis sin cerrar", "a (b c", 2, "(", "falta el paréntesis de cierre"},
+		{"paréntesis vacíos", "a ()", 3, ")", "se esperaba un término"},
+		{"paréntesis de más", "a b)", 3, ")", "token inesperado"},
+		{"campo desconocido", "a color:rojo", 2, "color:rojo", "campo desconocido: color"},
+		{"año no numérico", "year:abc", 0, "year:abc", "se esperaba un número"},
+		{"comparación no soportada", "author:borges AND year:>", 18, "year:>", "se esperaba un número"},
+		{"rango mal formado", "year:a..b", 0, "year:a..b", "el rango debe tener la forma N..M"},
+		{"rango sin extremos", "year:..", 0, "year:..", "el rango necesita al menos un extremo"},
+		{"rango invertido", "year:1960..1940", 0, "year:1960..1940", "el inicio del rango es mayor que el final"},
+		// Las posiciones son en bytes, no en caracteres
+		{"posición después de multibyte", "ñandú OR", 10, "", "se esperaba un término"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("Parse(%q): err = %v, se esperaba *Error", tt.input, err)
+			}
+			if qerr.Pos != tt.pos || qerr.Token != tt.token || qerr.Msg != tt.msg {
+				t.Errorf("Parse(%q) = {%d %q %q}, se esperaba {%d %q %q}",
+					tt.input, qerr.Pos, qerr.Token, qerr.Msg, tt.pos, tt.token, tt.msg)
+			}
+		})
+	}
+}
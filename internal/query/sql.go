@@ -0,0 +1,110 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ToSQL traduce la expresión a una condición WHERE parametrizada para la
+// tabla books. Los valores del usuario nunca se concatenan en el SQL: solo
+// viajan en args.
+func ToSQL(e Expr) (string, []any, error) {
+	var args []any
+	where, err := toSQL(e, &args)
+	if err != nil {
+		return "", nil, err
+	}
+	return where, args, nil
+}
+
+func toSQL(e Expr, args *[]any) (string, error) {
+	switch n := e.(type) {
+	case *And:
+		return joinSQL(n.Items, " AND ", args)
+	case *Or:
+		return joinSQL(n.Items, " OR ", args)
+	case *Not:
+		inner, err := toSQL(n.X, args)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case *Term:
+		return termSQL(n, args)
+	case *Range:
+		return rangeSQL(n, args)
+	default:
+		return "", fmt.Errorf("nodo de consulta no soportado: %T", e)
+	}
+}
+
+func joinSQL(items []Expr, sep string, args *[]any) (string, error) {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		s, err := toSQL(item, args)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "("+s+")")
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func termSQL(t *Term, args *[]any) (string, error) {
+	switch t.Field {
+	case FieldAny:
+		like := "%" + escapeLike(t.Value) + "%"
+		*args = append(*args, like, like)
+		return `title LIKE ? ESCAPE '\' OR author LIKE ? ESCAPE '\'`, nil
+	case FieldTitle, FieldAuthor:
+		*args = append(*args, "%"+escapeLike(t.Value)+"%")
+		return t.Field + ` LIKE ? ESCAPE '\'`, nil
+	case FieldTag:
+		// Las etiquetas se guardan separadas por comas: se busca ",tag,"
+		*args = append(*args, "%,"+escapeLike(t.Value)+",%")
+		return `(',' || COALESCE(tags, '') || ',') LIKE ? ESCAPE '\'`, nil
+	case FieldYear, FieldID:
+		n, err := strconv.Atoi(t.Value)
+		if err != nil {
+			return "", &Error{Pos: t.Pos, Token: t.Value, Msg: "se esperaba un número"}
+		}
+		*args = append(*args, n)
+		return column(t.Field) + " = ?", nil
+	default:
+		return "", &Error{Pos: t.Pos, Token: t.Field, Msg: "campo desconocido: " + t.Field}
+	}
+}
+
+func rangeSQL(r *Range, args *[]any) (string, error) {
+	if !numericFields[r.Field] {
+		return "", &Error{Pos: r.Pos, Token: r.Field, Msg: "el campo no admite rangos"}
+	}
+	switch {
+	case r.From != nil && r.To != nil:
+		*args = append(*args, *r.From, *r.To)
+		return column(r.Field) + " BETWEEN ? AND ?", nil
+	case r.From != nil:
+		*args = append(*args, *r.From)
+		return column(r.Field) + " >= ?", nil
+	default:
+		*args = append(*args, *r.To)
+		return column(r.Field) + " <= ?", nil
+	}
+}
+
+// column devuelve la expresión SQL de un campo numérico.
+// Un año NULL se trata como 0, que es como lo ve Match (Anio vacío): así
+// -year:1949 incluye a los libros sin año en las dos implementaciones.
+func column(field string) string {
+	if field == FieldYear {
+		return "COALESCE(year, 0)"
+	}
+	return field
+}
+
+// escapeLike escapa los comodines de LIKE para que se busquen literalmente
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
@@ -0,0 +1,53 @@
+// Package reqctx guarda en el context los datos propios de cada petición
+// (ID de la petición, usuario autenticado) para que cualquier capa pueda
+// leerlos sin depender de net/http.
+package reqctx
+
+import "context"
+
+// Info son los datos de una petición. Se guarda como puntero para que los
+// middlewares internos (ej. autenticación) puedan completarlo y los externos
+// (ej. access log) vean el resultado.
+type Info struct {
+	RequestID string
+	UserID    int
+	Role      string
+}
+
+type ctxKey struct{}
+
+// New crea un Info para la petición y lo agrega al context
+func New(ctx context.Context, requestID string) (context.Context, *Info) {
+	info := &Info{RequestID: requestID}
+	return context.WithValue(ctx, ctxKey{}, info), info
+}
+
+// From devuelve el Info de la petición o nil si no hay
+func From(ctx context.Context) *Info {
+	info, _ := ctx.Value(ctxKey{}).(*Info)
+	return info
+}
+
+// RequestID devuelve el ID de la petición o "" si no hay
+func RequestID(ctx context.Context) string {
+	if info := From(ctx); info != nil {
+		return info.RequestID
+	}
+	return ""
+}
+
+// Role devuelve el rol del usuario autenticado o "" si no hay
+func Role(ctx context.Context) string {
+	if info := From(ctx); info != nil {
+		return info.Role
+	}
+	return ""
+}
+
+// UserID devuelve el ID del usuario autenticado o 0 si no hay
+func UserID(ctx context.Context) int {
+	if info := From(ctx); info != nil {
+		return info.UserID
+	}
+	return 0
+}
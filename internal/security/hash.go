@@ -1,12 +1,31 @@
 package security
 
 import (
+	"fmt"
+	"sync/atomic"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
+// bcryptCost es el costo usado al hashear; se configura al iniciar
+var bcryptCost atomic.Int32
+
+func init() {
+	bcryptCost.Store(int32(bcrypt.DefaultCost))
+}
+
+// SetBcryptCost cambia el costo de bcrypt para los hashes nuevos
+func SetBcryptCost(cost int) error {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("el costo de bcrypt debe estar entre %d y %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	bcryptCost.Store(int32(cost))
+	return nil
+}
+
 // HashPassword genera un hash de la contraseña
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), int(bcryptCost.Load()))
 	return string(bytes), err
 }
 
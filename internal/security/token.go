@@ -0,0 +1,111 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Tipos de token: el de acceso autoriza peticiones y el de refresco solo
+// sirve para obtener un nuevo par de tokens
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+var (
+	ErrInvalidToken = errors.New("token inválido")
+	ErrExpiredToken = errors.New("token expirado")
+)
+
+// Claims son los datos firmados dentro de un JWT
+type Claims struct {
+	Subject   int    `json:"sub"`
+	Role      string `json:"role"`
+	Type      string `json:"typ"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenPair es lo que recibe el cliente al hacer login o refrescar
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// TokenIssuer firma y verifica JWT con HMAC-SHA256
+type TokenIssuer struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewTokenIssuer crea un emisor de tokens con la clave y duraciones indicadas
+func NewTokenIssuer(secret string, accessTTL, refreshTTL time.Duration) *TokenIssuer {
+	return &TokenIssuer{secret: []byte(secret), accessTTL: accessTTL, refreshTTL: refreshTTL}
+}
+
+// jwtHeader es fijo: solo se acepta HS256
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Issue genera un par de tokens para el usuario
+func (t *TokenIssuer) Issue(userID int, role string) (*TokenPair, error) {
+	now := time.Now()
+	access, err := t.sign(Claims{Subject: userID, Role: role, Type: TokenAccess, IssuedAt: now.Unix(), ExpiresAt: now.Add(t.accessTTL).Unix()})
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := t.sign(Claims{Subject: userID, Role: role, Type: TokenRefresh, IssuedAt: now.Unix(), ExpiresAt: now.Add(t.refreshTTL).Unix()})
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{AccessToken: access, RefreshToken: refresh, ExpiresIn: int(t.accessTTL.Seconds())}, nil
+}
+
+// Verify valida la firma, el tipo y la expiración del token
+func (t *TokenIssuer) Verify(token, typ string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, t.mac(parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var c Claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if c.Type != typ || c.Subject <= 0 {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= c.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return &c, nil
+}
+
+func (t *TokenIssuer) sign(c Claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(t.mac(unsigned)), nil
+}
+
+func (t *TokenIssuer) mac(s string) []byte {
+	h := hmac.New(sha256.New, t.secret)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
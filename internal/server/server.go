@@ -0,0 +1,123 @@
+// Package server arma el router HTTP: registra los handlers de cada dominio
+// y los envuelve con los middlewares comunes.
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"practica-go/internal/events"
+	"practica-go/internal/health"
+	"practica-go/internal/metrics"
+	"practica-go/internal/middleware"
+	"practica-go/internal/openapi"
+	"practica-go/internal/security"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"practica-go/internal/transport/audit"
+	"practica-go/internal/transport/books"
+	eventshttp "practica-go/internal/transport/events"
+	graphqlhttp "practica-go/internal/transport/graphql"
+	healthhttp "practica-go/internal/transport/health"
+	opdshttp "practica-go/internal/transport/opds"
+	"practica-go/internal/transport/users"
+	"practica-go/internal/transport/webhooks"
+)
+
+// Deps son las dependencias que necesita el servidor HTTP
+type Deps struct {
+	Store  *store.Store
+	Health *health.Checker
+	Tokens *security.TokenIssuer
+	Logger *slog.Logger
+	// MaxBodyBytes es el tamaño máximo de los cuerpos JSON
+	MaxBodyBytes int64
+	// AllowPrivateWebhooks acepta webhooks que apuntan a direcciones
+	// privadas o de loopback (desarrollo local)
+	AllowPrivateWebhooks bool
+}
+
+// routes es un ServeMux que recuerda los patrones registrados
+type routes struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (r *routes) Handle(pattern string, h http.Handler) {
+	r.patterns = append(r.patterns, pattern)
+	r.ServeMux.Handle(pattern, h)
+}
+
+func (r *routes) HandleFunc(pattern string, h func(http.ResponseWriter, *http.Request)) {
+	r.Handle(pattern, http.HandlerFunc(h))
+}
+
+// New crea el handler principal de la aplicación
+func New(d Deps) http.Handler {
+	mux := newRoutes(d)
+
+	// El orden importa: RequestID y Tracing primero para que el access log
+	// y las capas internas tengan el ID de petición y de traza en el context.
+	// Metrics va por fuera de Auth para contar también los 401 por token
+	// inválido, que no llegan al router (quedan con ruta "unmatched").
+	var h http.Handler = mux.ServeMux
+	h = middleware.Validate(d.MaxBodyBytes)(h)
+	h = middleware.Auth(d.Tokens)(h)
+	h = middleware.Metrics(h)
+	h = middleware.Logging(d.Logger)(h)
+	h = middleware.Tracing(h)
+	h = middleware.RequestID(h)
+	return h
+}
+
+// newRoutes registra los handlers de cada dominio. Que todas las rutas estén
+// en openapi.yaml lo verifica TestRoutesDocumented.
+func newRoutes(d Deps) *routes {
+	bookService := service.NewBook(*d.Store)
+	bookHandler := books.New(bookService)
+	opdsHandler := opdshttp.New(bookService)
+	userService := service.NewUser(*d.Store, d.Tokens)
+	userHandler := users.NewHandlerUser(userService)
+	graphqlHandler := graphqlhttp.New(bookService, userService)
+	webhookHandler := webhooks.New(service.NewWebhook(*d.Store, d.AllowPrivateWebhooks))
+	eventsHandler := eventshttp.New(events.Default)
+	auditHandler := audit.New(service.NewAudit(*d.Store))
+	healthHandler := healthhttp.New(d.Health)
+
+	mux := &routes{ServeMux: http.NewServeMux()}
+
+	mux.HandleFunc("/books", bookHandler.HandleBooks)
+	mux.HandleFunc("/books/", bookHandler.HandleBookByID)
+	mux.HandleFunc("/books/search", bookHandler.HandleSearchBooks)
+	mux.HandleFunc("/books/query", bookHandler.HandleQueryBooks)
+	mux.HandleFunc("/books/import", bookHandler.HandleImportBooks)
+	mux.HandleFunc("/books/export", bookHandler.HandleExportBooks)
+	mux.HandleFunc("/books/onix", bookHandler.HandleImportONIX)
+	mux.HandleFunc("/books/marc", bookHandler.HandleImportMARC)
+	mux.HandleFunc("/books/exists/", bookHandler.HandleBookExists)
+
+	mux.HandleFunc("/opds", opdsHandler.HandleOPDS)
+	mux.HandleFunc("/opds/", opdsHandler.HandleOPDS)
+
+	mux.HandleFunc("/users", userHandler.HandleUsers)
+	mux.HandleFunc("/users/", userHandler.HandleUserByUserOrEmail)
+	mux.HandleFunc("/users/search", userHandler.HandleSearchUsersOrEmail)
+	mux.HandleFunc("/users/exists/", userHandler.HandleUserExists)
+	mux.HandleFunc("/users/login", userHandler.HandleLogin)
+	mux.HandleFunc("/users/refresh", userHandler.HandleRefresh)
+
+	mux.HandleFunc("/graphql", graphqlHandler.HandleGraphQL)
+
+	mux.HandleFunc("/events", middleware.RequireRole("admin", eventsHandler.HandleEvents))
+
+	mux.HandleFunc("/webhooks", middleware.RequireRole("admin", webhookHandler.HandleWebhooks))
+	mux.HandleFunc("/webhooks/", middleware.RequireRole("admin", webhookHandler.HandleWebhookByID))
+
+	mux.HandleFunc("/audit", middleware.RequireRole("admin", auditHandler.HandleAudit))
+
+	mux.HandleFunc("/healthz", healthHandler.HandleLive)
+	mux.HandleFunc("/readyz", healthHandler.HandleReady)
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/openapi.json", openapi.Handler())
+	mux.Handle("/docs", openapi.DocsHandler())
+	return mux
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"practica-go/internal/health"
+	"practica-go/internal/metrics"
+	"practica-go/internal/openapi"
+	"practica-go/internal/security"
+	"practica-go/internal/store"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Cada ruta registrada en el router tiene que estar documentada en openapi.yaml
+func TestRoutesDocumented(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mux := newRoutes(Deps{Store: store.New(db), Health: health.New(time.Second)})
+	if !slices.Contains(mux.patterns, "/books") {
+		t.Fatalf("el router no registró /books: %v", mux.patterns)
+	}
+	if err := openapi.CheckRoutes(mux.patterns); err != nil {
+		t.Error(err)
+	}
+}
+
+// CheckRoutes tiene que detectar una ruta que no está en el documento
+func TestCheckRoutesMissing(t *testing.T) {
+	err := openapi.CheckRoutes([]string{"/books", "/no-documentada"})
+	if err == nil {
+		t.Fatal("se esperaba un error por la ruta sin documentar")
+	}
+	if want := "rutas sin documentar en openapi.yaml: /no-documentada"; err.Error() != want {
+		t.Errorf("error = %q, se esperaba %q", err, want)
+	}
+}
+
+// Un token inválido se rechaza en Auth, antes del router: igual tiene que
+// quedar contado en las métricas HTTP
+func TestMetricsCountUnauthorized(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	h := New(Deps{
+		Store:        store.New(db),
+		Health:       health.New(time.Second),
+		Tokens:       security.NewTokenIssuer("0123456789abcdef0123456789abcdef", time.Minute, time.Hour),
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		MaxBodyBytes: 1 << 20,
+	})
+
+	const sample = `http_requests_total{route="unmatched",method="DELETE",status="401"}`
+	before := counterValue(t, sample)
+	req := httptest.NewRequest(http.MethodDelete, "/books/1", nil)
+	req.Header.Set("Authorization", "Bearer no-es-un-token")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, se esperaba 401", rec.Code)
+	}
+	if after := counterValue(t, sample); after != before+1 {
+		t.Errorf("%s = %v, se esperaba %v", sample, after, before+1)
+	}
+}
+
+// counterValue devuelve el valor de la serie en el registro por defecto (0 si
+// todavía no existe)
+func counterValue(t *testing.T, sample string) float64 {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := metrics.Default.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, sample+" "); ok {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"practica-go/internal/model"
+	"practica-go/internal/reqctx"
+	"practica-go/internal/store"
+	"practica-go/internal/tracing"
+	"reflect"
+	"time"
+)
+
+// Límites de la consulta del registro de auditoría
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// redacted reemplaza en el diff los valores que no se guardan (contraseñas)
+const redacted = "***"
+
+// AuditService consulta el registro de auditoría. Las entradas las escriben
+// los demás services con audit, dentro de la transacción del cambio.
+type AuditService struct {
+	store store.Store
+}
+
+func NewAudit(s store.Store) *AuditService {
+	return &AuditService{store: s}
+}
+
+// QueryAudit busca entradas por entidad, actor y rango de fechas, de la más
+// nueva a la más vieja
+func (s *AuditService) QueryAudit(ctx context.Context, f store.AuditFilter) ([]*model.AuditEntry, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.QueryAudit")
+	defer span.End()
+
+	switch f.EntityType {
+	case "", model.EntityBook, model.EntityUser:
+	default:
+		return nil, errors.New("entity_type debe ser book o user")
+	}
+	if f.EntityID > 0 && f.EntityType == "" {
+		return nil, errors.New("entity_id requiere entity_type")
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return nil, errors.New("from debe ser anterior a to")
+	}
+	if f.Limit <= 0 {
+		f.Limit = defaultAuditLimit
+	}
+	if f.Limit > maxAuditLimit {
+		return nil, errors.New("limit no puede superar 500")
+	}
+	return s.store.AuditStorage.Query(ctx, f)
+}
+
+// audit registra un cambio con el usuario y el ID de petición del context.
+// before y after son la entidad antes y después (nil al crear o borrar); se
+// guardan sin la contraseña. changed agrega al diff campos que no están en
+// las entidades (ej. la contraseña, que se registra como redacted).
+func audit(ctx context.Context, tx *store.Store, action, entityType string, entityID int, before, after any, changed ...string) error {
+	b, bm, err := snapshot(before)
+	if err != nil {
+		return err
+	}
+	a, am, err := snapshot(after)
+	if err != nil {
+		return err
+	}
+
+	e := &model.AuditEntry{
+		ActorID:    reqctx.UserID(ctx),
+		ActorRole:  reqctx.Role(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     b,
+		After:      a,
+		Diff:       diff(bm, am),
+		RequestID:  reqctx.RequestID(ctx),
+		CreatedAt:  time.Now().UTC(),
+	}
+	for _, field := range changed {
+		if e.Diff == nil {
+			e.Diff = make(map[string]model.Change)
+		}
+		e.Diff[field] = model.Change{From: redacted, To: redacted}
+	}
+	return tx.AuditStorage.Record(ctx, e)
+}
+
+// snapshot serializa la entidad para el registro, sin la contraseña
+func snapshot(v any) (json.RawMessage, map[string]any, error) {
+	if rv := reflect.ValueOf(v); !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		return nil, nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, nil, err
+	}
+	if _, ok := m["password"]; ok {
+		delete(m, "password")
+		if raw, err = json.Marshal(m); err != nil {
+			return nil, nil, err
+		}
+	}
+	return raw, m, nil
+}
+
+// diff devuelve los campos que cambiaron entre before y after
+func diff(before, after map[string]any) map[string]model.Change {
+	changes := make(map[string]model.Change)
+	for k, v := range after {
+		if old, ok := before[k]; !ok || !reflect.DeepEqual(old, v) {
+			changes[k] = model.Change{From: before[k], To: v}
+		}
+	}
+	for k, v := range before {
+		if _, ok := after[k]; !ok {
+			changes[k] = model.Change{From: v, To: nil}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"practica-go/internal/model"
+	"practica-go/internal/store"
+	"practica-go/internal/tracing"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// importChunkSize es la cantidad de libros que se insertan por transacción
+const importChunkSize = 100
+
+// Estados posibles de una fila importada
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportSkipped = "skipped"
+	ImportFailed  = "failed"
+	ImportValid   = "valid" // simulación: la fila se crearía
+)
+
+// ImportRow es el resultado de una fila del archivo importado
+type ImportRow struct {
+	Row    int    `json:"row"`
+	Status string `json:"status"`
+	BookID int    `json:"book_id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ImportReport resume una importación fila por fila
+type ImportReport struct {
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Skipped int         `json:"skipped"`
+	Failed  int         `json:"failed"`
+	Valid   int         `json:"valid,omitempty"`
+	DryRun  bool        `json:"dry_run,omitempty"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+func (r *ImportReport) add(row ImportRow) {
+	switch row.Status {
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	case ImportSkipped:
+		r.Skipped++
+	case ImportFailed:
+		r.Failed++
+	case ImportValid:
+		r.Valid++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// CSVMapping indica qué columna del CSV corresponde a cada campo del libro
+// (title, author, year, tags, isbn, publisher). Las columnas se comparan sin distinguir mayúsculas.
+type CSVMapping map[string]string
+
+// csvFields son los campos importables con los nombres de columna que se
+// reconocen automáticamente si no hay un mapeo explícito
+var csvFields = map[string][]string{
+	"title":     {"title", "titulo", "título"},
+	"author":    {"author", "autor"},
+	"year":      {"year", "anio", "año"},
+	"tags":      {"tags", "etiquetas", "categorias", "categorías"},
+	"isbn":      {"isbn"},
+	"publisher": {"publisher", "editorial"},
+}
+
+// ParseCSVMapping interpreta "title:Nombre,author:Escritor" en un CSVMapping
+func ParseCSVMapping(s string) (CSVMapping, error) {
+	m := CSVMapping{}
+	if Trim(s) == "" {
+		return m, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		field, column, ok := strings.Cut(pair, ":")
+		field = strings.ToLower(Trim(field))
+		if !ok || Trim(column) == "" {
+			return nil, fmt.Errorf("mapeo inválido %q: se espera campo:columna", pair)
+		}
+		if _, known := csvFields[field]; !known {
+			return nil, fmt.Errorf("campo desconocido en el mapeo: %s", field)
+		}
+		m[field] = Trim(column)
+	}
+	return m, nil
+}
+
+// resolveColumns devuelve el índice de cada campo en la fila de encabezados
+func resolveColumns(header []string, mapping CSVMapping) (map[string]int, error) {
+	index := make(map[string]int)
+	for i, h := range header {
+		index[strings.ToLower(Trim(h))] = i
+	}
+
+	cols := make(map[string]int)
+	for field, aliases := range csvFields {
+		if column, ok := mapping[field]; ok {
+			i, found := index[strings.ToLower(column)]
+			if !found {
+				return nil, fmt.Errorf("la columna %q del mapeo no está en el encabezado", column)
+			}
+			cols[field] = i
+			continue
+		}
+		for _, alias := range aliases {
+			if i, found := index[alias]; found {
+				cols[field] = i
+				break
+			}
+		}
+	}
+
+	if _, ok := cols["title"]; !ok {
+		return nil, errors.New("el CSV necesita una columna para el título")
+	}
+	if _, ok := cols["author"]; !ok {
+		return nil, errors.New("el CSV necesita una columna para el autor")
+	}
+	return cols, nil
+}
+
+// ImportCSV importa libros desde un CSV cuya primera fila es el encabezado.
+// Cada fila se valida con ValidateBook; los duplicados (en el archivo o en la
+// base, por título y autor) se omiten y las filas con un ISBN que ya está en
+// uso fallan. Los libros válidos se insertan en transacciones de
+// importChunkSize filas y el reporte indica qué pasó con cada fila. Una fila
+// mal formada se reporta y se sigue. Si falla la lectura del archivo (ej. el
+// cuerpo supera el límite) la importación se corta: se guardan las filas
+// leídas hasta ahí y se devuelve el reporte parcial, con el corte como última
+// fila, junto con el error de lectura.
+func (s *BookService) ImportCSV(ctx context.Context, r io.Reader, mapping CSVMapping) (*ImportReport, error) {
+	ctx, span := tracing.Start(ctx, "BookService.ImportCSV")
+	defer span.End()
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("el CSV está vacío")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encabezado inválido: %w", err)
+	}
+	cols, err := resolveColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{}
+	seen := make(map[string]bool)
+	seenISBN := make(map[string]int)
+	var pending []*model.Book
+	var pendingRows []ImportRow
+	var readErr error
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		err := s.store.WithTx(ctx, func(tx *store.Store) error {
+			if err := tx.BookStorage.CreateBatch(ctx, pending); err != nil {
+				return err
+			}
+			for _, book := range pending {
+				if err := audit(ctx, tx, model.AuditCreate, model.EntityBook, book.ID, nil, book); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		for i, row := range pendingRows {
+			if err != nil {
+				row.Status = ImportFailed
+				row.Reason = "no se pudo guardar el lote: " + err.Error()
+			} else {
+				row.Status = ImportCreated
+				row.BookID = pending[i].ID
+			}
+			report.add(row)
+		}
+		pending, pendingRows = nil, nil
+	}
+
+	for rowNum := 2; ; rowNum++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.add(ImportRow{Row: rowNum, Status: ImportFailed, Reason: "CSV inválido: " + err.Error()})
+			continue
+		}
+		if err != nil {
+			readErr = fmt.Errorf("no se pudo leer el CSV: %w", err)
+			report.add(ImportRow{Row: rowNum, Status: ImportFailed, Reason: readErr.Error()})
+			break
+		}
+
+		book, err := bookFromRecord(record, cols)
+		if err == nil {
+			err = ValidateBook(book)
+		}
+		if err != nil {
+			report.add(ImportRow{Row: rowNum, Status: ImportFailed, Title: book.Titulo, Reason: err.Error()})
+			continue
+		}
+
+		key := strings.ToLower(book.Titulo) + "\x00" + strings.ToLower(book.Autor)
+		if seen[key] {
+			report.add(ImportRow{Row: rowNum, Status: ImportSkipped, Title: book.Titulo, Reason: "duplicado dentro del archivo"})
+			continue
+		}
+		exists, err := s.store.BookStorage.ExistsByTitleAndAuthor(ctx, book.Titulo, book.Autor)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			report.add(ImportRow{Row: rowNum, Status: ImportSkipped, Title: book.Titulo, Reason: "ya existe un libro con ese título y autor"})
+			continue
+		}
+		// Un ISBN repetido haría fallar el lote entero por el índice único:
+		// se detecta antes y se reporta solo esa fila
+		if book.ISBN != "" {
+			if row, ok := seenISBN[book.ISBN]; ok {
+				report.add(ImportRow{Row: rowNum, Status: ImportFailed, Title: book.Titulo, Reason: fmt.Sprintf("el ISBN %s ya aparece en la fila %d", book.ISBN, row)})
+				continue
+			}
+			other, err := s.store.BookStorage.GetByISBN(ctx, book.ISBN)
+			if err != nil {
+				return nil, err
+			}
+			if other != nil {
+				report.add(ImportRow{Row: rowNum, Status: ImportFailed, Title: book.Titulo, Reason: fmt.Sprintf("el ISBN %s ya pertenece al libro %d", book.ISBN, other.ID)})
+				continue
+			}
+			seenISBN[book.ISBN] = rowNum
+		}
+		seen[key] = true
+
+		pending = append(pending, book)
+		pendingRows = append(pendingRows, ImportRow{Row: rowNum, Title: book.Titulo})
+		if len(pending) == importChunkSize {
+			flush()
+		}
+	}
+	flush()
+	// Los lotes se resuelven después de los omitidos: se reordena por fila
+	slices.SortFunc(report.Rows, func(a, b ImportRow) int { return a.Row - b.Row })
+
+	slog.InfoContext(ctx, "importación CSV terminada",
+		slog.Int("created", report.Created),
+		slog.Int("skipped", report.Skipped),
+		slog.Int("failed", report.Failed),
+	)
+	return report, readErr
+}
+
+// bookFromRecord arma un libro con las columnas resueltas de una fila
+func bookFromRecord(record []string, cols map[string]int) (*model.Book, error) {
+	get := func(field string) string {
+		i, ok := cols[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return Trim(record[i])
+	}
+
+	book := &model.Book{Titulo: get("title"), Autor: get("author"), ISBN: get("isbn"), Editorial: get("publisher")}
+	if year := get("year"); year != "" {
+		n, err := strconv.Atoi(year)
+		if err != nil {
+			return book, fmt.Errorf("año inválido %q", year)
+		}
+		book.Anio = n
+	}
+	if tags := get("tags"); tags != "" {
+		// Dentro de una celda las etiquetas se separan con ';' o '|'
+		for _, t := range strings.FieldsFunc(tags, func(r rune) bool { return r == ';' || r == '|' }) {
+			if t = Trim(t); t != "" {
+				book.Etiquetas = append(book.Etiquetas, t)
+			}
+		}
+	}
+	return book, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// Si la lectura se corta después de que se guardó el primer lote, el reporte
+// parcial vuelve junto con el error y dice qué filas quedaron creadas
+func TestImportCSVReadErrorAfterChunk(t *testing.T) {
+	ctx := context.Background()
+	books := NewBook(*newTestStore(t))
+
+	var csv strings.Builder
+	csv.WriteString("title,author\n")
+	rows := importChunkSize + importChunkSize/2
+	for i := range rows {
+		fmt.Fprintf(&csv, "Libro %d,Autora %d\n", i, i)
+	}
+	errCut := errors.New("conexión cortada")
+	report, err := books.ImportCSV(ctx, io.MultiReader(strings.NewReader(csv.String()), iotest.ErrReader(errCut)), nil)
+
+	if !errors.Is(err, errCut) {
+		t.Fatalf("err = %v, se esperaba el error de lectura", err)
+	}
+	if report == nil {
+		t.Fatal("no se devolvió el reporte parcial")
+	}
+	if report.Created != rows || report.Failed != 1 {
+		t.Fatalf("created = %d, failed = %d; se esperaba %d y 1", report.Created, report.Failed, rows)
+	}
+	last := report.Rows[len(report.Rows)-1]
+	if last.Status != ImportFailed || last.Row != rows+2 || !strings.Contains(last.Reason, "conexión cortada") {
+		t.Errorf("última fila = %+v", last)
+	}
+	all, err := books.GetAllBooks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != rows {
+		t.Errorf("hay %d libros en la base, el reporte dice %d", len(all), rows)
+	}
+}
+
+// Un error de sintaxis en una fila no corta la importación
+func TestImportCSVParseErrorContinues(t *testing.T) {
+	ctx := context.Background()
+	books := NewBook(*newTestStore(t))
+
+	report, err := books.ImportCSV(ctx, strings.NewReader("title,author\nFic\"ciones,Borges\nRayuela,Cortázar\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Failed != 1 || report.Created != 1 {
+		t.Fatalf("reporte = %+v", report)
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// marcRecord arma un <record> MARCXML con título, autor e ISBN
+func marcRecord(title, author, isbn string) string {
+	return `<record><leader>00000nam a2200000 a 4500</leader>` +
+		`<datafield tag="020" ind1=" " ind2=" "><subfield code="a">` + isbn + `</subfield></datafield>` +
+		`<datafield tag="100" ind1="0" ind2=" "><subfield code="a">` + author + `</subfield></datafield>` +
+		`<datafield tag="245" ind1="1" ind2="0"><subfield code="a">` + title + `</subfield></datafield>` +
+		`</record>`
+}
+
+// La simulación tiene que dar los mismos estados que la importación real
+// también cuando los duplicados están dentro del mismo archivo
+func TestImportMARCDryRunMatchesImport(t *testing.T) {
+	ctx := context.Background()
+	doc := `<collection xmlns="http://www.loc.gov/MARC21/slim">` +
+		marcRecord("Ficciones", "Jorge Luis Borges", "9788437604947") +
+		marcRecord("El Aleph", "Jorge Luis Borges", "9788437604947") + // mismo ISBN
+		marcRecord("FICCIONES", "jorge luis borges", "9789500430586") + // mismo título y autor
+		marcRecord("Rayuela", "Julio Cortázar", "9789500430586") +
+		`</collection>`
+
+	books := NewBook(*newTestStore(t))
+	dry, err := books.ImportMARC(ctx, strings.NewReader(doc), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := books.ImportMARC(ctx, strings.NewReader(doc), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ dry, real string }{
+		{ImportValid, ImportCreated},
+		{ImportSkipped, ImportSkipped},
+		{ImportSkipped, ImportSkipped},
+		{ImportValid, ImportCreated},
+	}
+	if len(dry.Rows) != len(want) || len(imported.Rows) != len(want) {
+		t.Fatalf("filas: simulación %d, importación %d; se esperaban %d", len(dry.Rows), len(imported.Rows), len(want))
+	}
+	for i, w := range want {
+		if dry.Rows[i].Status != w.dry || imported.Rows[i].Status != w.real {
+			t.Errorf("registro %d: simulación %+v, importación %+v", i+1, dry.Rows[i], imported.Rows[i])
+		}
+	}
+	if imported.Created != dry.Valid {
+		t.Errorf("la importación creó %d libros y la simulación anticipó %d", imported.Created, dry.Valid)
+	}
+}
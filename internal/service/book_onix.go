@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"practica-go/internal/model"
+	"practica-go/internal/onix"
+	"practica-go/internal/store"
+	"practica-go/internal/tracing"
+)
+
+// ImportONIX importa un mensaje ONIX 3.0 producto por producto. El ISBN es la
+// clave: si ya hay un libro con ese ISBN se actualiza, si no se crea. Los
+// productos sin ISBN o inválidos quedan como fallidos y las bajas (tipo de
+// notificación 05) se omiten: el catálogo no se borra desde un feed. Si el
+// XML se corta a mitad de camino se devuelve el reporte parcial con el error
+// como última fila.
+func (s *BookService) ImportONIX(ctx context.Context, r io.Reader) (*ImportReport, error) {
+	ctx, span := tracing.Start(ctx, "BookService.ImportONIX")
+	defer span.End()
+
+	report := &ImportReport{}
+	err := onix.Read(r, func(n int, p *onix.Product) error {
+		report.add(s.upsertProduct(ctx, n, p))
+		return ctx.Err()
+	})
+	if err != nil {
+		if len(report.Rows) == 0 {
+			return nil, err
+		}
+		// Los productos anteriores ya se guardaron: el corte queda en el reporte
+		report.add(ImportRow{Row: len(report.Rows) + 1, Status: ImportFailed, Reason: err.Error()})
+	}
+
+	slog.InfoContext(ctx, "importación ONIX terminada",
+		slog.Int("created", report.Created),
+		slog.Int("updated", report.Updated),
+		slog.Int("skipped", report.Skipped),
+		slog.Int("failed", report.Failed),
+	)
+	return report, nil
+}
+
+// upsertProduct crea o actualiza el libro de un producto y devuelve su fila del reporte
+func (s *BookService) upsertProduct(ctx context.Context, n int, p *onix.Product) ImportRow {
+	row := ImportRow{Row: n}
+	if p.Deleted() {
+		row.Status, row.Reason = ImportSkipped, "notificación de baja: no se eliminan libros desde ONIX"
+		return row
+	}
+
+	book, err := p.Book()
+	row.Title = book.Titulo
+	if err == nil && book.ISBN == "" {
+		err = errors.New("el producto no tiene ISBN")
+	}
+	if err == nil {
+		err = ValidateBook(book)
+	}
+	if err != nil {
+		row.Status, row.Reason = ImportFailed, err.Error()
+		return row
+	}
+
+	err = s.store.WithTx(ctx, func(tx *store.Store) error {
+		existing, err := tx.BookStorage.GetByISBN(ctx, book.ISBN)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			row.Status = ImportUpdated
+			book.Version = existing.Version
+			if _, err := tx.BookStorage.Update(ctx, existing.ID, book, store.BookFields); err != nil {
+				return err
+			}
+			return audit(ctx, tx, model.AuditUpdate, model.EntityBook, book.ID, existing, book)
+		}
+		row.Status = ImportCreated
+		if _, err := tx.BookStorage.Create(ctx, book); err != nil {
+			return err
+		}
+		return audit(ctx, tx, model.AuditCreate, model.EntityBook, book.ID, nil, book)
+	})
+	if err != nil {
+		row.Status, row.Reason = ImportFailed, err.Error()
+		return row
+	}
+	row.BookID = book.ID
+	return row
+}
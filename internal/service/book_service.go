@@ -1,9 +1,15 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"practica-go/internal/model"
+	"practica-go/internal/query"
 	"practica-go/internal/store"
+	"practica-go/internal/tracing"
+	"slices"
+	"strings"
 )
 
 // Service representa la capa de negocio de la aplicación.
@@ -20,27 +26,111 @@ func NewBook(s store.Store) *BookService {
 	}
 }
 
+// WithDeleted devuelve un BookService cuyas lecturas incluyen los libros
+// borrados. Es solo para admins: el handler verifica el rol.
+func (s *BookService) WithDeleted() *BookService {
+	return &BookService{store: *s.store.WithDeleted()}
+}
+
 // GetAllBooks obtiene todos los libros disponibles desde el almacenamiento.
-func (s *BookService) GetAllBooks() ([]*model.Book, error) {
-	return s.store.BookStorage.GetAll()
+func (s *BookService) GetAllBooks(ctx context.Context) ([]*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetAllBooks")
+	defer span.End()
+
+	return s.store.BookStorage.GetAll(ctx)
 }
 
 // SearchByTitleOrAuthor busca libros cuyo título o autor contengan el término indicado.
-func (s *BookService) SearchBookByTitleOrAuthor(term string) ([]*model.Book, error) {
+func (s *BookService) SearchBookByTitleOrAuthor(ctx context.Context, term string) ([]*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.SearchBookByTitleOrAuthor")
+	defer span.End()
+
 	term = Trim(term)
 	if term == "" {
 		return nil, errors.New("el término de búsqueda no puede quedar vacío")
 	}
-	return s.store.BookStorage.SearchByTitleOrAuthor(term)
+	return s.store.BookStorage.SearchByTitleOrAuthor(ctx, term)
+}
+
+// QueryBooks busca libros con el lenguaje de consultas avanzado
+// (ej. author:"Borges" -tag:ensayo year:1940..1960).
+// Si la consulta es inválida devuelve un *query.Error con la posición del problema.
+func (s *BookService) QueryBooks(ctx context.Context, q string) ([]*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.QueryBooks")
+	defer span.End()
+
+	expr, err := query.Parse(q)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.BookStorage.Query(ctx, expr)
+}
+
+// ExportBooks recorre el catálogo filtrado por expr (todo si es nil) y pasa
+// cada libro a fn a medida que se lee de la base. Se usa para exportaciones
+// grandes que no conviene cargar enteras en memoria.
+func (s *BookService) ExportBooks(ctx context.Context, expr query.Expr, fn func(*model.Book) error) error {
+	ctx, span := tracing.Start(ctx, "BookService.ExportBooks")
+	defer span.End()
+
+	count := 0
+	err := s.store.BookStorage.Each(ctx, expr, func(b *model.Book) error {
+		count++
+		return fn(b)
+	})
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "catálogo exportado", slog.Int("books", count))
+	return nil
+}
+
+// ListBooks devuelve una página del catálogo filtrado por expr (todo si es
+// nil) y el total de libros que cumplen el filtro, para armar la paginación.
+func (s *BookService) ListBooks(ctx context.Context, expr query.Expr, newestFirst bool, limit, offset int) ([]*model.Book, int, error) {
+	ctx, span := tracing.Start(ctx, "BookService.ListBooks")
+	defer span.End()
+
+	if limit <= 0 || offset < 0 {
+		return nil, 0, errors.New("paginación inválida")
+	}
+	total, err := s.store.BookStorage.Count(ctx, expr)
+	if err != nil {
+		return nil, 0, err
+	}
+	books, err := s.store.BookStorage.Page(ctx, expr, newestFirst, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return books, total, nil
+}
+
+// BookAuthors lista los autores del catálogo con su cantidad de libros
+func (s *BookService) BookAuthors(ctx context.Context) ([]store.Facet, error) {
+	ctx, span := tracing.Start(ctx, "BookService.BookAuthors")
+	defer span.End()
+
+	return s.store.BookStorage.Authors(ctx)
+}
+
+// BookTags lista las etiquetas del catálogo con su cantidad de libros
+func (s *BookService) BookTags(ctx context.Context) ([]store.Facet, error) {
+	ctx, span := tracing.Start(ctx, "BookService.BookTags")
+	defer span.End()
+
+	return s.store.BookStorage.Tags(ctx)
 }
 
 // GetBookByID obtiene un libro específico según su ID.
-func (s *BookService) GetBookByID(id int) (*model.Book, error) {
+func (s *BookService) GetBookByID(ctx context.Context, id int) (*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBookByID")
+	defer span.End()
+
 	if id <= 0 {
 		return nil, errors.New("el id debe ser positivo")
 	}
 
-	book, err := s.store.BookStorage.GetByID(id)
+	book, err := s.store.BookStorage.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -50,24 +140,81 @@ func (s *BookService) GetBookByID(id int) (*model.Book, error) {
 	return book, nil
 }
 
+// BooksByIDs busca varios libros a la vez y los devuelve indexados por ID.
+// Los IDs inexistentes no están en el mapa.
+func (s *BookService) BooksByIDs(ctx context.Context, ids []int) (map[int]*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.BooksByIDs")
+	defer span.End()
+
+	books, err := s.store.BookStorage.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*model.Book, len(books))
+	for _, b := range books {
+		byID[b.ID] = b
+	}
+	return byID, nil
+}
+
+// BooksByAuthors devuelve los libros de cada autor pedido, agrupados por autor
+func (s *BookService) BooksByAuthors(ctx context.Context, authors []string) (map[string][]*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.BooksByAuthors")
+	defer span.End()
+
+	books, err := s.store.BookStorage.GetByAuthors(ctx, authors)
+	if err != nil {
+		return nil, err
+	}
+	byAuthor := make(map[string][]*model.Book, len(authors))
+	for _, b := range books {
+		byAuthor[b.Autor] = append(byAuthor[b.Autor], b)
+	}
+	return byAuthor, nil
+}
+
 // BookExists verifica si existe un libro con el ID dado.
-func (s *BookService) BookExists(id int) (bool, error) {
+func (s *BookService) BookExists(ctx context.Context, id int) (bool, error) {
+	ctx, span := tracing.Start(ctx, "BookService.BookExists")
+	defer span.End()
+
 	if id <= 0 {
 		return false, errors.New("el id debe ser positivo")
 	}
-	return s.store.BookStorage.Exists(id)
+	return s.store.BookStorage.Exists(ctx, id)
 }
 
 // CreateBook crea un nuevo libro en la base de datos, validando sus datos antes.
-func (s *BookService) CreateBook(libro *model.Book) (*model.Book, error) {
+func (s *BookService) CreateBook(ctx context.Context, libro *model.Book) (*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.CreateBook")
+	defer span.End()
+
 	if err := ValidateBook(libro); err != nil {
 		return nil, err
 	}
-	return s.store.BookStorage.Create(libro)
+
+	var created *model.Book
+	err := s.store.WithTx(ctx, func(tx *store.Store) error {
+		var err error
+		if created, err = tx.BookStorage.Create(ctx, libro); err != nil {
+			return err
+		}
+		return audit(ctx, tx, model.AuditCreate, model.EntityBook, created.ID, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "libro creado", slog.Int("book_id", created.ID))
+	return created, nil
 }
 
-// UpdateBook actualiza los datos de un libro existente por ID.
-func (s *BookService) UpdateBook(id int, libro *model.Book) (*model.Book, error) {
+// UpdateBook actualiza los datos de un libro existente por ID. El libro
+// tiene que seguir en libro.Version; si cambió devuelve
+// store.ErrVersionConflict. Con store.AnyVersion no se controla la versión.
+func (s *BookService) UpdateBook(ctx context.Context, id int, libro *model.Book) (*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.UpdateBook")
+	defer span.End()
+
 	if id <= 0 {
 		return nil, errors.New("el id debe ser positivo")
 	}
@@ -76,30 +223,190 @@ func (s *BookService) UpdateBook(id int, libro *model.Book) (*model.Book, error)
 		return nil, err
 	}
 
-	existing, err := s.store.BookStorage.SearchByTitleOrAuthor(libro.Titulo)
+	// La verificación y la actualización van en la misma transacción para
+	// que otra petición no cree el título entre una y otra
+	var updated *model.Book
+	err := s.store.WithTx(ctx, func(tx *store.Store) error {
+		taken, err := titleTaken(ctx, tx, libro.Titulo, id)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return errors.New("ya existe un libro con ese título")
+		}
+		before, err := tx.BookStorage.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return errors.New("libro no encontrado")
+		}
+		if err := checkVersion(libro.Version, before.Version); err != nil {
+			return err
+		}
+		libro.Version = before.Version
+		if updated, err = tx.BookStorage.Update(ctx, id, libro, store.BookFields); err != nil {
+			return err
+		}
+		return audit(ctx, tx, model.AuditUpdate, model.EntityBook, id, before, updated)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if len(existing) > 0 {
-		return nil, errors.New("ya existe un libro con ese título")
+	slog.InfoContext(ctx, "libro actualizado", slog.Int("book_id", id))
+	return updated, nil
+}
+
+// PatchBook modifica parcialmente un libro con un parche en formato
+// mediaType (patch.MergePatch o patch.JSONPatch). El libro resultante se
+// valida como en UpdateBook y solo se guardan los campos que cambiaron.
+// version funciona como libro.Version en UpdateBook.
+func (s *BookService) PatchBook(ctx context.Context, id, version int, mediaType string, doc []byte) (*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.PatchBook")
+	defer span.End()
+
+	if id <= 0 {
+		return nil, errors.New("el id debe ser positivo")
 	}
 
-	return s.store.BookStorage.Update(id, libro)
+	var updated *model.Book
+	err := s.store.WithTx(ctx, func(tx *store.Store) error {
+		before, err := tx.BookStorage.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return errors.New("libro no encontrado")
+		}
+		if err := checkVersion(version, before.Version); err != nil {
+			return err
+		}
+
+		libro := &model.Book{}
+		if err := applyPatch(before, mediaType, doc, libro); err != nil {
+			return err
+		}
+		if err := ValidateBook(libro); err != nil {
+			return err
+		}
+		fields, err := changedFields(before, libro, "id", "version", "deleted_at")
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			updated = before
+			return nil
+		}
+		if slices.Contains(fields, "title") {
+			taken, err := titleTaken(ctx, tx, libro.Titulo, id)
+			if err != nil {
+				return err
+			}
+			if taken {
+				return errors.New("ya existe un libro con ese título")
+			}
+		}
+		if updated, err = tx.BookStorage.Update(ctx, id, libro, fields); err != nil {
+			return err
+		}
+		return audit(ctx, tx, model.AuditUpdate, model.EntityBook, id, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "libro actualizado", slog.Int("book_id", id))
+	return updated, nil
+}
+
+// titleTaken indica si otro libro distinto de id ya tiene ese título
+func titleTaken(ctx context.Context, tx *store.Store, title string, id int) (bool, error) {
+	existing, err := tx.BookStorage.SearchByTitleOrAuthor(ctx, title)
+	if err != nil {
+		return false, err
+	}
+	for _, b := range existing {
+		if b.ID != id && strings.EqualFold(b.Titulo, title) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
-// DeleteBook elimina un libro existente según su ID.
-func (s *BookService) DeleteBook(id int) error {
+// DeleteBook elimina un libro existente según su ID. version funciona como
+// libro.Version en UpdateBook.
+func (s *BookService) DeleteBook(ctx context.Context, id, version int) error {
+	ctx, span := tracing.Start(ctx, "BookService.DeleteBook")
+	defer span.End()
+
 	if id <= 0 {
 		return errors.New("el id debe ser positivo")
 	}
 
-	exists, err := s.store.BookStorage.Exists(id)
+	err := s.store.WithTx(ctx, func(tx *store.Store) error {
+		before, err := tx.BookStorage.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return errors.New("no se puede eliminar: el libro no existe")
+		}
+		if err := checkVersion(version, before.Version); err != nil {
+			return err
+		}
+		if err := tx.BookStorage.Delete(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, tx, model.AuditDelete, model.EntityBook, id, before, nil)
+	})
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return errors.New("no se puede eliminar: el libro no existe")
+	slog.InfoContext(ctx, "libro eliminado", slog.Int("book_id", id))
+	return nil
+}
+
+// RestoreBook vuelve a dar de alta un libro borrado. Falla si mientras tanto
+// otro libro tomó su ISBN.
+func (s *BookService) RestoreBook(ctx context.Context, id int) (*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.RestoreBook")
+	defer span.End()
+
+	if id <= 0 {
+		return nil, errors.New("el id debe ser positivo")
 	}
 
-	return s.store.BookStorage.Delete(id)
+	var restored model.Book
+	err := s.store.WithTx(ctx, func(tx *store.Store) error {
+		before, err := tx.WithDeleted().BookStorage.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return errors.New("libro no encontrado")
+		}
+		if before.DeletedAt == nil {
+			return errors.New("el libro no está borrado")
+		}
+		if before.ISBN != "" {
+			existing, err := tx.BookStorage.GetByISBN(ctx, before.ISBN)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return errors.New("ya existe otro libro con ese ISBN")
+			}
+		}
+		if err := tx.BookStorage.Restore(ctx, id); err != nil {
+			return err
+		}
+		restored = *before
+		restored.DeletedAt = nil
+		restored.Version++
+		return audit(ctx, tx, model.AuditRestore, model.EntityBook, id, before, &restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "libro restaurado", slog.Int("book_id", id))
+	return &restored, nil
 }
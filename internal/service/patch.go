@@ -0,0 +1,63 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"practica-go/internal/patch"
+	"slices"
+	"strings"
+)
+
+// applyPatch aplica a current el parche doc en el formato mediaType
+// (patch.MergePatch o patch.JSONPatch) y decodifica el resultado en out
+func applyPatch(current any, mediaType string, doc []byte, out any) error {
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	patched, err := patch.Apply(mediaType, raw, doc)
+	if err != nil {
+		return fmt.Errorf("no se pudo aplicar el parche: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &typeErr) && typeErr.Field == "":
+			return errors.New("el resultado del parche tiene que ser un objeto")
+		case errors.As(err, &typeErr):
+			return fmt.Errorf("el parche deja un valor inválido en %s", typeErr.Field)
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			return fmt.Errorf("el campo %s no existe", strings.TrimPrefix(err.Error(), "json: unknown field "))
+		default:
+			return fmt.Errorf("el resultado del parche no es válido: %w", err)
+		}
+	}
+	return nil
+}
+
+// changedFields devuelve los campos JSON que cambiaron entre before y after,
+// ordenados. Falla si cambió alguno de readonly.
+func changedFields(before, after any, readonly ...string) ([]string, error) {
+	_, bm, err := snapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	_, am, err := snapshot(after)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for field := range diff(bm, am) {
+		if slices.Contains(readonly, field) {
+			return nil, fmt.Errorf("el campo %s es de solo lectura", field)
+		}
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	return fields, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"practica-go/internal/model"
+	"practica-go/internal/patch"
+	"practica-go/internal/security"
+	"practica-go/internal/store"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	if err := security.SetBcryptCost(bcrypt.MinCost); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st := store.New(db)
+	if _, err := st.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestPatchBook(t *testing.T) {
+	ctx := context.Background()
+	books := NewBook(*newTestStore(t))
+	created, err := books.CreateBook(ctx, &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges", Etiquetas: []string{"cuentos"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, mediaType, doc, wantErr string
+	}{
+		{"id", patch.MergePatch, `{"id":99}`, "el campo id es de solo lectura"},
+		{"version", patch.MergePatch, `{"version":9}`, "el campo version es de solo lectura"},
+		{"deleted_at", patch.MergePatch, `{"deleted_at":"2024-01-01T00:00:00Z"}`, "el campo deleted_at es de solo lectura"},
+		{"id con JSON Patch", patch.JSONPatch, `[{"op":"replace","path":"/id","value":99}]`, "el campo id es de solo lectura"},
+		{"quitar la versión", patch.JSONPatch, `[{"op":"remove","path":"/version"}]`, "el campo version es de solo lectura"},
+		{"deleted_at con JSON Patch", patch.JSONPatch, `[{"op":"add","path":"/deleted_at","value":"2024-01-01T00:00:00Z"}]`, "el campo deleted_at es de solo lectura"},
+		{"campo desconocido", patch.MergePatch, `{"pages":300}`, `el campo "pages" no existe`},
+		{"tipo inválido", patch.MergePatch, `{"year":"1944"}`, "el parche deja un valor inválido en year"},
+		{"resultado no es un objeto", patch.JSONPatch, `[{"op":"replace","path":"","value":[]}]`, "el resultado del parche tiene que ser un objeto"},
+		{"resultado inválido", patch.MergePatch, `{"title":null}`, "título"},
+		{"test fallido", patch.JSONPatch, `[{"op":"test","path":"/title","value":"El Aleph"}]`, patch.ErrTestFailed.Error()},
+		{"formato desconocido", "application/json", `{"title":"El Aleph"}`, "formato de parche no soportado"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := books.PatchBook(ctx, created.ID, created.Version, tt.mediaType, []byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, se esperaba %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Ningún parche rechazado llegó a la base
+	current, err := books.GetBookByID(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Version != created.Version {
+		t.Fatalf("versión = %d, se esperaba %d", current.Version, created.Version)
+	}
+
+	// Reescribir un campo de solo lectura con su mismo valor no es un cambio
+	same, err := books.PatchBook(ctx, created.ID, created.Version, patch.MergePatch, []byte(`{"id":`+strconv.Itoa(created.ID)+`}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.Version != created.Version {
+		t.Errorf("un parche sin cambios subió la versión a %d", same.Version)
+	}
+
+	updated, err := books.PatchBook(ctx, created.ID, created.Version, patch.JSONPatch,
+		[]byte(`[{"op":"test","path":"/title","value":"Ficciones"},{"op":"add","path":"/tags/-","value":"borges"},{"op":"add","path":"/year","value":1944}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Anio != 1944 || len(updated.Etiquetas) != 2 || updated.Version != created.Version+1 {
+		t.Errorf("libro = %+v", updated)
+	}
+}
+
+func TestPatchUser(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	users := NewUser(*st, security.NewTokenIssuer("0123456789abcdef0123456789abcdef", time.Minute, time.Hour))
+	created, err := users.Register(ctx, &model.User{Username: "lector", Email: "lector@example.com", Password: "secreto123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, mediaType, doc, wantErr string
+	}{
+		{"role", patch.MergePatch, `{"role":"admin"}`, "el campo role es de solo lectura"},
+		{"role con JSON Patch", patch.JSONPatch, `[{"op":"replace","path":"/role","value":"admin"}]`, "el campo role es de solo lectura"},
+		{"id", patch.MergePatch, `{"id":99}`, "el campo id es de solo lectura"},
+		{"version", patch.JSONPatch, `[{"op":"replace","path":"/version","value":9}]`, "el campo version es de solo lectura"},
+		{"deleted_at", patch.MergePatch, `{"deleted_at":"2024-01-01T00:00:00Z"}`, "el campo deleted_at es de solo lectura"},
+		{"campo desconocido", patch.MergePatch, `{"admin":true}`, `el campo "admin" no existe`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := users.PatchUser(ctx, created.ID, created.Version, tt.mediaType, []byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, se esperaba %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	current, err := users.GetUserByID(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Role != "user" || current.Version != created.Version {
+		t.Fatalf("usuario = %+v, se esperaba rol user y versión %d", current, created.Version)
+	}
+
+	// La contraseña se puede cambiar por parche y no vuelve en la respuesta
+	updated, err := users.PatchUser(ctx, created.ID, created.Version, patch.MergePatch, []byte(`{"email":"lectora@example.com","password":"nueva-clave"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Email != "lectora@example.com" || updated.Password != "" || updated.Version != created.Version+1 {
+		t.Errorf("usuario = %+v", updated)
+	}
+	if _, err := users.Login(ctx, "lector", "nueva-clave"); err != nil {
+		t.Errorf("login con la nueva contraseña: %v", err)
+	}
+}
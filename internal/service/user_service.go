@@ -1,44 +1,90 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"practica-go/internal/metrics"
 	"practica-go/internal/model"
 	"practica-go/internal/security"
 	"practica-go/internal/store"
+	"practica-go/internal/tracing"
+)
+
+// Roles de usuario
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
 )
 
 type UserService struct {
-	store store.Store
+	store  store.Store
+	tokens *security.TokenIssuer
 }
 
-func NewUser(s store.Store) *UserService {
+func NewUser(s store.Store, tokens *security.TokenIssuer) *UserService {
 	return &UserService{
-		store: s,
+		store:  s,
+		tokens: tokens,
 	}
 }
 
+// WithDeleted devuelve un UserService cuyas lecturas incluyen los usuarios
+// borrados. Es solo para admins: el handler verifica el rol.
+func (s *UserService) WithDeleted() *UserService {
+	return &UserService{store: *s.store.WithDeleted(), tokens: s.tokens}
+}
+
 // GetAllUser devuelve todos los usuarios almacenados
-func (s *UserService) GetAllUser() ([]*model.User, error) {
-	return s.store.UserStorage.GetAllUser()
+func (s *UserService) GetAllUser(ctx context.Context) ([]*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAllUser")
+	defer span.End()
+
+	return s.store.UserStorage.GetAllUser(ctx)
 }
 
 // SearchUserByUserOrEmail busca usuarios por username o email
-func (s *UserService) SearchUserByUserOrEmail(term string) ([]*model.User, error) {
+func (s *UserService) SearchUserByUserOrEmail(ctx context.Context, term string) ([]*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.SearchUserByUserOrEmail")
+	defer span.End()
+
 	term = Trim(term)
 	if term == "" {
 		return nil, errors.New("el término no puede estar vacío")
 	}
-	return s.store.UserStorage.SearchByUserOrEmail(term)
+	return s.store.UserStorage.SearchByUserOrEmail(ctx, term)
 }
 
 // GetUsersByEmailOrUser obtiene un usuario por email o username
-func (s *UserService) GetUsersByEmailOrUser(term string) (*model.User, error) {
+func (s *UserService) GetUsersByEmailOrUser(ctx context.Context, term string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUsersByEmailOrUser")
+	defer span.End()
+
 	term = Trim(term)
 	if term == "" {
 		return nil, errors.New("el usuario o email no puede estar vacío")
 	}
 
-	user, err := s.store.UserStorage.GetByEmailOrUser(term)
+	user, err := s.store.UserStorage.GetByEmailOrUser(ctx, term)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("usuario no encontrado")
+	}
+	user.Password = "" // nunca exponer el hash
+	return user, nil
+}
+
+// GetUserByID obtiene un usuario por su ID, sin la contraseña
+func (s *UserService) GetUserByID(ctx context.Context, id int) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
+
+	if id <= 0 {
+		return nil, errors.New("el id debe ser positivo")
+	}
+	user, err := s.store.UserStorage.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -49,122 +95,358 @@ func (s *UserService) GetUsersByEmailOrUser(term string) (*model.User, error) {
 }
 
 // ExistsUser verifica si un usuario existe por ID
-func (s *UserService) ExistsUser(id int) (bool, error) {
+func (s *UserService) ExistsUser(ctx context.Context, id int) (bool, error) {
+	ctx, span := tracing.Start(ctx, "UserService.ExistsUser")
+	defer span.End()
+
 	if id <= 0 {
 		return false, errors.New("el id tiene que ser positivo")
 	}
-	return s.store.UserStorage.Exists(id)
+	return s.store.UserStorage.Exists(ctx, id)
 }
 
 // Register crea un nuevo usuario, aplicando validaciones y hash de contraseña
-func (s *UserService) Register(user *model.User) (*model.User, error) {
+func (s *UserService) Register(ctx context.Context, user *model.User) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Register")
+	defer span.End()
+
+	return s.create(ctx, user, RoleUser)
+}
+
+// CreateAdmin crea un usuario administrador. No se expone por HTTP: es la
+// única forma de obtener el rol admin y se usa desde la CLI.
+func (s *UserService) CreateAdmin(ctx context.Context, user *model.User) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateAdmin")
+	defer span.End()
+
+	return s.create(ctx, user, RoleAdmin)
+}
+
+// create valida, hashea la contraseña y guarda el usuario con el rol indicado
+func (s *UserService) create(ctx context.Context, user *model.User, role string) (*model.User, error) {
 	if err := ValidateUser(user); err != nil {
 		return nil, err
 	}
 
-	// Comprobar si ya existe usuario con email o username
-	existing, _ := s.store.UserStorage.GetByEmailOrUser(user.Username)
-	if existing != nil {
-		return nil, errors.New("ya existe un usuario con ese username o email")
-	}
-
-	// Hashear contraseña y asignar rol por defecto
+	// Hashear contraseña y asignar rol (antes de la transacción: es lento)
 	hashed, err := security.HashPassword(user.Password)
 	if err != nil {
 		return nil, err
 	}
 	user.Password = hashed
-	user.Role = "user"
+	user.Role = role
 
-	created, err := s.store.UserStorage.CreateUser(user)
+	// Comprobar si ya existe usuario con email o username, en la misma
+	// transacción que el alta. Los de usuarios borrados siguen reservados
+	// hasta la purga.
+	var created *model.User
+	err = s.store.WithTx(ctx, func(tx *store.Store) error {
+		for _, term := range []string{user.Username, user.Email} {
+			existing, err := tx.WithDeleted().UserStorage.GetByEmailOrUser(ctx, term)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return errors.New("ya existe un usuario con ese username o email")
+			}
+		}
+		if created, err = tx.UserStorage.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		return audit(ctx, tx, model.AuditCreate, model.EntityUser, created.ID, nil, created)
+	})
 	if err != nil {
 		return nil, err
 	}
 	created.Password = "" // limpiar contraseña antes de devolver
+	slog.InfoContext(ctx, "usuario registrado", slog.Any("user", created))
 	return created, nil
 }
 
-// Aplica validaciones si se modifican campos y hashea la contraseña si cambio
-func (s *UserService) UpdateUser(id int, data *model.User) (*model.User, error) {
+// ResetPassword reemplaza la contraseña de un usuario buscado por username o email
+func (s *UserService) ResetPassword(ctx context.Context, userOrEmail, password string) error {
+	ctx, span := tracing.Start(ctx, "UserService.ResetPassword")
+	defer span.End()
+
+	if len(password) < 6 {
+		return errors.New("la contraseña debe tener al menos 6 caracteres")
+	}
+	user, err := s.GetUsersByEmailOrUser(ctx, userOrEmail)
+	if err != nil {
+		return err
+	}
+	hashed, err := security.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	err = s.store.WithTx(ctx, func(tx *store.Store) error {
+		if err := tx.UserStorage.UpdatePassword(ctx, user.ID, hashed); err != nil {
+			return err
+		}
+		return audit(ctx, tx, model.AuditResetPassword, model.EntityUser, user.ID, nil, nil, "password")
+	})
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "contraseña restablecida", slog.Int("target_user_id", user.ID))
+	return nil
+}
+
+// UpdateUser modifica los datos de un usuario; los campos vacíos de data no
+// cambian y solo se guardan los que cambiaron. Hashea la contraseña si cambia.
+// El usuario tiene que seguir en data.Version; si cambió devuelve
+// store.ErrVersionConflict. Con store.AnyVersion no se controla la versión.
+func (s *UserService) UpdateUser(ctx context.Context, id int, data *model.User) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUser")
+	defer span.End()
+
+	before, err := s.current(ctx, id, data.Version)
+	if err != nil {
+		return nil, err
+	}
+	merged := *before
+	if data.Username != "" {
+		merged.Username = data.Username
+	}
+	if data.Email != "" {
+		merged.Email = data.Email
+	}
+	merged.Password = data.Password
+	return s.save(ctx, before, &merged)
+}
+
+// PatchUser modifica parcialmente un usuario con un parche en formato
+// mediaType (patch.MergePatch o patch.JSONPatch). El rol no se puede
+// cambiar. version funciona como en UpdateUser.
+func (s *UserService) PatchUser(ctx context.Context, id, version int, mediaType string, doc []byte) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.PatchUser")
+	defer span.End()
+
+	before, err := s.current(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	merged := &model.User{}
+	if err := applyPatch(before, mediaType, doc, merged); err != nil {
+		return nil, err
+	}
+	return s.save(ctx, before, merged)
+}
+
+// current busca el usuario a modificar y verifica que siga en version
+func (s *UserService) current(ctx context.Context, id, version int) (*model.User, error) {
 	if id <= 0 {
 		return nil, errors.New("el id debe ser positivo")
 	}
-
-	exists, err := s.store.UserStorage.Exists(id)
+	user, err := s.store.UserStorage.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
+	if user == nil {
 		return nil, errors.New("usuario no encontrado")
 	}
+	if err := checkVersion(version, user.Version); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
 
-	// Validar datos solo si se modifican campos relevantes
-	if data.Username != "" || data.Email != "" || data.Password != "" {
-		if err := ValidateUser(data); err != nil {
-			return nil, err
-		}
+// save valida el usuario modificado y guarda los campos que cambiaron
+// respecto de before. La contraseña de merged, si no está vacía, es la nueva
+// en texto plano. El UPDATE controla la versión de before, así que si otro
+// cambió el usuario después de leerlo devuelve store.ErrVersionConflict.
+func (s *UserService) save(ctx context.Context, before, merged *model.User) (*model.User, error) {
+	if err := validateProfile(merged); err != nil {
+		return nil, err
+	}
+	password := merged.Password
+	merged.Password = ""
+	fields, err := changedFields(before, merged, "id", "role", "version", "deleted_at")
+	if err != nil {
+		return nil, err
 	}
 
-	// Hashear contraseña si se actualiza
-	if data.Password != "" {
-		hashed, err := security.HashPassword(data.Password)
-		if err != nil {
+	// Hashear contraseña si se actualiza (antes de la transacción: es lento)
+	var changed []string
+	if password != "" {
+		if err := validatePassword(password); err != nil {
+			return nil, err
+		}
+		if merged.Password, err = security.HashPassword(password); err != nil {
 			return nil, err
 		}
-		data.Password = hashed
+		fields = append(fields, "password")
+		changed = append(changed, "password")
+	}
+	if len(fields) == 0 {
+		return before, nil
 	}
 
-	updated, err := s.store.UserStorage.Update(id, data)
+	merged.Version = before.Version
+	var updated *model.User
+	err = s.store.WithTx(ctx, func(tx *store.Store) error {
+		// El username y el email de usuarios borrados siguen reservados
+		for _, field := range fields {
+			var term string
+			switch field {
+			case "username":
+				term = merged.Username
+			case "email":
+				term = merged.Email
+			default:
+				continue
+			}
+			existing, err := tx.WithDeleted().UserStorage.GetByEmailOrUser(ctx, term)
+			if err != nil {
+				return err
+			}
+			if existing != nil && existing.ID != before.ID {
+				return errors.New("ya existe un usuario con ese username o email")
+			}
+		}
+		var err error
+		if updated, err = tx.UserStorage.Update(ctx, before.ID, merged, fields); err != nil {
+			return err
+		}
+		updated.Password = ""
+		return audit(ctx, tx, model.AuditUpdate, model.EntityUser, before.ID, before, updated, changed...)
+	})
 	if err != nil {
 		return nil, err
 	}
-	updated.Password = "" // limpiar contraseña antes de devolver
+	slog.InfoContext(ctx, "usuario actualizado", slog.Int("target_user_id", before.ID))
 	return updated, nil
 }
 
 // Login valida las credenciales del usuario y devuelve el usuario sin contraseña
-func (s *UserService) Login(userOrEmail, password string) (*model.User, error) {
+func (s *UserService) Login(ctx context.Context, userOrEmail, password string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Login")
+	defer span.End()
+
 	userOrEmail = Trim(userOrEmail)
 	password = Trim(password)
 	if userOrEmail == "" || password == "" {
 		return nil, errors.New("usuario/email y contraseña son requeridos")
 	}
 
-	user, err := s.store.UserStorage.GetByEmailOrUser(userOrEmail)
+	user, err := s.store.UserStorage.GetByEmailOrUser(ctx, userOrEmail)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
+		metrics.LoginAttempts.Inc("failure")
+		slog.WarnContext(ctx, "login fallido: usuario inexistente", slog.String("login", userOrEmail))
 		return nil, errors.New("usuario no encontrado")
 	}
 
 	if !security.CheckPasswordHash(password, user.Password) {
+		metrics.LoginAttempts.Inc("failure")
+		slog.WarnContext(ctx, "login fallido: contraseña incorrecta", slog.Int("target_user_id", user.ID))
 		return nil, errors.New("contraseña incorrecta")
 	}
 
+	metrics.LoginAttempts.Inc("success")
+	slog.InfoContext(ctx, "login correcto", slog.Int("target_user_id", user.ID))
 	user.Password = "" // limpiar password antes de devolver
 	return user, nil
 }
 
-// DeleteUser elimina un usuario existente según su ID.
-func (s *UserService) DeleteUser(id int) error {
+// IssueTokens genera el par de tokens de acceso y refresco para un usuario ya autenticado
+func (s *UserService) IssueTokens(user *model.User) (*security.TokenPair, error) {
+	return s.tokens.Issue(user.ID, user.Role)
+}
+
+// RefreshTokens canjea un token de refresco por un par nuevo.
+// El rol se vuelve a leer de la base por si cambió desde el login.
+func (s *UserService) RefreshTokens(ctx context.Context, refreshToken string) (*security.TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "UserService.RefreshTokens")
+	defer span.End()
+
+	claims, err := s.tokens.Verify(Trim(refreshToken), security.TokenRefresh)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.store.UserStorage.GetByID(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("usuario no encontrado")
+	}
+	return s.tokens.Issue(user.ID, user.Role)
+}
+
+// DeleteUser elimina un usuario existente según su ID. version funciona como
+// data.Version en UpdateUser.
+func (s *UserService) DeleteUser(ctx context.Context, id, version int) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
 	if id <= 0 {
 		return errors.New("el id debe ser positivo")
 	}
 
-	exists, err := s.store.UserStorage.Exists(id)
+	err := s.store.WithTx(ctx, func(tx *store.Store) error {
+		before, err := tx.UserStorage.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return errors.New("usuario no encontrado")
+		}
+		if err := checkVersion(version, before.Version); err != nil {
+			return err
+		}
+		if err := tx.UserStorage.Delete(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, tx, model.AuditDelete, model.EntityUser, id, before, nil)
+	})
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return errors.New("usuario no encontrado")
+	slog.InfoContext(ctx, "usuario eliminado", slog.Int("target_user_id", id))
+	return nil
+}
+
+// RestoreUser vuelve a dar de alta un usuario borrado
+func (s *UserService) RestoreUser(ctx context.Context, id int) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.RestoreUser")
+	defer span.End()
+
+	if id <= 0 {
+		return nil, errors.New("el id debe ser positivo")
 	}
 
-	return s.store.UserStorage.Delete(id)
+	var restored model.User
+	err := s.store.WithTx(ctx, func(tx *store.Store) error {
+		before, err := tx.WithDeleted().UserStorage.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return errors.New("usuario no encontrado")
+		}
+		if before.DeletedAt == nil {
+			return errors.New("el usuario no está borrado")
+		}
+		if err := tx.UserStorage.Restore(ctx, id); err != nil {
+			return err
+		}
+		restored = *before
+		restored.DeletedAt = nil
+		restored.Version++
+		return audit(ctx, tx, model.AuditRestore, model.EntityUser, id, before, &restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "usuario restaurado", slog.Int("target_user_id", id))
+	return &restored, nil
 }
 
 // Logout es un marcador: en este service no hace nada.
 // Se puede implementar limpieza de tokens o sesiones si se desea.
-func (s *UserService) Logout() error {
+func (s *UserService) Logout(ctx context.Context) error {
 	return nil
 }
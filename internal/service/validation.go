@@ -3,7 +3,9 @@ package service
 import (
 	"errors"
 	"practica-go/internal/model"
+	"practica-go/internal/store"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -19,8 +21,19 @@ func ValidateUser(user *model.User) error {
 	if user.Username == "" || user.Email == "" || user.Password == "" {
 		return errors.New("username, email y password son requeridos")
 	}
-	if len(user.Password) < 6 {
-		return errors.New("la contraseña debe tener al menos 6 caracteres")
+	if err := validatePassword(user.Password); err != nil {
+		return err
+	}
+	return validateProfile(user)
+}
+
+// validateProfile valida el username y el email, que es lo obligatorio al
+// modificar un usuario (la contraseña solo se valida si cambia)
+func validateProfile(user *model.User) error {
+	user.Username = Trim(user.Username)
+	user.Email = Trim(user.Email)
+	if user.Username == "" || user.Email == "" {
+		return errors.New("username y email son requeridos")
 	}
 	if !isValidText(user.Username) {
 		return errors.New("username contiene caracteres inválidos")
@@ -28,6 +41,14 @@ func ValidateUser(user *model.User) error {
 	return nil
 }
 
+// validatePassword valida el largo de una contraseña nueva
+func validatePassword(password string) error {
+	if len(password) < 6 {
+		return errors.New("la contraseña debe tener al menos 6 caracteres")
+	}
+	return nil
+}
+
 // ValidateBook valida un libro
 func ValidateBook(book *model.Book) error {
 	book.Titulo = Trim(book.Titulo)
@@ -59,16 +80,138 @@ func ValidateBook(book *model.Book) error {
 		return errors.New("el nombre del autor contiene caracteres inválidos")
 	}
 
+	if book.Anio < 0 || book.Anio > time.Now().Year()+1 {
+		return errors.New("el año de publicación no es válido")
+	}
+
+	for i, tag := range book.Etiquetas {
+		tag = strings.ToLower(Trim(tag))
+		if tag == "" || strings.Contains(tag, ",") || !isValidText(tag) {
+			return errors.New("las etiquetas no pueden estar vacías ni contener comas o caracteres inválidos")
+		}
+		book.Etiquetas[i] = tag
+	}
+
+	if book.ISBN != "" {
+		isbn, err := NormalizeISBN(book.ISBN)
+		if err != nil {
+			return err
+		}
+		book.ISBN = isbn
+	}
+
+	book.Editorial = Trim(book.Editorial)
+	if len(book.Editorial) > 100 || !isValidText(book.Editorial) {
+		return errors.New("el nombre de la editorial no es válido")
+	}
+
+	for i := range book.Colaboradores {
+		c := &book.Colaboradores[i]
+		c.Nombre = Trim(c.Nombre)
+		c.Rol = strings.ToUpper(Trim(c.Rol))
+		if c.Nombre == "" || len(c.Nombre) > 100 || !isValidText(c.Nombre) {
+			return errors.New("el nombre del colaborador no es válido")
+		}
+		if c.Rol == "" {
+			return errors.New("cada colaborador necesita un rol")
+		}
+	}
+
+	for i := range book.Precios {
+		p := &book.Precios[i]
+		p.Moneda = strings.ToUpper(Trim(p.Moneda))
+		if p.Monto < 0 {
+			return errors.New("el precio no puede ser negativo")
+		}
+		if len(p.Moneda) != 3 {
+			return errors.New("la moneda debe ser un código ISO 4217 de tres letras")
+		}
+	}
+
 	return nil
 }
 
+// checkVersion compara la versión que envió el cliente con la actual del
+// registro. store.AnyVersion acepta cualquiera y 0 (sin versión) es un error.
+func checkVersion(version, current int) error {
+	switch {
+	case version == store.AnyVersion:
+		return nil
+	case version <= 0:
+		return errors.New("falta la versión del registro: usá la que devolvió la lectura")
+	case version != current:
+		return store.ErrVersionConflict
+	}
+	return nil
+}
+
+// NormalizeISBN acepta un ISBN-10 o ISBN-13 con o sin guiones, verifica el
+// dígito de control y lo devuelve como ISBN-13 sin separadores. Así el mismo
+// libro se reconoce aunque llegue escrito de distintas formas.
+func NormalizeISBN(isbn string) (string, error) {
+	var digits []byte
+	for _, r := range strings.ToUpper(isbn) {
+		switch {
+		case r >= '0' && r <= '9', r == 'X':
+			digits = append(digits, byte(r))
+		case r == '-' || r == ' ':
+			continue
+		default:
+			return "", errors.New("el ISBN contiene caracteres inválidos")
+		}
+	}
+
+	switch len(digits) {
+	case 10:
+		sum := 0
+		for i, d := range digits {
+			v := int(d - '0')
+			if d == 'X' {
+				if i != 9 {
+					return "", errors.New("el ISBN no es válido")
+				}
+				v = 10
+			}
+			sum += v * (10 - i)
+		}
+		if sum%11 != 0 {
+			return "", errors.New("el dígito de control del ISBN no es válido")
+		}
+		isbn13 := append([]byte("978"), digits[:9]...)
+		return string(append(isbn13, isbn13CheckDigit(isbn13))), nil
+	case 13:
+		if strings.ContainsRune(string(digits), 'X') {
+			return "", errors.New("el ISBN no es válido")
+		}
+		if isbn13CheckDigit(digits[:12]) != digits[12] {
+			return "", errors.New("el dígito de control del ISBN no es válido")
+		}
+		return string(digits), nil
+	default:
+		return "", errors.New("el ISBN debe tener 10 o 13 dígitos")
+	}
+}
+
+// isbn13CheckDigit calcula el dígito de control de los primeros 12 dígitos
+func isbn13CheckDigit(digits []byte) byte {
+	sum := 0
+	for i, d := range digits[:12] {
+		w := 1
+		if i%2 == 1 {
+			w = 3
+		}
+		sum += int(d-'0') * w
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
 // isValidText valida caracteres permitidos
 func isValidText(text string) bool {
 	for _, r := range text {
 		switch {
 		case unicode.IsLetter(r), unicode.IsNumber(r), unicode.IsSpace(r):
 			continue
-		case strings.ContainsRune(".,:;!?-'\"()¿¡", r):
+		case strings.ContainsRune(".,:;!?-'\"()¿¡&", r):
 			continue
 		default:
 			return false
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"practica-go/internal/model"
+	"practica-go/internal/store"
+	"practica-go/internal/tracing"
+	"practica-go/internal/webhook"
+	"slices"
+	"time"
+)
+
+// minWebhookSecret es el largo mínimo de un secreto elegido por el cliente
+const minWebhookSecret = 16
+
+// deliveriesLimit es cuántas entregas se devuelven en el registro de un webhook
+const deliveriesLimit = 100
+
+// WebhookService administra las suscripciones y su registro de entregas.
+// Las entregas las envía webhook.Dispatcher.
+type WebhookService struct {
+	store store.Store
+	// allowPrivate acepta urls a direcciones privadas o de loopback
+	allowPrivate bool
+}
+
+func NewWebhook(s store.Store, allowPrivate bool) *WebhookService {
+	return &WebhookService{store: s, allowPrivate: allowPrivate}
+}
+
+// CreateWebhook valida y guarda una suscripción. Si no se indica secreto se
+// genera uno; el secreto solo se devuelve en esta respuesta.
+func (s *WebhookService) CreateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.CreateWebhook")
+	defer span.End()
+
+	u, err := url.Parse(Trim(w.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("la url debe ser http o https absoluta")
+	}
+	// Un webhook a la red interna permitiría usar el servidor para llegar a
+	// servicios que no están expuestos (SSRF)
+	if !s.allowPrivate {
+		if err := webhook.CheckHost(ctx, u.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	w.URL = u.String()
+
+	if len(w.Events) == 0 {
+		return nil, errors.New("hay que indicar al menos un evento")
+	}
+	var events []string
+	for _, e := range w.Events {
+		if !slices.Contains(model.Events, e) {
+			return nil, fmt.Errorf("evento desconocido: %s", e)
+		}
+		if !slices.Contains(events, e) {
+			events = append(events, e)
+		}
+	}
+	w.Events = events
+
+	w.Secret = Trim(w.Secret)
+	if w.Secret == "" {
+		b := make([]byte, 32)
+		rand.Read(b)
+		w.Secret = hex.EncodeToString(b)
+	} else if len(w.Secret) < minWebhookSecret {
+		return nil, fmt.Errorf("el secreto debe tener al menos %d caracteres", minWebhookSecret)
+	}
+	w.CreatedAt = time.Now().UTC()
+
+	created, err := s.store.WebhookStorage.Create(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "webhook creado", slog.Int("webhook_id", created.ID), slog.String("url", created.URL))
+	return created, nil
+}
+
+// ListWebhooks devuelve las suscripciones, sin los secretos
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.ListWebhooks")
+	defer span.End()
+
+	return s.store.WebhookStorage.GetAll(ctx)
+}
+
+// GetWebhook obtiene una suscripción por ID, sin el secreto
+func (s *WebhookService) GetWebhook(ctx context.Context, id int) (*model.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetWebhook")
+	defer span.End()
+
+	if id <= 0 {
+		return nil, errors.New("el id debe ser positivo")
+	}
+	w, err := s.store.WebhookStorage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		return nil, errors.New("webhook no encontrado")
+	}
+	return w, nil
+}
+
+// DeleteWebhook borra la suscripción y sus entregas pendientes
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.DeleteWebhook")
+	defer span.End()
+
+	if _, err := s.GetWebhook(ctx, id); err != nil {
+		return err
+	}
+	if err := s.store.WebhookStorage.Delete(ctx, id); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "webhook eliminado", slog.Int("webhook_id", id))
+	return nil
+}
+
+// WebhookDeliveries devuelve las últimas entregas de un webhook
+func (s *WebhookService) WebhookDeliveries(ctx context.Context, webhookID int) ([]*model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.WebhookDeliveries")
+	defer span.End()
+
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.store.WebhookStorage.Deliveries(ctx, webhookID, deliveriesLimit)
+}
+
+// Redeliver vuelve a encolar una entrega (entregada o fallida) para que se
+// envíe de nuevo en la próxima pasada del dispatcher
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID, deliveryID int) (*model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Redeliver")
+	defer span.End()
+
+	d, err := s.store.WebhookStorage.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || d.WebhookID != webhookID {
+		return nil, errors.New("entrega no encontrada")
+	}
+
+	now := time.Now().UTC()
+	if err := s.store.WebhookStorage.Requeue(ctx, deliveryID, now); err != nil {
+		return nil, err
+	}
+	d.Status, d.Attempts, d.NextAttemptAt = model.DeliveryPending, 0, now
+	slog.InfoContext(ctx, "entrega reencolada", slog.Int("webhook_id", webhookID), slog.Int("delivery_id", deliveryID))
+	return d, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"practica-go/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestCreateWebhookPrivateDestination(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+
+	tests := []struct {
+		url          string
+		allowPrivate bool
+		wantErr      string
+	}{
+		{url: "http://127.0.0.1:9000/hooks", wantErr: "dirección privada o local"},
+		{url: "http://localhost/hooks", wantErr: "dirección privada o local"},
+		{url: "http://[::1]/hooks", wantErr: "dirección privada o local"},
+		{url: "http://10.0.0.5/hooks", wantErr: "dirección privada o local"},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: "dirección privada o local"},
+		{url: "https://203.0.113.10/hooks"},
+		{url: "http://127.0.0.1:9000/hooks", allowPrivate: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			hooks := NewWebhook(*st, tt.allowPrivate)
+			_, err := hooks.CreateWebhook(ctx, &model.Webhook{URL: tt.url, Events: []string{model.EventBookCreated}})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, se esperaba %q", err, tt.wantErr)
+			}
+		})
+	}
+}
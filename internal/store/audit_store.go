@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"practica-go/internal/model"
+	"strings"
+	"time"
+)
+
+// AuditStore guarda y consulta el registro de auditoría. No tiene métodos
+// para modificar ni borrar: la tabla es de solo agregado.
+type AuditStore interface {
+	Record(ctx context.Context, e *model.AuditEntry) error
+	Query(ctx context.Context, f AuditFilter) ([]*model.AuditEntry, error)
+}
+
+// AuditFilter son los criterios de consulta; los campos en cero no filtran.
+// Los resultados van del más nuevo al más viejo; BeforeID pagina devolviendo
+// los anteriores a ese ID.
+type AuditFilter struct {
+	EntityType string
+	EntityID   int
+	ActorID    int
+	From       time.Time
+	To         time.Time
+	BeforeID   int
+	Limit      int
+}
+
+type auditSQL struct {
+	db dbtx
+}
+
+// Record agrega una entrada. Dentro de WithTx queda en la misma transacción
+// que el cambio que registra.
+func (s *auditSQL) Record(ctx context.Context, e *model.AuditEntry) error {
+	defer observe(ctx, "AuditStore", "Record", time.Now())
+
+	var diff any
+	if len(e.Diff) > 0 {
+		b, err := json.Marshal(e.Diff)
+		if err != nil {
+			return err
+		}
+		diff = string(b)
+	}
+	q := `INSERT INTO audit_log (actor_id, actor_role, action, entity_type, entity_id, before, after, diff, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := s.db.ExecContext(ctx, q, e.ActorID, e.ActorRole, e.Action, e.EntityType, e.EntityID,
+		nullJSON(e.Before), nullJSON(e.After), diff, e.RequestID, e.CreatedAt.UTC())
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	e.ID = int(id)
+	return nil
+}
+
+// Query busca entradas con los criterios de f
+func (s *auditSQL) Query(ctx context.Context, f AuditFilter) ([]*model.AuditEntry, error) {
+	defer observe(ctx, "AuditStore", "Query", time.Now())
+
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		conds = append(conds, cond)
+		args = append(args, arg)
+	}
+	if f.EntityType != "" {
+		add("entity_type = ?", f.EntityType)
+	}
+	if f.EntityID > 0 {
+		add("entity_id = ?", f.EntityID)
+	}
+	if f.ActorID > 0 {
+		add("actor_id = ?", f.ActorID)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= ?", f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		add("created_at < ?", f.To.UTC())
+	}
+	if f.BeforeID > 0 {
+		add("id < ?", f.BeforeID)
+	}
+
+	q := "SELECT id, actor_id, actor_role, action, entity_type, entity_id, before, after, diff, request_id, created_at FROM audit_log"
+	if len(conds) > 0 {
+		q += " WHERE " + strings.Join(conds, " AND ")
+	}
+	q += " ORDER BY id DESC LIMIT ?"
+	rows, err := s.db.QueryContext(ctx, q, append(args, f.Limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		e := &model.AuditEntry{}
+		var before, after, diff sql.NullString
+		err := rows.Scan(&e.ID, &e.ActorID, &e.ActorRole, &e.Action, &e.EntityType, &e.EntityID,
+			&before, &after, &diff, &e.RequestID, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+		if diff.Valid {
+			if err := json.Unmarshal([]byte(diff.String), &e.Diff); err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// nullJSON guarda NULL en vez de un JSON vacío
+func nullJSON(b json.RawMessage) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
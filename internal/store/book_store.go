@@ -1,87 +1,418 @@
 package store
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"practica-go/internal/model"
+	"practica-go/internal/query"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 )
 
 // Esto permite desacoplar la lógica de acceso a datos del resto de la aplicación
 type BookStore interface {
-	GetAll() ([]*model.Book, error)
-	SearchByTitleOrAuthor(book string) ([]*model.Book, error)
-	GetByID(id int) (*model.Book, error)
-	Exists(id int) (bool, error)
-	Create(book *model.Book) (*model.Book, error)
-	Update(id int, book *model.Book) (*model.Book, error)
-	Delete(id int) error
+	GetAll(ctx context.Context) ([]*model.Book, error)
+	SearchByTitleOrAuthor(ctx context.Context, book string) ([]*model.Book, error)
+	Query(ctx context.Context, expr query.Expr) ([]*model.Book, error)
+	Each(ctx context.Context, expr query.Expr, fn func(*model.Book) error) error
+	Page(ctx context.Context, expr query.Expr, newestFirst bool, limit, offset int) ([]*model.Book, error)
+	Count(ctx context.Context, expr query.Expr) (int, error)
+	Authors(ctx context.Context) ([]Facet, error)
+	Tags(ctx context.Context) ([]Facet, error)
+	GetByID(ctx context.Context, id int) (*model.Book, error)
+	GetByIDs(ctx context.Context, ids []int) ([]*model.Book, error)
+	GetByAuthors(ctx context.Context, authors []string) ([]*model.Book, error)
+	GetByISBN(ctx context.Context, isbn string) (*model.Book, error)
+	Exists(ctx context.Context, id int) (bool, error)
+	ExistsByTitleAndAuthor(ctx context.Context, title, author string) (bool, error)
+	Create(ctx context.Context, book *model.Book) (*model.Book, error)
+	CreateBatch(ctx context.Context, books []*model.Book) error
+	Update(ctx context.Context, id int, book *model.Book, fields []string) (*model.Book, error)
+	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
 
+// Facet es un valor de agrupación (un autor, una etiqueta) y cuántos libros tiene
+type Facet struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// bookSQL deja afuera de las lecturas los libros borrados, salvo que
+// withDeleted sea true (ver Store.WithDeleted)
 type bookSQL struct {
-	db *sql.DB
+	db          dbtx
+	tx          txFunc
+	withDeleted bool
 }
 
-// GetAll obtiene todos los libros de la base de datos
-func (s *bookSQL) GetAll() ([]*model.Book, error) {
-	q := "SELECT id, title, author FROM books"
-	rows, err := s.db.Query(q)
+// bookColumns son las columnas que se leen en cada consulta de libros
+const bookColumns = "id, title, author, COALESCE(year, 0), COALESCE(tags, ''), isbn, publisher, contributors, prices, version, deleted_at"
+
+// bookInsert inserta un libro con los valores de bookValues
+const bookInsert = "INSERT INTO books (title, author, year, tags, isbn, publisher, contributors, prices) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+
+// rowScanner abstrae *sql.Row y *sql.Rows para reutilizar scanBook
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanBook lee una fila con las columnas de bookColumns
+func scanBook(row rowScanner) (*model.Book, error) {
+	b := &model.Book{}
+	var tags, contributors, prices string
+	var deletedAt sql.NullTime
+	if err := row.Scan(&b.ID, &b.Titulo, &b.Autor, &b.Anio, &tags, &b.ISBN, &b.Editorial, &contributors, &prices, &b.Version, &deletedAt); err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		b.DeletedAt = &deletedAt.Time
+	}
+	b.Etiquetas = splitTags(tags)
+	if err := decodeJSONColumn(contributors, &b.Colaboradores); err != nil {
+		return nil, err
+	}
+	if err := decodeJSONColumn(prices, &b.Precios); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// BookFields son las columnas editables de un libro, en el orden de
+// bookInsert. Se llaman igual que los campos JSON del libro.
+var BookFields = []string{"title", "author", "year", "tags", "isbn", "publisher", "contributors", "prices"}
+
+// bookValues son los valores de las columnas editables, en el orden de bookInsert
+func bookValues(b *model.Book) ([]any, error) {
+	contributors, err := encodeJSONColumn(b.Colaboradores)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := encodeJSONColumn(b.Precios)
 	if err != nil {
 		return nil, err
 	}
+	return []any{b.Titulo, b.Autor, b.Anio, joinTags(b.Etiquetas), b.ISBN, b.Editorial, contributors, prices}, nil
+}
+
+// scanBooks recorre todas las filas de una consulta de libros
+func scanBooks(rows *sql.Rows) ([]*model.Book, error) {
 	defer rows.Close()
 
 	var libros []*model.Book
 
 	for rows.Next() {
-		b := &model.Book{}
-		if err := rows.Scan(&b.ID, &b.Titulo, &b.Autor); err != nil {
+		b, err := scanBook(rows)
+		if err != nil {
 			return nil, err
 		}
 		libros = append(libros, b)
 	}
+	return libros, rows.Err()
+}
+
+// joinTags guarda las etiquetas separadas por comas
+func joinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// splitTags es la operación inversa de joinTags
+func splitTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, ",")
+}
 
-	return libros, nil
+// encodeJSONColumn guarda una lista como JSON; una lista vacía queda como texto vacío
+func encodeJSONColumn[T any](items []T) (string, error) {
+	if len(items) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(items)
+	return string(data), err
+}
+
+// decodeJSONColumn es la operación inversa de encodeJSONColumn
+func decodeJSONColumn[T any](data string, items *[]T) error {
+	if data == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(data), items)
+}
+
+// scope es la condición que deja afuera los libros borrados; con withDeleted
+// no filtra nada
+func (s *bookSQL) scope() string {
+	if s.withDeleted {
+		return "1"
+	}
+	return "deleted_at IS NULL"
+}
+
+// GetAll obtiene todos los libros de la base de datos
+func (s *bookSQL) GetAll(ctx context.Context) ([]*model.Book, error) {
+	defer observe(ctx, "BookStore", "GetAll", time.Now())
+
+	q := "SELECT " + bookColumns + " FROM books WHERE " + s.scope()
+	rows, err := s.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return scanBooks(rows)
 }
 
 // SearchByTitleOrAuthor busca libros cuyo título o autor contenga la palabra indicada
-func (s *bookSQL) SearchByTitleOrAuthor(book string) ([]*model.Book, error) {
-	q := "SELECT id, title, author FROM books WHERE title LIKE ? OR author LIKE ?"
+func (s *bookSQL) SearchByTitleOrAuthor(ctx context.Context, book string) ([]*model.Book, error) {
+	defer observe(ctx, "BookStore", "SearchByTitleOrAuthor", time.Now())
+
+	q := "SELECT " + bookColumns + " FROM books WHERE " + s.scope() + " AND (title LIKE ? OR author LIKE ?)"
 
 	// Usamos % para permitir coincidencias parciales (ej. "harry" → "Harry Potter")
-	rows, err := s.db.Query(q, "%"+book+"%", "%"+book+"%")
+	rows, err := s.db.QueryContext(ctx, q, "%"+book+"%", "%"+book+"%")
+	if err != nil {
+		return nil, err
+	}
+	return scanBooks(rows)
+}
+
+// Query busca libros con una consulta avanzada ya analizada.
+// La condición se traduce a SQL parametrizado, nunca se concatenan valores.
+func (s *bookSQL) Query(ctx context.Context, expr query.Expr) ([]*model.Book, error) {
+	defer observe(ctx, "BookStore", "Query", time.Now())
+
+	where, args, err := s.whereClause(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+bookColumns+" FROM books"+where, args...)
 	if err != nil {
 		return nil, err
 	}
+	return scanBooks(rows)
+}
+
+// Each recorre los libros que cumplen expr (todos si es nil) ordenados por ID
+// y llama a fn con cada uno a medida que se leen, sin armar la lista completa.
+// Si fn devuelve error el recorrido se corta y se devuelve ese error.
+func (s *bookSQL) Each(ctx context.Context, expr query.Expr, fn func(*model.Book) error) error {
+	defer observe(ctx, "BookStore", "Each", time.Now())
+
+	where, args, err := s.whereClause(expr)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+bookColumns+" FROM books"+where+" ORDER BY id", args...)
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
 
-	var libros []*model.Book
+	for rows.Next() {
+		b, err := scanBook(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// whereClause arma " WHERE ..." con scope y la condición de expr, si no es nil
+func (s *bookSQL) whereClause(expr query.Expr) (string, []any, error) {
+	if expr == nil {
+		return " WHERE " + s.scope(), nil, nil
+	}
+	where, args, err := query.ToSQL(expr)
+	if err != nil {
+		return "", nil, err
+	}
+	return " WHERE " + s.scope() + " AND (" + where + ")", args, nil
+}
+
+// Page devuelve una página de los libros que cumplen expr (todos si es nil).
+// Se ordenan por ID, o del más nuevo al más viejo con newestFirst.
+func (s *bookSQL) Page(ctx context.Context, expr query.Expr, newestFirst bool, limit, offset int) ([]*model.Book, error) {
+	defer observe(ctx, "BookStore", "Page", time.Now())
+
+	where, args, err := s.whereClause(expr)
+	if err != nil {
+		return nil, err
+	}
+	order := " ORDER BY id"
+	if newestFirst {
+		order = " ORDER BY id DESC"
+	}
+
+	q := "SELECT " + bookColumns + " FROM books" + where + order + " LIMIT ? OFFSET ?"
+	rows, err := s.db.QueryContext(ctx, q, append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	return scanBooks(rows)
+}
+
+// Count cuenta los libros que cumplen expr (todos si es nil)
+func (s *bookSQL) Count(ctx context.Context, expr query.Expr) (int, error) {
+	defer observe(ctx, "BookStore", "Count", time.Now())
+
+	where, args, err := s.whereClause(expr)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM books"+where, args...).Scan(&n)
+	return n, err
+}
+
+// Authors lista los autores del catálogo con su cantidad de libros
+func (s *bookSQL) Authors(ctx context.Context) ([]Facet, error) {
+	defer observe(ctx, "BookStore", "Authors", time.Now())
+
+	rows, err := s.db.QueryContext(ctx, "SELECT author, COUNT(*) FROM books WHERE "+s.scope()+" GROUP BY author ORDER BY author")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	var facets []Facet
 	for rows.Next() {
-		b := &model.Book{}
-		if err := rows.Scan(&b.ID, &b.Titulo, &b.Autor); err != nil {
+		var f Facet
+		if err := rows.Scan(&f.Name, &f.Count); err != nil {
 			return nil, err
 		}
-		libros = append(libros, b)
+		facets = append(facets, f)
 	}
-	return libros, nil
+	return facets, rows.Err()
 }
 
-// GetByID busca un libro por su ID
-func (s *bookSQL) GetByID(id int) (*model.Book, error) {
-	q := "SELECT id, title, author FROM books WHERE id = ?"
+// Tags lista las etiquetas del catálogo con su cantidad de libros.
+// Como se guardan separadas por comas en una sola columna, se cuentan acá.
+func (s *bookSQL) Tags(ctx context.Context) ([]Facet, error) {
+	defer observe(ctx, "BookStore", "Tags", time.Now())
 
-	b := &model.Book{}
-	err := s.db.QueryRow(q, id).Scan(&b.ID, &b.Titulo, &b.Autor)
+	rows, err := s.db.QueryContext(ctx, "SELECT tags FROM books WHERE "+s.scope()+" AND tags <> ''")
 	if err != nil {
 		return nil, err
 	}
-	return b, nil
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var tags string
+		if err := rows.Scan(&tags); err != nil {
+			return nil, err
+		}
+		for _, t := range splitTags(tags) {
+			counts[t]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	facets := make([]Facet, 0, len(counts))
+	for name, n := range counts {
+		facets = append(facets, Facet{Name: name, Count: n})
+	}
+	sort.Slice(facets, func(i, j int) bool { return facets[i].Name < facets[j].Name })
+	return facets, nil
+}
+
+// GetByID busca un libro por su ID; devuelve nil si no existe
+func (s *bookSQL) GetByID(ctx context.Context, id int) (*model.Book, error) {
+	defer observe(ctx, "BookStore", "GetByID", time.Now())
+
+	q := "SELECT " + bookColumns + " FROM books WHERE " + s.scope() + " AND id = ?"
+	b, err := scanBook(s.db.QueryRowContext(ctx, q, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return b, err
+}
+
+// GetByIDs busca varios libros en una sola consulta. Los IDs que no existen
+// no aparecen en el resultado.
+func (s *bookSQL) GetByIDs(ctx context.Context, ids []int) ([]*model.Book, error) {
+	defer observe(ctx, "BookStore", "GetByIDs", time.Now())
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	q := "SELECT " + bookColumns + " FROM books WHERE " + s.scope() + " AND id IN (" + placeholders(len(ids)) + ")"
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanBooks(rows)
+}
+
+// GetByAuthors devuelve los libros de varios autores en una sola consulta,
+// ordenados por ID. El autor se compara exacto.
+func (s *bookSQL) GetByAuthors(ctx context.Context, authors []string) ([]*model.Book, error) {
+	defer observe(ctx, "BookStore", "GetByAuthors", time.Now())
+
+	if len(authors) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(authors))
+	for i, a := range authors {
+		args[i] = a
+	}
+	q := "SELECT " + bookColumns + " FROM books WHERE " + s.scope() + " AND author IN (" + placeholders(len(authors)) + ") ORDER BY id"
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanBooks(rows)
+}
+
+// setColumns arma "a = ?, b = ?" y sus valores para las columnas fields, que
+// tienen que estar en columns; values son los valores de todas las columns,
+// en el mismo orden
+func setColumns(columns []string, values []any, fields []string) (string, []any, error) {
+	if len(fields) == 0 {
+		return "", nil, errors.New("no hay campos para actualizar")
+	}
+	set := make([]string, len(fields))
+	args := make([]any, len(fields))
+	for i, f := range fields {
+		j := slices.Index(columns, f)
+		if j < 0 {
+			return "", nil, fmt.Errorf("el campo %s no se puede actualizar", f)
+		}
+		set[i] = f + " = ?"
+		args[i] = values[j]
+	}
+	return strings.Join(set, ", "), args, nil
+}
+
+// placeholders arma "?, ?, ?" para una lista IN de n valores
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
 }
 
 // Exists verifica si un libro con el ID dado existe en la base de datos
 // Usamos SELECT 1 por eficiencia (no se cargan todos los campos)
-func (s *bookSQL) Exists(id int) (bool, error) {
-	q := "SELECT 1 FROM books WHERE id = ?"
-	row := s.db.QueryRow(q, id)
+func (s *bookSQL) Exists(ctx context.Context, id int) (bool, error) {
+	defer observe(ctx, "BookStore", "Exists", time.Now())
+
+	q := "SELECT 1 FROM books WHERE " + s.scope() + " AND id = ?"
+	row := s.db.QueryRowContext(ctx, q, id)
 
 	var exists int
 	err := row.Scan(&exists)
@@ -95,45 +426,190 @@ func (s *bookSQL) Exists(id int) (bool, error) {
 	return true, nil // Existe
 }
 
-// Create inserta un nuevo libro en la base de datos
-func (s *bookSQL) Create(libro *model.Book) (*model.Book, error) {
-	q := "INSERT INTO books (title, author) VALUES (?, ?)"
-	resp, err := s.db.Exec(q, libro.Titulo, libro.Autor)
+// ExistsByTitleAndAuthor indica si ya hay un libro con el mismo título y autor
+// (sin distinguir mayúsculas). Se usa para detectar duplicados al importar.
+func (s *bookSQL) ExistsByTitleAndAuthor(ctx context.Context, title, author string) (bool, error) {
+	defer observe(ctx, "BookStore", "ExistsByTitleAndAuthor", time.Now())
+
+	q := "SELECT 1 FROM books WHERE " + s.scope() + " AND lower(title) = lower(?) AND lower(author) = lower(?) LIMIT 1"
+	var exists int
+	err := s.db.QueryRowContext(ctx, q, title, author).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
 	if err != nil {
-		return nil, err
+		return false, err
+	}
+	return true, nil
+}
+
+// GetByISBN busca un libro por ISBN; devuelve nil si no existe
+func (s *bookSQL) GetByISBN(ctx context.Context, isbn string) (*model.Book, error) {
+	defer observe(ctx, "BookStore", "GetByISBN", time.Now())
+
+	q := "SELECT " + bookColumns + " FROM books WHERE " + s.scope() + " AND isbn = ?"
+	b, err := scanBook(s.db.QueryRowContext(ctx, q, isbn))
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
+	return b, err
+}
+
+// Create inserta un nuevo libro en la base de datos, junto con su evento
+// book.created en el outbox
+func (s *bookSQL) Create(ctx context.Context, libro *model.Book) (*model.Book, error) {
+	defer observe(ctx, "BookStore", "Create", time.Now())
 
-	// Obtenemos el ID generado automáticamente por la base de datos
-	id, err := resp.LastInsertId()
+	values, err := bookValues(libro)
 	if err != nil {
 		return nil, err
 	}
-	libro.ID = int(id)
 
+	err = s.tx(ctx, func(db dbtx) error {
+		resp, err := db.ExecContext(ctx, bookInsert, values...)
+		if err != nil {
+			return err
+		}
+
+		// Obtenemos el ID generado automáticamente por la base de datos
+		id, err := resp.LastInsertId()
+		if err != nil {
+			return err
+		}
+		libro.ID, libro.Version = int(id), 1
+		return addEvent(ctx, db, model.EventBookCreated, libro)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return libro, nil
 }
 
-// Update actualiza los datos de un libro existente
-func (s *bookSQL) Update(id int, libro *model.Book) (*model.Book, error) {
-	q := "UPDATE books SET title = ?, author = ? WHERE id = ?"
+// CreateBatch inserta varios libros en una sola transacción: se crean todos
+// o ninguno, cada uno con su evento book.created. Asigna el ID generado a
+// cada libro.
+func (s *bookSQL) CreateBatch(ctx context.Context, libros []*model.Book) error {
+	defer observe(ctx, "BookStore", "CreateBatch", time.Now())
 
-	_, err := s.db.Exec(q, libro.Titulo, libro.Autor, id)
+	return s.tx(ctx, func(db dbtx) error {
+		for _, libro := range libros {
+			values, err := bookValues(libro)
+			if err != nil {
+				return err
+			}
+			resp, err := db.ExecContext(ctx, bookInsert, values...)
+			if err != nil {
+				return err
+			}
+			id, err := resp.LastInsertId()
+			if err != nil {
+				return err
+			}
+			libro.ID, libro.Version = int(id), 1
+			if err := addEvent(ctx, db, model.EventBookCreated, libro); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Update actualiza las columnas fields (de BookFields) de un libro existente,
+// junto con su evento book.updated en el outbox. Solo se aplica si el libro
+// sigue en la versión libro.Version; si no devuelve ErrVersionConflict. Suma
+// uno a la versión.
+func (s *bookSQL) Update(ctx context.Context, id int, libro *model.Book, fields []string) (*model.Book, error) {
+	defer observe(ctx, "BookStore", "Update", time.Now())
+
+	values, err := bookValues(libro)
 	if err != nil {
 		return nil, err
 	}
+	set, args, err := setColumns(BookFields, values, fields)
+	if err != nil {
+		return nil, err
+	}
+	q := "UPDATE books SET " + set + ", version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
 
-	libro.ID = id
+	err = s.tx(ctx, func(db dbtx) error {
+		res, err := db.ExecContext(ctx, q, append(args, id, libro.Version)...)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrVersionConflict
+		}
+		libro.ID = id
+		libro.Version++
+		return addEvent(ctx, db, model.EventBookUpdated, libro)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return libro, nil
 }
 
-// Delete elimina un libro de la base de datos por su ID
-func (s *bookSQL) Delete(id int) error {
-	q := "DELETE FROM books WHERE id = ?"
+// Delete da de baja un libro por su ID, junto con su evento book.deleted en
+// el outbox. La fila queda con deleted_at hasta que Purge la borra. Si no hay
+// un libro activo con ese ID devuelve ErrNotFound.
+func (s *bookSQL) Delete(ctx context.Context, id int) error {
+	defer observe(ctx, "BookStore", "Delete", time.Now())
+
+	q := "UPDATE books SET deleted_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL"
 
-	_, err := s.db.Exec(q, id)
+	return s.tx(ctx, func(db dbtx) error {
+		res, err := db.ExecContext(ctx, q, time.Now().UTC(), id)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		return addEvent(ctx, db, model.EventBookDeleted, map[string]int{"id": id})
+	})
+}
+
+// Restore vuelve a dar de alta un libro borrado, junto con su evento
+// book.restored en el outbox. Si no hay un libro borrado con ese ID devuelve
+// ErrNotFound.
+func (s *bookSQL) Restore(ctx context.Context, id int) error {
+	defer observe(ctx, "BookStore", "Restore", time.Now())
+
+	q := "UPDATE books SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL"
+
+	return s.tx(ctx, func(db dbtx) error {
+		res, err := db.ExecContext(ctx, q, id)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		return addEvent(ctx, db, model.EventBookRestored, map[string]int{"id": id})
+	})
+}
+
+// Purge borra definitivamente los libros dados de baja antes de before y
+// devuelve cuántos
+func (s *bookSQL) Purge(ctx context.Context, before time.Time) (int, error) {
+	defer observe(ctx, "BookStore", "Purge", time.Now())
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM books WHERE deleted_at IS NOT NULL AND deleted_at < ?", before.UTC())
 	if err != nil {
-		return err
+		return 0, err
 	}
-
-	return nil
+	n, err := res.RowsAffected()
+	return int(n), err
 }
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"practica-go/internal/tracing"
+	"strings"
+)
+
+// dbtx son las operaciones que usan los repositorios. Lo cumplen *sql.DB,
+// *sql.Tx y tracedDB, así los repositorios no dependen de cuál reciben.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// tracedDB crea un span de cliente por cada consulta SQL
+type tracedDB struct {
+	db dbtx
+}
+
+func (t *tracedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	res, err := t.db.ExecContext(ctx, query, args...)
+	span.RecordError(err)
+	return res, err
+}
+
+func (t *tracedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	span.RecordError(err)
+	return rows, err
+}
+
+func (t *tracedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	row := t.db.QueryRowContext(ctx, query, args...)
+	if err := row.Err(); err != sql.ErrNoRows {
+		span.RecordError(err)
+	}
+	return row
+}
+
+// startQuerySpan registra la sentencia SQL (sin argumentos, que pueden tener datos sensibles)
+func startQuerySpan(ctx context.Context, query string) (context.Context, *tracing.Span) {
+	name := "sql"
+	if fields := strings.Fields(query); len(fields) > 0 {
+		name = "sql " + strings.ToUpper(fields[0])
+	}
+	return tracing.StartKind(ctx, name, tracing.KindClient,
+		tracing.String("db.system", "sqlite"),
+		tracing.String("db.statement", query),
+	)
+}
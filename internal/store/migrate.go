@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Las migraciones son archivos NNN_descripcion.sql que se aplican en orden.
+// Cada una corre en su propia transacción y queda registrada en schema_migrations.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration es una migración embebida en el binario
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrations devuelve las migraciones embebidas ordenadas por versión
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Migration
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("nombre de migración inválido: %s", e.Name())
+		}
+		content, err := migrationFiles.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, Migration{Version: version, Name: e.Name(), SQL: string(content)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// ensureMigrationsTable crea la tabla de control si no existe
+func (s *Store) ensureMigrationsTable(ctx context.Context) error {
+	q := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`
+	_, err := s.db.ExecContext(ctx, q)
+	return err
+}
+
+// appliedVersions devuelve las versiones ya aplicadas
+func (s *Store) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		applied[v] = true
+	}
+	return applied, rows.Err()
+}
+
+// Migrate aplica las migraciones pendientes y devuelve cuántas aplicó
+func (s *Store) Migrate(ctx context.Context) (int, error) {
+	if err := s.ensureMigrationsTable(ctx); err != nil {
+		return 0, err
+	}
+	applied, err := s.appliedVersions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	list, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range list {
+		if applied[m.Version] {
+			continue
+		}
+		if err := s.applyMigration(ctx, m); err != nil {
+			return count, fmt.Errorf("migración %s: %w", m.Name, err)
+		}
+		slog.InfoContext(ctx, "migración aplicada", slog.String("migration", m.Name))
+		count++
+	}
+	return count, nil
+}
+
+func (s *Store) applyMigration(ctx context.Context, m Migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", m.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PendingMigrations devuelve los nombres de las migraciones sin aplicar
+func (s *Store) PendingMigrations(ctx context.Context) ([]string, error) {
+	if err := s.ensureMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, m := range list {
+		if !applied[m.Version] {
+			pending = append(pending, m.Name)
+		}
+	}
+	return pending, nil
+}
+
+// Ping verifica que la base de datos responde
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// CheckMigrations devuelve un error si hay migraciones pendientes
+func (s *Store) CheckMigrations(ctx context.Context) error {
+	pending, err := s.PendingMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("migraciones pendientes: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS books (
+    id     INTEGER PRIMARY KEY AUTOINCREMENT,
+    title  TEXT    NOT NULL,
+    author TEXT    NOT NULL,
+    year   INTEGER NOT NULL DEFAULT 0,
+    tags   TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_books_title ON books (title);
+CREATE INDEX IF NOT EXISTS idx_books_author ON books (author);
+
+CREATE TABLE IF NOT EXISTS users (
+    id       INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL UNIQUE,
+    email    TEXT NOT NULL UNIQUE,
+    password TEXT NOT NULL,
+    role     TEXT NOT NULL DEFAULT 'user'
+);
@@ -0,0 +1,8 @@
+-- Metadatos bibliográficos que llegan en los feeds ONIX de las editoriales.
+-- contributors y prices se guardan como JSON.
+ALTER TABLE books ADD COLUMN isbn TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN publisher TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN contributors TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN prices TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_books_isbn ON books (isbn) WHERE isbn <> '';
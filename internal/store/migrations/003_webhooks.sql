@@ -0,0 +1,28 @@
+-- Suscripciones de sistemas externos a eventos del catálogo y cola
+-- persistente de entregas. events es la lista de eventos separada por comas.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    url        TEXT      NOT NULL,
+    events     TEXT      NOT NULL,
+    secret     TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- Cada entrega guarda el resultado del último intento; status es pending,
+-- delivered o failed (se agotaron los reintentos).
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id       INTEGER   NOT NULL REFERENCES webhooks (id),
+    event            TEXT      NOT NULL,
+    payload          TEXT      NOT NULL,
+    status           TEXT      NOT NULL DEFAULT 'pending',
+    attempts         INTEGER   NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMP NOT NULL,
+    last_status_code INTEGER   NOT NULL DEFAULT 0,
+    last_error       TEXT      NOT NULL DEFAULT '',
+    created_at       TIMESTAMP NOT NULL,
+    delivered_at     TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id);
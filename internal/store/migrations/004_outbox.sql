@@ -0,0 +1,14 @@
+-- Outbox transaccional: cada escritura de libros o usuarios inserta su evento
+-- en la misma transacción y el relay (internal/outbox) lo reparte después.
+-- delivered_at queda NULL hasta que todos los handlers lo procesaron.
+CREATE TABLE IF NOT EXISTS outbox (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    event        TEXT      NOT NULL,
+    payload      TEXT      NOT NULL,
+    created_at   TIMESTAMP NOT NULL,
+    attempts     INTEGER   NOT NULL DEFAULT 0,
+    last_error   TEXT      NOT NULL DEFAULT '',
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (id) WHERE delivered_at IS NULL;
@@ -0,0 +1,29 @@
+-- Registro de auditoría de los cambios hechos desde los services. Es de solo
+-- agregado: los triggers rechazan cualquier UPDATE o DELETE.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id    INTEGER   NOT NULL DEFAULT 0,
+    actor_role  TEXT      NOT NULL DEFAULT '',
+    action      TEXT      NOT NULL,
+    entity_type TEXT      NOT NULL,
+    entity_id   INTEGER   NOT NULL,
+    before      TEXT,
+    after       TEXT,
+    diff        TEXT,
+    request_id  TEXT      NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity_type, entity_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log (created_at);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log es de solo agregado');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log es de solo agregado');
+END;
@@ -0,0 +1,12 @@
+-- Borrado lógico: las bajas guardan la fecha en deleted_at y las filas se
+-- borran de verdad después del período de retención. Los ISBN de libros
+-- borrados quedan libres; los username y email de usuarios borrados siguen
+-- reservados (la restricción UNIQUE es de la tabla) hasta la purga.
+ALTER TABLE books ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_books_deleted_at ON books (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_books_isbn;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_books_isbn ON books (isbn) WHERE isbn <> '' AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Control de concurrencia optimista: cada escritura suma uno a version y los
+-- UPDATE solo se aplican si la versión sigue siendo la que leyó el cliente.
+ALTER TABLE books ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+	"practica-go/internal/metrics"
+	"time"
+)
+
+// observe registra la latencia de un método del repositorio en las métricas
+// y en el log de depuración. Se usa con defer al inicio de cada método:
+//
+//	defer observe(ctx, "BookStore", "GetAll", time.Now())
+func observe(ctx context.Context, store, method string, start time.Time) {
+	metrics.ObserveStore(store, method, start)
+	slog.DebugContext(ctx, "consulta a la base",
+		slog.String("store", store),
+		slog.String("method", method),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
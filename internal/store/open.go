@@ -0,0 +1,42 @@
+package store
+
+import (
+	"database/sql"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// pragmas se aplican en cada conexión del pool. Con WAL las lecturas largas
+// (Each, la exportación) no bloquean a las escrituras, y busy_timeout hace
+// que SQLite espere el lock antes de devolver SQLITE_BUSY a retryBusy.
+var pragmas = []string{"journal_mode(WAL)", "busy_timeout(5000)"}
+
+// Open abre la base de datos indicada por la URL de configuración.
+// Acepta "sqlite3://./data.db", "sqlite://./data.db" o directamente la ruta.
+func Open(url string) (*sql.DB, error) {
+	return sql.Open("sqlite", withPragmas(sqlitePath(url)))
+}
+
+// withPragmas agrega pragmas al DSN, respetando los parámetros que ya tenga
+func withPragmas(dsn string) string {
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	for _, p := range pragmas {
+		dsn += sep + "_pragma=" + p
+		sep = "&"
+	}
+	return dsn
+}
+
+// sqlitePath convierte "sqlite3://./data.db" en la ruta que espera el driver
+func sqlitePath(url string) string {
+	for _, prefix := range []string{"sqlite3://", "sqlite://"} {
+		if strings.HasPrefix(url, prefix) {
+			return strings.TrimPrefix(url, prefix)
+		}
+	}
+	return url
+}
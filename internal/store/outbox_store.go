@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"practica-go/internal/model"
+	"time"
+)
+
+// OutboxStore es el lado de lectura del outbox, para el relay. Los eventos se
+// escriben desde los otros repositorios con addEvent, dentro de la misma
+// transacción que el cambio.
+type OutboxStore interface {
+	Pending(ctx context.Context, limit int) ([]*model.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, id int, at time.Time) error
+	RecordFailure(ctx context.Context, id int, reason string) error
+	Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+type outboxSQL struct {
+	db dbtx
+}
+
+// addEvent inserta un evento en el outbox usando db, que tiene que ser la
+// transacción de la escritura que lo genera: si esta se revierte, el evento
+// también
+func addEvent(ctx context.Context, db dbtx, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	q := "INSERT INTO outbox (event, payload, created_at) VALUES (?, ?, ?)"
+	_, err = db.ExecContext(ctx, q, event, string(payload), time.Now().UTC())
+	return err
+}
+
+// Pending devuelve hasta limit eventos sin entregar, en el orden en que se
+// escribieron
+func (s *outboxSQL) Pending(ctx context.Context, limit int) ([]*model.OutboxEvent, error) {
+	defer observe(ctx, "OutboxStore", "Pending", time.Now())
+
+	q := "SELECT id, event, payload, created_at, attempts FROM outbox WHERE delivered_at IS NULL ORDER BY id LIMIT ?"
+	rows, err := s.db.QueryContext(ctx, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []*model.OutboxEvent
+	for rows.Next() {
+		e := &model.OutboxEvent{}
+		var payload string
+		if err := rows.Scan(&e.ID, &e.Event, &payload, &e.CreatedAt, &e.Attempts); err != nil {
+			return nil, err
+		}
+		e.Payload = []byte(payload)
+		pending = append(pending, e)
+	}
+	return pending, rows.Err()
+}
+
+// MarkDelivered marca el evento como entregado a todos los handlers
+func (s *outboxSQL) MarkDelivered(ctx context.Context, id int, at time.Time) error {
+	defer observe(ctx, "OutboxStore", "MarkDelivered", time.Now())
+
+	_, err := s.db.ExecContext(ctx, "UPDATE outbox SET delivered_at = ?, attempts = attempts + 1 WHERE id = ?", at.UTC(), id)
+	return err
+}
+
+// RecordFailure suma un intento fallido; el evento sigue pendiente
+func (s *outboxSQL) RecordFailure(ctx context.Context, id int, reason string) error {
+	defer observe(ctx, "OutboxStore", "RecordFailure", time.Now())
+
+	_, err := s.db.ExecContext(ctx, "UPDATE outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?", reason, id)
+	return err
+}
+
+// Purge borra los eventos entregados antes de before y devuelve cuántos
+func (s *outboxSQL) Purge(ctx context.Context, before time.Time) (int, error) {
+	defer observe(ctx, "OutboxStore", "Purge", time.Now())
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM outbox WHERE delivered_at IS NOT NULL AND delivered_at < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"practica-go/internal/model"
+	"testing"
+)
+
+// Dar de baja o restaurar un registro que no está en el estado esperado
+// devuelve ErrNotFound y no deja evento en el outbox
+func TestDeleteRestoreNotFound(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+
+	book, err := st.BookStorage.Create(ctx, &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := st.UserStorage.CreateUser(ctx, &model.User{Username: "lector", Email: "lector@example.com", Password: "hash", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := []struct {
+		name    string
+		id      int
+		delete  func(ctx context.Context, id int) error
+		restore func(ctx context.Context, id int) error
+	}{
+		{"libros", book.ID, st.BookStorage.Delete, st.BookStorage.Restore},
+		{"usuarios", user.ID, st.UserStorage.Delete, st.UserStorage.Restore},
+	}
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			steps := []struct {
+				name    string
+				op      func(ctx context.Context, id int) error
+				id      int
+				wantErr error
+			}{
+				{"restaurar uno activo", s.restore, s.id, ErrNotFound},
+				{"dar de baja uno inexistente", s.delete, s.id + 1000, ErrNotFound},
+				{"restaurar uno inexistente", s.restore, s.id + 1000, ErrNotFound},
+				{"dar de baja", s.delete, s.id, nil},
+				{"dar de baja de nuevo", s.delete, s.id, ErrNotFound},
+				{"restaurar", s.restore, s.id, nil},
+				{"restaurar de nuevo", s.restore, s.id, ErrNotFound},
+			}
+			for _, step := range steps {
+				before := pendingEvents(t, st)
+				err := step.op(ctx, step.id)
+				if !errors.Is(err, step.wantErr) {
+					t.Fatalf("%s: err = %v, se esperaba %v", step.name, err, step.wantErr)
+				}
+				want := 0
+				if step.wantErr == nil {
+					want = 1
+				}
+				if added := pendingEvents(t, st) - before; added != want {
+					t.Errorf("%s: %d eventos nuevos en el outbox, se esperaba %d", step.name, added, want)
+				}
+			}
+		})
+	}
+}
+
+func pendingEvents(t *testing.T, st *Store) int {
+	t.Helper()
+	pending, err := st.OutboxStorage.Pending(context.Background(), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(pending)
+}
@@ -1,19 +1,72 @@
 package store
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
 
-// Store centraliza el acceso a los distintos repositorios
+// ErrVersionConflict indica que el registro cambió (o se dio de baja) desde
+// que el cliente lo leyó: la versión que envió ya no es la actual
+var ErrVersionConflict = errors.New("el registro fue modificado por otra petición")
+
+// ErrNotFound indica que no hay un registro con ese ID en el estado que la
+// operación espera: dar de baja uno que no existe o ya está dado de baja, o
+// restaurar uno que no está borrado
+var ErrNotFound = errors.New("no existe un registro con ese id en ese estado")
+
+// AnyVersion es la versión con la que una escritura saltea el control de
+// concurrencia y se aplica sobre la versión actual, sea cual sea. Es explícita
+// a propósito: los services rechazan la versión 0 para que omitirla no
+// saltee el control sin querer.
+const AnyVersion = -1
+
+// Store centraliza el acceso a los distintos repositorios. Dentro de WithTx
+// tx es la transacción en curso y los repositorios la usan.
 type Store struct {
-	db          *sql.DB
-	BookStorage BookStore
-	UserStorage UserStore
+	db             *sql.DB
+	tx             *sql.Tx
+	BookStorage    BookStore
+	UserStorage    UserStore
+	WebhookStorage WebhookStore
+	OutboxStorage  OutboxStore
+	AuditStorage   AuditStore
 }
 
 // New crea una instancia de Store con todas las dependencias inicializadas
 func New(db *sql.DB) *Store {
+	traced := &tracedDB{db: db}
+	begin := beginTx(db)
 	return &Store{
-		db:          db,
-		BookStorage: &bookSQL{db: db},
-		UserStorage: &userSQL{db: db},
+		db:             db,
+		BookStorage:    &bookSQL{db: traced, tx: begin},
+		UserStorage:    &userSQL{db: traced, tx: begin},
+		WebhookStorage: &webhookSQL{db: traced, tx: begin},
+		OutboxStorage:  &outboxSQL{db: traced},
+		AuditStorage:   &auditSQL{db: traced},
 	}
 }
+
+// WithDeleted devuelve un Store como s (en la misma transacción, si la hay)
+// cuyas lecturas de libros y usuarios incluyen los borrados
+func (s *Store) WithDeleted() *Store {
+	c := *s
+	if b, ok := s.BookStorage.(*bookSQL); ok {
+		scoped := *b
+		scoped.withDeleted = true
+		c.BookStorage = &scoped
+	}
+	if u, ok := s.UserStorage.(*userSQL); ok {
+		scoped := *u
+		scoped.withDeleted = true
+		c.UserStorage = &scoped
+	}
+	return &c
+}
+
+// Backup copia la base completa a path usando VACUUM INTO de SQLite.
+// Es seguro hacerlo con el servidor en marcha: la copia es consistente.
+func (s *Store) Backup(ctx context.Context, path string) error {
+	_, err := s.db.ExecContext(ctx, "VACUUM INTO ?", path)
+	return err
+}
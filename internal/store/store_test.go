@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// newTestStore abre una base SQLite migrada en un directorio temporal
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	return openTestStore(t, filepath.Join(t.TempDir(), "test.db"))
+}
+
+// openTestStore abre y migra la base de path. Usa sql.Open directo, sin los
+// pragmas de Open, así un lock de otra conexión devuelve SQLITE_BUSY en el
+// momento.
+func openTestStore(t *testing.T, path string) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st := New(db)
+	if _, err := st.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	// maxTxRetries es cuántas veces se reintenta una transacción que chocó
+	// con otra escritura (SQLITE_BUSY)
+	maxTxRetries = 8
+	// txRetryBase es la espera antes del primer reintento; se duplica en cada uno
+	txRetryBase = 10 * time.Millisecond
+)
+
+// txFunc corre fn dentro de una transacción. Fuera de WithTx abre una propia
+// (y la reintenta si la base está ocupada); dentro de WithTx usa la de
+// WithTx, así las escrituras de los repositorios se suman a la unidad de
+// trabajo en vez de confirmarse por separado.
+type txFunc func(ctx context.Context, fn func(db dbtx) error) error
+
+// beginTx devuelve el txFunc de los repositorios que no están en WithTx
+func beginTx(db *sql.DB) txFunc {
+	return func(ctx context.Context, fn func(db dbtx) error) error {
+		return retryBusy(ctx, func() error {
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+
+			if err := fn(&tracedDB{db: tx}); err != nil {
+				return err
+			}
+			return tx.Commit()
+		})
+	}
+}
+
+// joinTx devuelve el txFunc de los repositorios de WithTx: usa db sin abrir
+// otra transacción
+func joinTx(db dbtx) txFunc {
+	return func(ctx context.Context, fn func(db dbtx) error) error {
+		return fn(db)
+	}
+}
+
+// WithTx corre fn en una transacción con repositorios que la usan. Si fn
+// devuelve un error o entra en pánico se revierte todo; si no, se confirma.
+// Si la transacción choca con otra escritura (SQLITE_BUSY) se repite fn
+// entera, así que fn no debe tener efectos fuera de la base. Dentro de un
+// WithTx, otro WithTx se suma a la transacción en curso.
+//
+//	err := st.WithTx(ctx, func(tx *store.Store) error {
+//		ok, err := tx.BookStorage.Exists(ctx, id)
+//		...
+//		return tx.BookStorage.Delete(ctx, id)
+//	})
+func (s *Store) WithTx(ctx context.Context, fn func(tx *Store) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+	return retryBusy(ctx, func() error {
+		return s.runTx(ctx, fn)
+	})
+}
+
+func (s *Store) runTx(ctx context.Context, fn func(tx *Store) error) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := fn(s.withTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withTx arma un Store cuyos repositorios usan tx
+func (s *Store) withTx(tx *sql.Tx) *Store {
+	traced := &tracedDB{db: tx}
+	join := joinTx(traced)
+	return &Store{
+		db:             s.db,
+		tx:             tx,
+		BookStorage:    &bookSQL{db: traced, tx: join},
+		UserStorage:    &userSQL{db: traced, tx: join},
+		WebhookStorage: &webhookSQL{db: traced, tx: join},
+		OutboxStorage:  &outboxSQL{db: traced},
+		AuditStorage:   &auditSQL{db: traced},
+	}
+}
+
+// retryBusy repite fn mientras falle porque la base está ocupada por otra
+// escritura, con backoff exponencial y jitter
+func retryBusy(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !isBusy(err) || attempt >= maxTxRetries {
+			return err
+		}
+		d := txRetryBase << attempt
+		d = d/2 + rand.N(d/2+1)
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// isBusy indica si err es SQLITE_BUSY o SQLITE_LOCKED (con sus códigos
+// extendidos)
+func isBusy(err error) bool {
+	var e *sqlite.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	code := e.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
@@ -0,0 +1,226 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"practica-go/internal/model"
+	"testing"
+	"time"
+)
+
+// countBooks devuelve cuántos libros y cuántos eventos pendientes hay
+func countBooks(t *testing.T, st *Store) (books, events int) {
+	t.Helper()
+	all, err := st.BookStorage.GetAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := st.OutboxStorage.Pending(context.Background(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(all), len(pending)
+}
+
+func TestWithTxCommit(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+
+	err := st.WithTx(ctx, func(tx *Store) error {
+		if _, err := tx.BookStorage.Create(ctx, &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges"}); err != nil {
+			return err
+		}
+		// Dentro de la transacción se ve lo que ya se escribió en ella
+		books, err := tx.BookStorage.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		if len(books) != 1 {
+			t.Errorf("dentro de la transacción hay %d libros, se esperaba 1", len(books))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if books, events := countBooks(t, st); books != 1 || events != 1 {
+		t.Fatalf("%d libros y %d eventos, se esperaba 1 y 1", books, events)
+	}
+}
+
+// Si fn falla no queda nada de lo que escribió (ni sus eventos) y el error
+// vuelve sin cambios y sin reintentos
+func TestWithTxRollback(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	errFail := errors.New("falla a propósito")
+
+	calls := 0
+	err := st.WithTx(ctx, func(tx *Store) error {
+		calls++
+		if _, err := tx.BookStorage.Create(ctx, &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges"}); err != nil {
+			return err
+		}
+		return errFail
+	})
+	if !errors.Is(err, errFail) {
+		t.Fatalf("err = %v, se esperaba %v", err, errFail)
+	}
+	if calls != 1 {
+		t.Errorf("fn corrió %d veces, se esperaba 1", calls)
+	}
+	if books, events := countBooks(t, st); books != 0 || events != 0 {
+		t.Fatalf("quedaron %d libros y %d eventos después del rollback", books, events)
+	}
+}
+
+func TestWithTxRollbackOnPanic(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+
+	func() {
+		defer func() {
+			if p := recover(); p != "pánico a propósito" {
+				t.Fatalf("recover() = %v, se esperaba el pánico original", p)
+			}
+		}()
+		st.WithTx(ctx, func(tx *Store) error {
+			if _, err := tx.BookStorage.Create(ctx, &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges"}); err != nil {
+				return err
+			}
+			panic("pánico a propósito")
+		})
+	}()
+	if books, events := countBooks(t, st); books != 0 || events != 0 {
+		t.Fatalf("quedaron %d libros y %d eventos después del pánico", books, events)
+	}
+}
+
+// Un WithTx anidado se suma a la transacción de afuera: si la de afuera
+// falla, lo que escribió el de adentro también se revierte
+func TestWithTxNested(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	errFail := errors.New("falla a propósito")
+
+	err := st.WithTx(ctx, func(tx *Store) error {
+		err := tx.WithTx(ctx, func(inner *Store) error {
+			_, err := inner.BookStorage.Create(ctx, &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges"})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return errFail
+	})
+	if !errors.Is(err, errFail) {
+		t.Fatalf("err = %v, se esperaba %v", err, errFail)
+	}
+	if books, _ := countBooks(t, st); books != 0 {
+		t.Fatalf("quedaron %d libros: el WithTx anidado confirmó por su cuenta", books)
+	}
+}
+
+// lockDB toma el lock de escritura de la base desde otra conexión, como otro
+// proceso a mitad de una escritura. release lo suelta.
+func lockDB(t *testing.T, path string) (release func()) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		conn.ExecContext(ctx, "ROLLBACK")
+		conn.Close()
+	}
+}
+
+// Mientras otra conexión tiene el lock, la escritura falla con SQLITE_BUSY y
+// WithTx la repite entera hasta que se libera
+func TestWithTxRetriesBusy(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	st := openTestStore(t, path)
+
+	release := lockDB(t, path)
+	time.AfterFunc(50*time.Millisecond, release)
+
+	calls := 0
+	var firstErr error
+	err := st.WithTx(ctx, func(tx *Store) error {
+		calls++
+		_, err := tx.BookStorage.Create(ctx, &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges"})
+		if calls == 1 {
+			firstErr = err
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isBusy(firstErr) {
+		t.Fatalf("el primer intento devolvió %v, se esperaba SQLITE_BUSY", firstErr)
+	}
+	if calls < 2 {
+		t.Fatalf("fn corrió %d veces, se esperaba que se reintentara", calls)
+	}
+	if books, events := countBooks(t, st); books != 1 || events != 1 {
+		t.Fatalf("%d libros y %d eventos, se esperaba 1 y 1: los intentos fallidos dejaron restos", books, events)
+	}
+}
+
+// Si el lock no se libera, WithTx se rinde después de maxTxRetries
+// reintentos y devuelve el SQLITE_BUSY
+func TestWithTxBusyGivesUp(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	st := openTestStore(t, path)
+	release := lockDB(t, path)
+	defer release()
+
+	calls := 0
+	err := st.WithTx(ctx, func(tx *Store) error {
+		calls++
+		_, err := tx.BookStorage.Create(ctx, &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges"})
+		return err
+	})
+	if !isBusy(err) {
+		t.Fatalf("err = %v, se esperaba SQLITE_BUSY", err)
+	}
+	if calls != maxTxRetries+1 {
+		t.Fatalf("fn corrió %d veces, se esperaban %d", calls, maxTxRetries+1)
+	}
+}
+
+// Cancelar el context corta la espera entre reintentos
+func TestWithTxBusyCanceled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	st := openTestStore(t, path)
+	release := lockDB(t, path)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := st.WithTx(ctx, func(tx *Store) error {
+		_, err := tx.BookStorage.Create(ctx, &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges"})
+		return err
+	})
+	if err == nil {
+		t.Fatal("la escritura no debería haber pasado con la base bloqueada")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("WithTx tardó %s en rendirse con el context cancelado", elapsed)
+	}
+}
@@ -1,27 +1,65 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"practica-go/internal/model"
+	"time"
 )
 
 type UserStore interface {
-	GetAllUser() ([]*model.User, error)
-	SearchByUserOrEmail(user string) ([]*model.User, error)
-	GetByEmailOrUser(user string) (*model.User, error)
-	Exists(id int) (bool, error)
-	CreateUser(user *model.User) (*model.User, error)
-	Update(id int, user *model.User) (*model.User, error)
-	Delete(id int) error
+	GetAllUser(ctx context.Context) ([]*model.User, error)
+	SearchByUserOrEmail(ctx context.Context, user string) ([]*model.User, error)
+	GetByEmailOrUser(ctx context.Context, user string) (*model.User, error)
+	GetByID(ctx context.Context, id int) (*model.User, error)
+	Exists(ctx context.Context, id int) (bool, error)
+	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
+	Update(ctx context.Context, id int, user *model.User, fields []string) (*model.User, error)
+	UpdatePassword(ctx context.Context, id int, hash string) error
+	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
 
+// userSQL deja afuera de las lecturas los usuarios borrados, salvo que
+// withDeleted sea true (ver Store.WithDeleted)
 type userSQL struct {
-	db *sql.DB
+	db          dbtx
+	tx          txFunc
+	withDeleted bool
 }
 
-func (s *userSQL) GetAllUser() ([]*model.User, error) {
-	q := "SELECT id, username, email, role FROM users"
-	rows, err := s.db.Query(q)
+// userColumns son las columnas que se leen en cada consulta de usuarios (sin
+// la contraseña)
+const userColumns = "id, username, email, role, version, deleted_at"
+
+// scanUser lee una fila con las columnas de userColumns
+func scanUser(row rowScanner) (*model.User, error) {
+	u := &model.User{}
+	var deletedAt sql.NullTime
+	if err := row.Scan(&u.ID, &u.Username, &u.Email, &u.Role, &u.Version, &deletedAt); err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		u.DeletedAt = &deletedAt.Time
+	}
+	return u, nil
+}
+
+// scope es la condición que deja afuera los usuarios borrados; con
+// withDeleted no filtra nada
+func (s *userSQL) scope() string {
+	if s.withDeleted {
+		return "1"
+	}
+	return "deleted_at IS NULL"
+}
+
+func (s *userSQL) GetAllUser(ctx context.Context) ([]*model.User, error) {
+	defer observe(ctx, "UserStore", "GetAllUser", time.Now())
+
+	q := "SELECT " + userColumns + " FROM users WHERE " + s.scope()
+	rows, err := s.db.QueryContext(ctx, q)
 	if err != nil {
 		return nil, err
 	}
@@ -30,8 +68,8 @@ func (s *userSQL) GetAllUser() ([]*model.User, error) {
 	var users []*model.User
 
 	for rows.Next() {
-		u := &model.User{}
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role); err != nil {
+		u, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
 		users = append(users, u)
@@ -39,9 +77,11 @@ func (s *userSQL) GetAllUser() ([]*model.User, error) {
 	return users, nil
 }
 
-func (s *userSQL) SearchByUserOrEmail(user string) ([]*model.User, error) {
-	q := "SELECT id, username, email, role FROM users WHERE username LIKE ? OR email LIKE ?"
-	rows, err := s.db.Query(q, "%"+user+"%", "%"+user+"%")
+func (s *userSQL) SearchByUserOrEmail(ctx context.Context, user string) ([]*model.User, error) {
+	defer observe(ctx, "UserStore", "SearchByUserOrEmail", time.Now())
+
+	q := "SELECT " + userColumns + " FROM users WHERE " + s.scope() + " AND (username LIKE ? OR email LIKE ?)"
+	rows, err := s.db.QueryContext(ctx, q, "%"+user+"%", "%"+user+"%")
 	if err != nil {
 		return nil, err
 	}
@@ -50,8 +90,8 @@ func (s *userSQL) SearchByUserOrEmail(user string) ([]*model.User, error) {
 	var users []*model.User
 
 	for rows.Next() {
-		u := &model.User{}
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role); err != nil {
+		u, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
 		users = append(users, u)
@@ -59,28 +99,41 @@ func (s *userSQL) SearchByUserOrEmail(user string) ([]*model.User, error) {
 	return users, nil
 }
 
-func (s *userSQL) CreateUser(user *model.User) (*model.User, error) {
+// CreateUser inserta el usuario junto con su evento user.registered en el
+// outbox (sin la contraseña)
+func (s *userSQL) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	defer observe(ctx, "UserStore", "CreateUser", time.Now())
+
 	q := "INSERT INTO users (username, email, password, role) VALUES(?, ?, ?, ?)"
-	resp, err := s.db.Exec(q, user.Username, user.Email, user.Password, user.Role)
-	if err != nil {
-		return nil, err
-	}
+	err := s.tx(ctx, func(db dbtx) error {
+		resp, err := db.ExecContext(ctx, q, user.Username, user.Email, user.Password, user.Role)
+		if err != nil {
+			return err
+		}
+
+		id, err := resp.LastInsertId()
+		if err != nil {
+			return err
+		}
+		user.ID, user.Version = int(id), 1
 
-	id, err := resp.LastInsertId()
+		return addEvent(ctx, db, model.EventUserRegistered, userEvent(user))
+	})
 	if err != nil {
 		return nil, err
 	}
-	user.ID = int(id)
-
 	return user, nil
 }
 
-func (s *userSQL) GetByEmailOrUser(user string) (*model.User, error) {
-	q := "SELECT id, username, email, role FROM users WHERE username = ? OR email = ?"
-	row := s.db.QueryRow(q, user, user)
+func (s *userSQL) GetByEmailOrUser(ctx context.Context, user string) (*model.User, error) {
+	defer observe(ctx, "UserStore", "GetByEmailOrUser", time.Now())
+
+	// Incluye el hash de la contraseña porque lo necesita el login
+	q := "SELECT id, username, email, password, role, version FROM users WHERE " + s.scope() + " AND (username = ? OR email = ?)"
+	row := s.db.QueryRowContext(ctx, q, user, user)
 
 	u := &model.User{}
-	if err := row.Scan(&u.ID, &u.Username, &u.Email, &u.Role); err != nil {
+	if err := row.Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.Role, &u.Version); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -89,9 +142,26 @@ func (s *userSQL) GetByEmailOrUser(user string) (*model.User, error) {
 	return u, nil
 }
 
-func (s *userSQL) Exists(id int) (bool, error) {
-	q := "SELECT 1 FROM users WHERE id = ?"
-	row := s.db.QueryRow(q, id)
+// GetByID busca un usuario por su ID; devuelve nil si no existe
+func (s *userSQL) GetByID(ctx context.Context, id int) (*model.User, error) {
+	defer observe(ctx, "UserStore", "GetByID", time.Now())
+
+	q := "SELECT " + userColumns + " FROM users WHERE " + s.scope() + " AND id = ?"
+	u, err := scanUser(s.db.QueryRowContext(ctx, q, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (s *userSQL) Exists(ctx context.Context, id int) (bool, error) {
+	defer observe(ctx, "UserStore", "Exists", time.Now())
+
+	q := "SELECT 1 FROM users WHERE " + s.scope() + " AND id = ?"
+	row := s.db.QueryRowContext(ctx, q, id)
 	var exists int
 	err := row.Scan(&exists)
 
@@ -104,18 +174,126 @@ func (s *userSQL) Exists(id int) (bool, error) {
 	return true, nil
 }
 
-func (s *userSQL) Update(id int, user *model.User) (*model.User, error) {
-	q := "UPDATE users SET username=?, email=?, role=?, password=? WHERE id=?"
-	_, err := s.db.Exec(q, user.Username, user.Email, user.Role, user.Password, id)
+// UserFields son las columnas editables de un usuario. Se llaman igual que
+// los campos JSON del usuario.
+var UserFields = []string{"username", "email", "role", "password"}
+
+// userEvent son los datos de un usuario que viajan en sus eventos (sin la
+// contraseña)
+func userEvent(u *model.User) map[string]any {
+	return map[string]any{"id": u.ID, "username": u.Username, "email": u.Email, "role": u.Role}
+}
+
+// Update actualiza las columnas fields (de UserFields) de un usuario, junto
+// con su evento user.updated en el outbox. Solo se aplica si el usuario sigue
+// en la versión user.Version; si no devuelve ErrVersionConflict. Suma uno a
+// la versión.
+func (s *userSQL) Update(ctx context.Context, id int, user *model.User, fields []string) (*model.User, error) {
+	defer observe(ctx, "UserStore", "Update", time.Now())
+
+	values := []any{user.Username, user.Email, user.Role, user.Password}
+	set, args, err := setColumns(UserFields, values, fields)
+	if err != nil {
+		return nil, err
+	}
+	q := "UPDATE users SET " + set + ", version=version+1 WHERE id=? AND version=? AND deleted_at IS NULL"
+
+	err = s.tx(ctx, func(db dbtx) error {
+		res, err := db.ExecContext(ctx, q, append(args, id, user.Version)...)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrVersionConflict
+		}
+		user.ID = id
+		user.Version++
+		return addEvent(ctx, db, model.EventUserUpdated, userEvent(user))
+	})
 	if err != nil {
 		return nil, err
 	}
-	user.ID = id
 	return user, nil
 }
 
-func (s *userSQL) Delete(id int) error {
-	q := "DELETE FROM users WHERE id=?"
-	_, err := s.db.Exec(q, id)
-	return err
+// UpdatePassword cambia solo el hash de la contraseña, junto con el evento
+// user.updated en el outbox (con los datos del usuario, no la contraseña)
+func (s *userSQL) UpdatePassword(ctx context.Context, id int, hash string) error {
+	defer observe(ctx, "UserStore", "UpdatePassword", time.Now())
+
+	return s.tx(ctx, func(db dbtx) error {
+		if _, err := db.ExecContext(ctx, "UPDATE users SET password=?, version=version+1 WHERE id=?", hash, id); err != nil {
+			return err
+		}
+		u, err := scanUser(db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id=?", id))
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, db, model.EventUserUpdated, userEvent(u))
+	})
+}
+
+// Delete da de baja un usuario, junto con su evento user.deleted en el
+// outbox. La fila queda con deleted_at hasta que Purge la borra. Si no hay un
+// usuario activo con ese ID devuelve ErrNotFound.
+func (s *userSQL) Delete(ctx context.Context, id int) error {
+	defer observe(ctx, "UserStore", "Delete", time.Now())
+
+	q := "UPDATE users SET deleted_at=?, version=version+1 WHERE id=? AND deleted_at IS NULL"
+
+	return s.tx(ctx, func(db dbtx) error {
+		res, err := db.ExecContext(ctx, q, time.Now().UTC(), id)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		return addEvent(ctx, db, model.EventUserDeleted, map[string]int{"id": id})
+	})
+}
+
+// Restore vuelve a dar de alta un usuario borrado, junto con su evento
+// user.restored en el outbox. Si no hay un usuario borrado con ese ID devuelve
+// ErrNotFound.
+func (s *userSQL) Restore(ctx context.Context, id int) error {
+	defer observe(ctx, "UserStore", "Restore", time.Now())
+
+	q := "UPDATE users SET deleted_at=NULL, version=version+1 WHERE id=? AND deleted_at IS NOT NULL"
+
+	return s.tx(ctx, func(db dbtx) error {
+		res, err := db.ExecContext(ctx, q, id)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		return addEvent(ctx, db, model.EventUserRestored, map[string]int{"id": id})
+	})
+}
+
+// Purge borra definitivamente los usuarios dados de baja antes de before y
+// devuelve cuántos
+func (s *userSQL) Purge(ctx context.Context, before time.Time) (int, error) {
+	defer observe(ctx, "UserStore", "Purge", time.Now())
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
 }
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"practica-go/internal/model"
+	"testing"
+)
+
+// Cada escritura de usuarios deja su evento en el outbox, sin la contraseña,
+// y una escritura que falla no deja ninguno
+func TestUserWritesEvents(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	users := st.UserStorage
+
+	user, err := users.CreateUser(ctx, &model.User{Username: "lector", Email: "lector@example.com", Password: "hash-1", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := *user
+	user.Username = "lectora"
+	if _, err := users.Update(ctx, user.ID, user, []string{"username"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.Update(ctx, user.ID, &stale, []string{"username"}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("err = %v, se esperaba ErrVersionConflict", err)
+	}
+	if err := users.UpdatePassword(ctx, user.ID, "hash-2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Restore(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := st.OutboxStorage.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		event    string
+		username string
+	}{
+		{model.EventUserRegistered, "lector"},
+		{model.EventUserUpdated, "lectora"},
+		{model.EventUserUpdated, "lectora"},
+		{model.EventUserDeleted, ""},
+		{model.EventUserRestored, ""},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("%d eventos, se esperaban %d", len(events), len(want))
+	}
+	for i, e := range events {
+		var data map[string]any
+		if err := json.Unmarshal(e.Payload, &data); err != nil {
+			t.Fatal(err)
+		}
+		if e.Event != want[i].event {
+			t.Errorf("evento %d = %s, se esperaba %s", i, e.Event, want[i].event)
+		}
+		if data["id"] != float64(user.ID) {
+			t.Errorf("evento %d: id = %v, se esperaba %d", i, data["id"], user.ID)
+		}
+		if want[i].username != "" && data["username"] != want[i].username {
+			t.Errorf("evento %d: username = %v, se esperaba %s", i, data["username"], want[i].username)
+		}
+		if _, ok := data["password"]; ok {
+			t.Errorf("evento %d lleva la contraseña: %s", i, e.Payload)
+		}
+	}
+}
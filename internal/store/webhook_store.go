@@ -0,0 +1,228 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"practica-go/internal/model"
+	"strings"
+	"time"
+)
+
+type WebhookStore interface {
+	Create(ctx context.Context, w *model.Webhook) (*model.Webhook, error)
+	GetAll(ctx context.Context) ([]*model.Webhook, error)
+	GetByID(ctx context.Context, id int) (*model.Webhook, error)
+	Delete(ctx context.Context, id int) error
+	Enqueue(ctx context.Context, event string, payload []byte, at time.Time) (int, error)
+	Due(ctx context.Context, now time.Time, limit int) ([]*DueDelivery, error)
+	RecordAttempt(ctx context.Context, d *model.WebhookDelivery) error
+	Deliveries(ctx context.Context, webhookID, limit int) ([]*model.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id int) (*model.WebhookDelivery, error)
+	Requeue(ctx context.Context, id int, at time.Time) error
+}
+
+// DueDelivery es una entrega pendiente con los datos del webhook necesarios
+// para enviarla
+type DueDelivery struct {
+	*model.WebhookDelivery
+	URL    string
+	Secret string
+}
+
+type webhookSQL struct {
+	db dbtx
+	tx txFunc
+}
+
+// deliveryColumns son las columnas que se leen de cada entrega
+const deliveryColumns = "id, webhook_id, event, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at"
+
+// scanDelivery lee una fila con las columnas de deliveryColumns más extra
+func scanDelivery(row rowScanner, extra ...any) (*model.WebhookDelivery, error) {
+	d := &model.WebhookDelivery{}
+	var payload string
+	var delivered sql.NullTime
+	dest := append([]any{&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &delivered}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	d.Payload = []byte(payload)
+	if delivered.Valid {
+		d.DeliveredAt = &delivered.Time
+	}
+	return d, nil
+}
+
+func scanWebhook(row rowScanner) (*model.Webhook, error) {
+	w := &model.Webhook{}
+	var events string
+	if err := row.Scan(&w.ID, &w.URL, &events, &w.CreatedAt); err != nil {
+		return nil, err
+	}
+	w.Events = strings.Split(events, ",")
+	return w, nil
+}
+
+// Create guarda una suscripción nueva
+func (s *webhookSQL) Create(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	defer observe(ctx, "WebhookStore", "Create", time.Now())
+
+	q := "INSERT INTO webhooks (url, events, secret, created_at) VALUES (?, ?, ?, ?)"
+	res, err := s.db.ExecContext(ctx, q, w.URL, strings.Join(w.Events, ","), w.Secret, w.CreatedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	w.ID = int(id)
+	return w, nil
+}
+
+// GetAll lista las suscripciones sin el secreto
+func (s *webhookSQL) GetAll(ctx context.Context) ([]*model.Webhook, error) {
+	defer observe(ctx, "WebhookStore", "GetAll", time.Now())
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id, url, events, created_at FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []*model.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, w)
+	}
+	return hooks, rows.Err()
+}
+
+// GetByID busca una suscripción (sin el secreto); devuelve nil si no existe
+func (s *webhookSQL) GetByID(ctx context.Context, id int) (*model.Webhook, error) {
+	defer observe(ctx, "WebhookStore", "GetByID", time.Now())
+
+	row := s.db.QueryRowContext(ctx, "SELECT id, url, events, created_at FROM webhooks WHERE id = ?", id)
+	w, err := scanWebhook(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return w, err
+}
+
+// Delete borra la suscripción y sus entregas en una transacción
+func (s *webhookSQL) Delete(ctx context.Context, id int) error {
+	defer observe(ctx, "WebhookStore", "Delete", time.Now())
+
+	return s.tx(ctx, func(db dbtx) error {
+		if _, err := db.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE webhook_id = ?", id); err != nil {
+			return err
+		}
+		_, err := db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id)
+		return err
+	})
+}
+
+// Enqueue crea una entrega pendiente del evento para cada webhook suscripto,
+// en una sola sentencia, y devuelve cuántas creó
+func (s *webhookSQL) Enqueue(ctx context.Context, event string, payload []byte, at time.Time) (int, error) {
+	defer observe(ctx, "WebhookStore", "Enqueue", time.Now())
+
+	q := `INSERT INTO webhook_deliveries (webhook_id, event, payload, next_attempt_at, created_at)
+		SELECT id, ?, ?, ?, ? FROM webhooks WHERE ',' || events || ',' LIKE ?`
+	at = at.UTC()
+	res, err := s.db.ExecContext(ctx, q, event, string(payload), at, at, "%,"+event+",%")
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// Due devuelve hasta limit entregas pendientes cuyo próximo intento ya llegó,
+// las más viejas primero
+func (s *webhookSQL) Due(ctx context.Context, now time.Time, limit int) ([]*DueDelivery, error) {
+	defer observe(ctx, "WebhookStore", "Due", time.Now())
+
+	q := "SELECT d." + strings.ReplaceAll(deliveryColumns, ", ", ", d.") + ", w.url, w.secret" +
+		" FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id" +
+		" WHERE d.status = ? AND d.next_attempt_at <= ? ORDER BY d.next_attempt_at, d.id LIMIT ?"
+	rows, err := s.db.QueryContext(ctx, q, model.DeliveryPending, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []*DueDelivery
+	for rows.Next() {
+		dd := &DueDelivery{}
+		if dd.WebhookDelivery, err = scanDelivery(rows, &dd.URL, &dd.Secret); err != nil {
+			return nil, err
+		}
+		due = append(due, dd)
+	}
+	return due, rows.Err()
+}
+
+// RecordAttempt guarda el resultado de un intento de entrega
+func (s *webhookSQL) RecordAttempt(ctx context.Context, d *model.WebhookDelivery) error {
+	defer observe(ctx, "WebhookStore", "RecordAttempt", time.Now())
+
+	var delivered any
+	if d.DeliveredAt != nil {
+		delivered = d.DeliveredAt.UTC()
+	}
+	q := `UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?,
+		last_status_code = ?, last_error = ?, delivered_at = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, q, d.Status, d.Attempts, d.NextAttemptAt.UTC(),
+		d.LastStatusCode, d.LastError, delivered, d.ID)
+	return err
+}
+
+// Deliveries es el registro de entregas de un webhook, las más nuevas primero
+func (s *webhookSQL) Deliveries(ctx context.Context, webhookID, limit int) ([]*model.WebhookDelivery, error) {
+	defer observe(ctx, "WebhookStore", "Deliveries", time.Now())
+
+	q := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?"
+	rows, err := s.db.QueryContext(ctx, q, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// GetDelivery busca una entrega; devuelve nil si no existe
+func (s *webhookSQL) GetDelivery(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	defer observe(ctx, "WebhookStore", "GetDelivery", time.Now())
+
+	row := s.db.QueryRowContext(ctx, "SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE id = ?", id)
+	d, err := scanDelivery(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return d, err
+}
+
+// Requeue vuelve a poner una entrega como pendiente para el instante at, con
+// los reintentos en cero
+func (s *webhookSQL) Requeue(ctx context.Context, id int, at time.Time) error {
+	defer observe(ctx, "WebhookStore", "Requeue", time.Now())
+
+	q := "UPDATE webhook_deliveries SET status = ?, attempts = 0, next_attempt_at = ? WHERE id = ?"
+	_, err := s.db.ExecContext(ctx, q, model.DeliveryPending, at.UTC(), id)
+	return err
+}
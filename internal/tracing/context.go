@@ -0,0 +1,130 @@
+// Package tracing implementa trazas distribuidas al estilo OpenTelemetry:
+// spans con trace/span IDs compatibles con W3C Trace Context, propagación
+// por la cabecera traceparent y exportadores enchufables que no necesitan
+// un collector (JSON por stdout y OTLP/JSON a archivo).
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/rand/v2"
+	"strings"
+)
+
+// TraceID identifica una traza completa (16 bytes)
+type TraceID [16]byte
+
+// SpanID identifica un span dentro de una traza (8 bytes)
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid indica si el ID no es todo ceros (inválido según W3C)
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+func (s SpanID) IsValid() bool  { return s != SpanID{} }
+
+// SpanContext es la parte de un span que se propaga entre procesos
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid indica si el contexto tiene IDs válidos
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// TraceParentHeader es la cabecera de W3C Trace Context
+const TraceParentHeader = "traceparent"
+
+// ParseTraceParent interpreta una cabecera traceparent versión 00:
+// 00-<trace-id 32 hex>-<parent-id 16 hex>-<flags 2 hex>
+func ParseTraceParent(h string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, errors.New("traceparent con formato inválido")
+	}
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, errors.New("versión de traceparent no soportada")
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, errors.New("trace-id inválido")
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, errors.New("parent-id inválido")
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return sc, errors.New("trace-flags inválidos")
+	}
+	if !sc.IsValid() {
+		return sc, errors.New("traceparent con IDs en cero")
+	}
+	sc.Sampled = flags[0]&0x01 == 1
+	return sc, nil
+}
+
+// TraceParent devuelve el valor de la cabecera traceparent para propagar sc
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// ContextWithSpan guarda el span activo en el context
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// SpanFromContext devuelve el span activo o nil
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// ContextWithRemoteParent guarda un SpanContext recibido de otro proceso
+// para que el próximo span lo use como padre
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// parentFromContext devuelve el contexto padre: el span activo o el remoto
+func parentFromContext(ctx context.Context) (SpanContext, bool) {
+	if s := SpanFromContext(ctx); s != nil {
+		return s.sc, true
+	}
+	sc, ok := ctx.Value(remoteKey{}).(SpanContext)
+	return sc, ok
+}
+
+func newTraceID() TraceID {
+	var t TraceID
+	for !t.IsValid() {
+		putUint64(t[:8], rand.Uint64())
+		putUint64(t[8:], rand.Uint64())
+	}
+	return t
+}
+
+func newSpanID() SpanID {
+	var s SpanID
+	for !s.IsValid() {
+		putUint64(s[:], rand.Uint64())
+	}
+	return s
+}
+
+func putUint64(b []byte, v uint64) {
+	for i := range 8 {
+		b[i] = byte(v >> (56 - 8*i))
+	}
+}
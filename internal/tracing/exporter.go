@@ -0,0 +1,207 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Exporter recibe los spans terminados. Las implementaciones deben ser
+// seguras para uso concurrente.
+type Exporter interface {
+	Export(s *Span)
+}
+
+// NewExporter crea un exportador por nombre: "stdout", "file" (OTLP/JSON en
+// path) o "none"/"" para desactivar el tracing.
+func NewExporter(kind, path, service string) (Exporter, io.Closer, error) {
+	switch kind {
+	case "", "none":
+		return nil, nil, nil
+	case "stdout":
+		return NewJSONExporter(os.Stdout), nil, nil
+	case "file":
+		if path == "" {
+			return nil, nil, fmt.Errorf("el exportador file necesita una ruta")
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewOTLPFileExporter(f, service), f, nil
+	default:
+		return nil, nil, fmt.Errorf("exportador de trazas desconocido %q", kind)
+	}
+}
+
+// JSONExporter escribe un objeto JSON legible por línea y span
+type JSONExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONExporter crea un exportador JSON sobre w
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{w: w}
+}
+
+type jsonSpan struct {
+	Name       string         `json:"name"`
+	TraceID    string         `json:"trace_id"`
+	SpanID     string         `json:"span_id"`
+	ParentID   string         `json:"parent_id,omitempty"`
+	Kind       string         `json:"kind"`
+	Start      string         `json:"start"`
+	DurationMS float64        `json:"duration_ms"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+func (e *JSONExporter) Export(s *Span) {
+	js := jsonSpan{
+		Name:       s.Name,
+		TraceID:    s.sc.TraceID.String(),
+		SpanID:     s.sc.SpanID.String(),
+		Kind:       kindName(s.Kind),
+		Start:      s.Start.Format("2006-01-02T15:04:05.000000Z07:00"),
+		DurationMS: float64(s.EndTime.Sub(s.Start).Microseconds()) / 1000,
+		Error:      s.ErrorMsg,
+	}
+	if s.Parent.IsValid() {
+		js.ParentID = s.Parent.String()
+	}
+	if attrs := s.Attrs(); len(attrs) > 0 {
+		js.Attributes = make(map[string]any, len(attrs))
+		for _, a := range attrs {
+			js.Attributes[a.Key] = a.Value
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	json.NewEncoder(e.w).Encode(js)
+}
+
+func kindName(k SpanKind) string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// OTLPFileExporter escribe cada span como un ExportTraceServiceRequest en
+// OTLP/JSON, una línea por span, igual que el file exporter del collector.
+// El archivo se puede reenviar luego a cualquier backend compatible con OTLP.
+type OTLPFileExporter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	service string
+}
+
+// NewOTLPFileExporter crea un exportador OTLP/JSON sobre w
+func NewOTLPFileExporter(w io.Writer, service string) *OTLPFileExporter {
+	return &OTLPFileExporter{w: w, service: service}
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttr `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []otlpAttr `json:"attributes,omitempty"`
+	Status            otlpStatus `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttr struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// otlpValue convierte un valor Go al AnyValue de OTLP/JSON
+func otlpValue(v any) map[string]any {
+	switch x := v.(type) {
+	case string:
+		return map[string]any{"stringValue": x}
+	case int:
+		return map[string]any{"intValue": strconv.Itoa(x)}
+	case int64:
+		return map[string]any{"intValue": strconv.FormatInt(x, 10)}
+	case bool:
+		return map[string]any{"boolValue": x}
+	case float64:
+		return map[string]any{"doubleValue": x}
+	default:
+		return map[string]any{"stringValue": fmt.Sprint(x)}
+	}
+}
+
+func (e *OTLPFileExporter) Export(s *Span) {
+	span := otlpSpan{
+		TraceID:           s.sc.TraceID.String(),
+		SpanID:            s.sc.SpanID.String(),
+		Name:              s.Name,
+		Kind:              int(s.Kind),
+		StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+	}
+	if s.Parent.IsValid() {
+		span.ParentSpanID = s.Parent.String()
+	}
+	for _, a := range s.Attrs() {
+		span.Attributes = append(span.Attributes, otlpAttr{Key: a.Key, Value: otlpValue(a.Value)})
+	}
+	if s.ErrorMsg != "" {
+		span.Status = otlpStatus{Code: 2, Message: s.ErrorMsg}
+	}
+
+	req := otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttr{
+			{Key: "service.name", Value: otlpValue(e.service)},
+		}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "practica-go/internal/tracing"},
+			Spans: []otlpSpan{span},
+		}},
+	}}}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	json.NewEncoder(e.w).Encode(req)
+}
@@ -0,0 +1,140 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SpanKind indica el rol del span, con los mismos valores que OTLP
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// Attr es un atributo clave/valor de un span
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String, Int y Bool crean atributos tipados
+func String(k, v string) Attr    { return Attr{Key: k, Value: v} }
+func Int(k string, v int) Attr   { return Attr{Key: k, Value: v} }
+func Bool(k string, v bool) Attr { return Attr{Key: k, Value: v} }
+
+// Span es una operación con nombre y duración dentro de una traza.
+// Un *Span nil es válido y no hace nada, para que el código instrumentado
+// no tenga que comprobar si el tracing está activo.
+type Span struct {
+	Name     string
+	Kind     SpanKind
+	Parent   SpanID
+	Start    time.Time
+	EndTime  time.Time
+	ErrorMsg string
+
+	sc SpanContext
+
+	mu    sync.Mutex
+	attrs []Attr
+	ended bool
+}
+
+// SpanContext devuelve los IDs del span para propagarlos
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttr agrega atributos al span
+func (s *Span) SetAttr(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// Attrs devuelve una copia de los atributos del span
+func (s *Span) Attrs() []Attr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Attr(nil), s.attrs...)
+}
+
+// RecordError marca el span como fallido si err no es nil
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.ErrorMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// End cierra el span y lo envía al exportador. Llamarlo dos veces no tiene efecto.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+
+	if e := exporter.Load(); e != nil && s.sc.Sampled {
+		(*e).Export(s)
+	}
+}
+
+// exporter es el destino global de los spans; nil desactiva el tracing
+var exporter atomic.Pointer[Exporter]
+
+// SetExporter configura el exportador global; nil desactiva el tracing
+func SetExporter(e Exporter) {
+	if e == nil {
+		exporter.Store(nil)
+		return
+	}
+	exporter.Store(&e)
+}
+
+// Enabled indica si hay un exportador configurado
+func Enabled() bool {
+	return exporter.Load() != nil
+}
+
+// Start crea un span hijo del span activo en ctx (o del padre remoto) y lo
+// deja activo en el context devuelto. Si el tracing está desactivado
+// devuelve un span nil.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return StartKind(ctx, name, KindInternal, attrs...)
+}
+
+// StartKind es como Start pero indicando el tipo de span
+func StartKind(ctx context.Context, name string, kind SpanKind, attrs ...Attr) (context.Context, *Span) {
+	if !Enabled() {
+		return ctx, nil
+	}
+
+	s := &Span{Name: name, Kind: kind, Start: time.Now(), attrs: attrs}
+	if parent, ok := parentFromContext(ctx); ok && parent.IsValid() {
+		s.sc = SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: parent.Sampled}
+		s.Parent = parent.SpanID
+	} else {
+		s.sc = SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: true}
+	}
+	return ContextWithSpan(ctx, s), s
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"net/http"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"practica-go/internal/transport"
+	"strconv"
+	"time"
+)
+
+type AuditHandler struct {
+	service *service.AuditService
+}
+
+func New(s *service.AuditService) *AuditHandler {
+	return &AuditHandler{service: s}
+}
+
+// HandleAudit consulta el registro de auditoría. Filtros opcionales:
+// entity_type, entity_id, actor_id, from y to (RFC 3339), before_id y limit.
+func (h *AuditHandler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+
+	q := r.URL.Query()
+	f := store.AuditFilter{EntityType: q.Get("entity_type")}
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{"entity_id", &f.EntityID},
+		{"actor_id", &f.ActorID},
+		{"before_id", &f.BeforeID},
+		{"limit", &f.Limit},
+	}
+	for _, p := range ints {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			transport.WriteError(w, http.StatusBadRequest, p.name+" inválido")
+			return
+		}
+		*p.dst = n
+	}
+	times := []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"from", &f.From},
+		{"to", &f.To},
+	}
+	for _, p := range times {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			transport.WriteError(w, http.StatusBadRequest, p.name+" debe tener formato RFC 3339")
+			return
+		}
+		*p.dst = t
+	}
+
+	entries, err := h.service.QueryAudit(r.Context(), f)
+	if err != nil {
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"entries": entries})
+}
@@ -2,9 +2,12 @@ package books
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"practica-go/internal/middleware"
 	"practica-go/internal/model"
 	"practica-go/internal/service"
+	"practica-go/internal/store"
 	"practica-go/internal/transport"
 	"strconv"
 	"strings"
@@ -22,7 +25,11 @@ func New(s *service.BookService) *BookHandler {
 func (h *BookHandler) HandleBooks(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		libros, err := h.service.GetAllBooks()
+		svc, ok := h.readService(w, r)
+		if !ok {
+			return
+		}
+		libros, err := svc.GetAllBooks(r.Context())
 		if err != nil {
 			transport.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -34,7 +41,7 @@ func (h *BookHandler) HandleBooks(w http.ResponseWriter, r *http.Request) {
 			transport.WriteError(w, http.StatusBadRequest, "input inválido")
 			return
 		}
-		created, err := h.service.CreateBook(&libro)
+		created, err := h.service.CreateBook(r.Context(), &libro)
 		if err != nil {
 			transport.WriteError(w, http.StatusBadRequest, err.Error())
 			return
@@ -45,40 +52,96 @@ func (h *BookHandler) HandleBooks(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Manejo de libro por ID
+// readService devuelve el service para las lecturas: con include_deleted
+// (solo admins) incluye los libros borrados
+func (h *BookHandler) readService(w http.ResponseWriter, r *http.Request) (*service.BookService, bool) {
+	include, ok := transport.IncludeDeleted(w, r)
+	if !ok {
+		return nil, false
+	}
+	if include {
+		return h.service.WithDeleted(), true
+	}
+	return h.service, true
+}
+
+// Manejo de libro por ID: /books/{id} y /books/{id}/restore
 func (h *BookHandler) HandleBookByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/books/")
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/books/"), "/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
 		transport.WriteError(w, http.StatusBadRequest, "id inválido")
 		return
 	}
+	switch action {
+	case "":
+	case "restore":
+		middleware.RequireRole("admin", func(w http.ResponseWriter, r *http.Request) {
+			h.handleRestore(w, r, id)
+		})(w, r)
+		return
+	default:
+		transport.WriteError(w, http.StatusNotFound, "ruta no encontrada")
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
-		libro, err := h.service.GetBookByID(id)
+		svc, ok := h.readService(w, r)
+		if !ok {
+			return
+		}
+		libro, err := svc.GetBookByID(r.Context(), id)
 		if err != nil {
 			transport.WriteError(w, http.StatusNotFound, err.Error())
 			return
 		}
+		w.Header().Set("ETag", transport.ETag(libro.Version))
 		transport.WriteJSON(w, http.StatusOK, map[string]any{"book": libro})
 
 	case http.MethodPut:
+		version, ok := transport.IfMatch(w, r)
+		if !ok {
+			return
+		}
 		var libro model.Book
 		if err := json.NewDecoder(r.Body).Decode(&libro); err != nil {
 			transport.WriteError(w, http.StatusBadRequest, "input inválido")
 			return
 		}
-		updated, err := h.service.UpdateBook(id, &libro)
+		libro.Version = version
+		updated, err := h.service.UpdateBook(r.Context(), id, &libro)
 		if err != nil {
-			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			writeWriteError(w, err)
+			return
+		}
+		w.Header().Set("ETag", transport.ETag(updated.Version))
+		transport.WriteJSON(w, http.StatusOK, map[string]any{"book": updated})
+
+	case http.MethodPatch:
+		version, ok := transport.IfMatch(w, r)
+		if !ok {
 			return
 		}
+		mediaType, doc, ok := transport.ReadPatch(w, r)
+		if !ok {
+			return
+		}
+		updated, err := h.service.PatchBook(r.Context(), id, version, mediaType, doc)
+		if err != nil {
+			writeWriteError(w, err)
+			return
+		}
+		w.Header().Set("ETag", transport.ETag(updated.Version))
 		transport.WriteJSON(w, http.StatusOK, map[string]any{"book": updated})
 
 	case http.MethodDelete:
-		if err := h.service.DeleteBook(id); err != nil {
-			transport.WriteError(w, http.StatusBadRequest, err.Error())
+		version, ok := transport.IfMatch(w, r)
+		if !ok {
+			return
+		}
+		if err := h.service.DeleteBook(r.Context(), id, version); err != nil {
+			writeWriteError(w, err)
 			return
 		}
 		transport.WriteJSON(w, http.StatusNoContent, map[string]string{"message": "el libro fue eliminado"})
@@ -86,3 +149,27 @@ func (h *BookHandler) HandleBookByID(w http.ResponseWriter, r *http.Request) {
 		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
 	}
 }
+
+// writeWriteError responde el error de una escritura: 412 si la versión de
+// If-Match ya no es la actual
+func writeWriteError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrVersionConflict) {
+		transport.WriteError(w, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+	transport.WriteError(w, http.StatusBadRequest, err.Error())
+}
+
+// handleRestore vuelve a dar de alta un libro borrado (solo admins)
+func (h *BookHandler) handleRestore(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+	restored, err := h.service.RestoreBook(r.Context(), id)
+	if err != nil {
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"book": restored})
+}
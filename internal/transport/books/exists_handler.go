@@ -21,7 +21,7 @@ func (h *BookHandler) HandleBookExists(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	exists, err := h.service.BookExists(id)
+	exists, err := h.service.BookExists(r.Context(), id)
 	if err != nil {
 		transport.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
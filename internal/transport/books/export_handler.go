@@ -0,0 +1,68 @@
+package books
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"practica-go/internal/export"
+	"practica-go/internal/model"
+	"practica-go/internal/query"
+	"practica-go/internal/transport"
+	"strings"
+	"time"
+)
+
+// Exportación del catálogo: GET /books/export?format=csv|jsonl|xlsx|onix&q=...
+// Acepta el mismo filtro q que /books/query; sin q exporta todo el catálogo.
+// Las filas se escriben a medida que salen de la base.
+func (h *BookHandler) HandleExportBooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	var expr query.Expr
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		var err error
+		if expr, err = query.Parse(q); err != nil {
+			var qerr *query.Error
+			if errors.As(err, &qerr) {
+				transport.WriteJSON(w, http.StatusBadRequest, qerr)
+				return
+			}
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// Un catálogo grande tarda más que el WriteTimeout del servidor en salir:
+	// sin ese límite, el corte lo decide el context de la petición
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(r.Context(), "no se pudo quitar el límite de escritura", slog.Any("error", err))
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="catalogo.`+export.Extension(format)+`"`)
+	out, err := export.New(format, w)
+	if err != nil {
+		w.Header().Del("Content-Disposition")
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.service.ExportBooks(r.Context(), expr, func(b *model.Book) error {
+		return out.Write(b)
+	})
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		// El estado 200 ya se envió: solo queda registrar el corte
+		slog.ErrorContext(r.Context(), "exportación interrumpida", slog.String("format", format), slog.Any("error", err))
+	}
+}
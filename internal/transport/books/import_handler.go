@@ -0,0 +1,70 @@
+package books
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"practica-go/internal/service"
+	"practica-go/internal/transport"
+	"strings"
+)
+
+// maxImportSize limita el tamaño del CSV aceptado
+const maxImportSize = 10 << 20
+
+var tooLargeMessage = fmt.Sprintf("el CSV supera el máximo de %d bytes", maxImportSize)
+
+// Importación masiva: POST /books/import?map=title:Nombre,author:Escritor
+// El CSV puede venir como cuerpo (text/csv) o como archivo "file" en multipart.
+func (h *BookHandler) HandleImportBooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+
+	mapping, err := service.ParseCSVMapping(r.URL.Query().Get("map"))
+	if err != nil {
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			transport.WriteError(w, http.StatusRequestEntityTooLarge, tooLargeMessage)
+			return
+		}
+		if err != nil {
+			transport.WriteError(w, http.StatusBadRequest, "falta el archivo CSV en el campo file")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	report, err := h.service.ImportCSV(r.Context(), body, mapping)
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		// Las filas anteriores al corte ya se guardaron: el reporte dice cuáles
+		resp := map[string]any{"error": tooLargeMessage}
+		if report != nil {
+			resp["report"] = report
+		}
+		transport.WriteJSON(w, http.StatusRequestEntityTooLarge, resp)
+		return
+	}
+	if err != nil && report == nil {
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status := http.StatusOK
+	if report.Created > 0 {
+		status = http.StatusCreated
+	}
+	transport.WriteJSON(w, status, map[string]any{"report": report})
+}
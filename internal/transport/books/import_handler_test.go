@@ -0,0 +1,109 @@
+package books
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestHandler(t *testing.T) *BookHandler {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st := store.New(db)
+	if _, err := st.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return New(service.NewBook(*st))
+}
+
+// Un CSV que supera el límite después de algunas filas válidas responde 413
+// con el reporte de lo que sí se guardó
+func TestImportTooLargeKeepsReport(t *testing.T) {
+	h := newTestHandler(t)
+
+	var body bytes.Buffer
+	body.WriteString("title,author\n")
+	for i := range 150 {
+		fmt.Fprintf(&body, "Libro %d,Autora %d\n", i, i)
+	}
+	// Una celda entre comillas que no termina antes del límite
+	body.WriteString(`"` + strings.Repeat("a", maxImportSize))
+
+	req := httptest.NewRequest(http.MethodPost, "/books/import", &body)
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+	h.HandleImportBooks(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, se esperaba 413: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Error  string                `json:"error"`
+		Report *service.ImportReport `json:"report"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == "" || resp.Report == nil {
+		t.Fatalf("respuesta = %s", rec.Body)
+	}
+	if resp.Report.Created != 150 || resp.Report.Failed != 1 {
+		t.Errorf("created = %d, failed = %d; se esperaba 150 y 1", resp.Report.Created, resp.Report.Failed)
+	}
+}
+
+func TestImportMultipartTooLarge(t *testing.T) {
+	h := newTestHandler(t)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "catalogo.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("title,author\n" + strings.Repeat("Ficciones,Borges\n", maxImportSize/16)))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/books/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.HandleImportBooks(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, se esperaba 413: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestImportMultipartWithoutFile(t *testing.T) {
+	h := newTestHandler(t)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("otro", "valor")
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/books/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.HandleImportBooks(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, se esperaba 400: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,36 @@
+package books
+
+import (
+	"net/http"
+	"practica-go/internal/transport"
+	"strconv"
+)
+
+// maxMARCSize limita el archivo MARC aceptado; se lee registro por registro
+const maxMARCSize = 100 << 20
+
+// Importación MARC21: POST /books/marc[?dry_run=true] con registros ISO 2709
+// o MARCXML como cuerpo. Con dry_run solo valida y devuelve el reporte.
+func (h *BookHandler) HandleImportMARC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			transport.WriteError(w, http.StatusBadRequest, "dry_run debe ser true o false")
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxMARCSize)
+	report, err := h.service.ImportMARC(r.Context(), r.Body, dryRun)
+	if err != nil {
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"report": report})
+}
@@ -0,0 +1,26 @@
+package books
+
+import (
+	"net/http"
+	"practica-go/internal/transport"
+)
+
+// maxONIXSize limita el mensaje ONIX aceptado; se lee producto por producto
+const maxONIXSize = 100 << 20
+
+// Importación ONIX 3.0: POST /books/onix con el mensaje XML como cuerpo.
+// Crea o actualiza libros por ISBN y devuelve el reporte por producto.
+func (h *BookHandler) HandleImportONIX(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxONIXSize)
+	report, err := h.service.ImportONIX(r.Context(), r.Body)
+	if err != nil {
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"report": report})
+}
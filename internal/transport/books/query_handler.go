@@ -0,0 +1,35 @@
+package books
+
+import (
+	"errors"
+	"net/http"
+	"practica-go/internal/query"
+	"practica-go/internal/transport"
+	"strings"
+)
+
+// Manejo de búsqueda avanzada: GET /books/query?q=author:"Borges" year:1940..1960
+func (h *BookHandler) HandleQueryBooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		transport.WriteError(w, http.StatusBadRequest, "la consulta no puede quedar vacía")
+		return
+	}
+
+	results, err := h.service.QueryBooks(r.Context(), q)
+	if err != nil {
+		var qerr *query.Error
+		if errors.As(err, &qerr) {
+			// Devolvemos la posición y el token para que el cliente pueda marcarlo
+			transport.WriteJSON(w, http.StatusBadRequest, qerr)
+			return
+		}
+		transport.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"results": results})
+}
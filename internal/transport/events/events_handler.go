@@ -0,0 +1,117 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"practica-go/internal/events"
+	"practica-go/internal/transport"
+	"strconv"
+	"time"
+)
+
+// heartbeat es cada cuánto se manda un comentario para que los proxies no
+// corten la conexión por inactividad
+const heartbeat = 15 * time.Second
+
+// retryMillis es la espera que se le sugiere al navegador antes de reconectar
+const retryMillis = 3000
+
+type EventsHandler struct {
+	broker *events.Broker
+}
+
+func New(b *events.Broker) *EventsHandler {
+	return &EventsHandler{broker: b}
+}
+
+// HandleEvents abre un stream de Server-Sent Events con los cambios del
+// catálogo. Con Last-Event-ID (o ?last_event_id=) primero se envían los
+// eventos que el cliente se perdió; si alguno ya no está en el buffer se
+// envía antes un evento resync para que recargue el estado completo.
+func (h *EventsHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+
+	lastIDStr := r.Header.Get("Last-Event-ID")
+	if lastIDStr == "" {
+		lastIDStr = r.URL.Query().Get("last_event_id")
+	}
+	var lastID uint64
+	if lastIDStr != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(lastIDStr, 10, 64); err != nil {
+			transport.WriteError(w, http.StatusBadRequest, "Last-Event-ID inválido")
+			return
+		}
+	}
+
+	// El stream dura lo que el cliente quiera: sin el límite de escritura
+	// del servidor
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, "el servidor no soporta streaming")
+		return
+	}
+
+	sub, missed, complete := h.broker.Subscribe(lastID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+
+	if !complete {
+		fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+	}
+	for _, e := range missed {
+		if err := writeEvent(w, e); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				if sub.Dropped() {
+					slog.WarnContext(r.Context(), "cliente de eventos desconectado por lento")
+				}
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent escribe un evento en el formato de SSE; el JSON va en una sola
+// línea de data
+func writeEvent(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
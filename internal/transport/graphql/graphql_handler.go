@@ -0,0 +1,64 @@
+package graphql
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"practica-go/internal/service"
+	"practica-go/internal/transport"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// maxDepth limita el anidamiento de las consultas (ej. autor → libros →
+// autor → ...) para que una consulta no recorra el catálogo entero
+const maxDepth = 8
+
+type GraphQLHandler struct {
+	schema *graphql.Schema
+	books  *service.BookService
+}
+
+// New arma el esquema con los services. Si el esquema y los resolvers no
+// coinciden entra en pánico: es un error de programación, no de datos.
+func New(books *service.BookService, users *service.UserService) *GraphQLHandler {
+	schema := graphql.MustParseSchema(schemaSDL, &resolver{books: books, users: users},
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(maxDepth),
+	)
+	return &GraphQLHandler{schema: schema, books: books}
+}
+
+// graphQLRequest es el cuerpo de POST /graphql
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Manejo de consultas GraphQL: POST /graphql con {"query", "variables"}.
+// Los errores de resolución van en "errors" con status 200, como indica la
+// convención de GraphQL sobre HTTP; solo un cuerpo ilegible responde 400.
+func (h *GraphQLHandler) HandleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+
+	var req graphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, "input no valido")
+		return
+	}
+	if req.Query == "" {
+		transport.WriteError(w, http.StatusBadRequest, "la consulta no puede quedar vacía")
+		return
+	}
+
+	ctx := withLoaders(r.Context(), newLoaders(h.books))
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	transport.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,321 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"practica-go/internal/model"
+	"practica-go/internal/reqctx"
+	"practica-go/internal/security"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"golang.org/x/crypto/bcrypt"
+	_ "modernc.org/sqlite"
+)
+
+// testEnv es un esquema armado sobre una base temporal con algunos libros y
+// tres usuarios: un admin y dos usuarios comunes
+type testEnv struct {
+	h     *GraphQLHandler
+	books *service.BookService
+	users *service.UserService
+	admin *model.User
+	alice *model.User
+	bob   *model.User
+
+	mu            sync.Mutex
+	authorBatches [][]string // claves de cada llamada a BooksByAuthors
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	if err := security.SetBcryptCost(bcrypt.MinCost); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st := store.New(db)
+	ctx := context.Background()
+	if _, err := st.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	env := &testEnv{books: service.NewBook(*st)}
+	env.users = service.NewUser(*st, security.NewTokenIssuer("0123456789abcdef0123456789abcdef", time.Minute, time.Hour))
+	env.h = New(env.books, env.users)
+
+	for _, b := range []*model.Book{
+		{Titulo: "Ficciones", Autor: "Jorge Luis Borges"},
+		{Titulo: "El Aleph", Autor: "Jorge Luis Borges"},
+		{Titulo: "Rayuela", Autor: "Julio Cortázar"},
+		{Titulo: "Bestiario", Autor: "Julio Cortázar"},
+		{Titulo: "Sobre héroes y tumbas", Autor: "Ernesto Sabato"},
+	} {
+		if _, err := env.books.CreateBook(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newUser := func(name string, create func(context.Context, *model.User) (*model.User, error)) *model.User {
+		u, err := create(ctx, &model.User{Username: name, Email: name + "@ejemplo.com", Password: "clave-segura-123"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	env.admin = newUser("admin", env.users.CreateAdmin)
+	env.alice = newUser("alice", env.users.Register)
+	env.bob = newUser("bob", env.users.Register)
+	return env
+}
+
+// exec corre la consulta como user (nil = anónimo) con loaders nuevos, como
+// HandleGraphQL, pero contando las llamadas a BooksByAuthors
+func (env *testEnv) exec(user *model.User, q string) *graphql.Response {
+	ctx, info := reqctx.New(context.Background(), "")
+	if user != nil {
+		info.UserID, info.Role = user.ID, user.Role
+	}
+	l := newLoaders(env.books)
+	l.booksByAuthor = newLoader(func(ctx context.Context, keys []string) (map[string][]*model.Book, error) {
+		env.mu.Lock()
+		env.authorBatches = append(env.authorBatches, slices.Sorted(slices.Values(keys)))
+		env.mu.Unlock()
+		return env.books.BooksByAuthors(ctx, keys)
+	})
+	return env.h.schema.Exec(withLoaders(ctx, l), q, "", nil)
+}
+
+// errorPaths devuelve el camino de cada error, ej. "register.email"
+func errorPaths(resp *graphql.Response) []string {
+	var paths []string
+	for _, e := range resp.Errors {
+		parts := make([]string, len(e.Path))
+		for i, p := range e.Path {
+			parts[i] = fmt.Sprint(p)
+		}
+		paths = append(paths, strings.Join(parts, "."))
+	}
+	return paths
+}
+
+func TestAuthorBooksBatched(t *testing.T) {
+	allAuthors := []string{"Ernesto Sabato", "Jorge Luis Borges", "Julio Cortázar"}
+
+	tests := []struct {
+		name  string
+		query string
+		want  [][]string
+	}{
+		{
+			name:  "un nivel",
+			query: `{ authors { name books { title } } }`,
+			want:  [][]string{allAuthors},
+		},
+		{
+			// El segundo nivel pide los mismos autores: sale de la caché del loader
+			name:  "autor, libros, autor, libros",
+			query: `{ authors { books { author { books { title } } } } }`,
+			want:  [][]string{allAuthors},
+		},
+		{
+			name:  "desde una página de libros",
+			query: `{ books(first: 10) { books { author { books { author { books { title } } } } } } }`,
+			want:  [][]string{allAuthors},
+		},
+		{
+			// Los campos raíz de una consulta se resuelven en paralelo: los dos
+			// autores van en el mismo lote
+			name:  "campos raíz con alias",
+			query: `{ a: author(name: "Julio Cortázar") { books { title } } b: author(name: "Ernesto Sabato") { books { title } } }`,
+			want:  [][]string{{"Ernesto Sabato", "Julio Cortázar"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			resp := env.exec(nil, tt.query)
+			if len(resp.Errors) > 0 {
+				t.Fatalf("errores: %v", resp.Errors)
+			}
+			if !slices.EqualFunc(env.authorBatches, tt.want, slices.Equal[[]string]) {
+				t.Errorf("llamadas a BooksByAuthors = %v, se esperaba %v", env.authorBatches, tt.want)
+			}
+		})
+	}
+
+	// Los libros de cada autor son los suyos aunque se pidan en un solo lote
+	env := newTestEnv(t)
+	resp := env.exec(nil, `{ author(name: "Julio Cortázar") { books { title author { name } } } }`)
+	var data struct {
+		Author struct {
+			Books []struct {
+				Title  string
+				Author struct{ Name string }
+			}
+		}
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Author.Books) != 2 || data.Author.Books[0].Author.Name != "Julio Cortázar" {
+		t.Errorf("libros de Cortázar = %+v", data.Author.Books)
+	}
+}
+
+func TestUserEmailVisibility(t *testing.T) {
+	env := newTestEnv(t)
+	aliceID := formatID(env.alice.ID)
+
+	tests := []struct {
+		name      string
+		as        *model.User
+		query     string
+		wantEmail bool
+		wantErrs  []string
+	}{
+		{name: "el propio usuario", as: env.alice, query: `{ me { email } }`, wantEmail: true},
+		{name: "admin", as: env.admin, query: `{ user(id: "` + string(aliceID) + `") { email } }`, wantEmail: true},
+		{name: "otro usuario", as: env.bob, query: `{ user(id: "` + string(aliceID) + `") { email } }`, wantErrs: []string{"user"}},
+		{name: "lista sin ser admin", as: env.bob, query: `{ users { username email } }`, wantErrs: []string{"users"}},
+		{
+			// El alta anónima devuelve el usuario creado pero no su email
+			name: "registro anónimo", as: nil,
+			query:    `mutation { register(input: {username: "carla", email: "carla@ejemplo.com", password: "clave-segura-123"}) { username email } }`,
+			wantErrs: []string{"register.email"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := env.exec(tt.as, tt.query)
+			if got := errorPaths(resp); !slices.Equal(got, tt.wantErrs) {
+				t.Fatalf("errores en %v, se esperaban en %v (%v)", got, tt.wantErrs, resp.Errors)
+			}
+			if got := strings.Contains(string(resp.Data), "@ejemplo.com"); got != tt.wantEmail {
+				t.Errorf("respuesta = %s; ¿email visible? %v, se esperaba %v", resp.Data, got, tt.wantEmail)
+			}
+		})
+	}
+}
+
+// Ningún tipo de salida expone la contraseña: solo aparece en los inputs
+func TestSchemaHasNoPassword(t *testing.T) {
+	env := newTestEnv(t)
+	resp := env.exec(env.admin, `{ __schema { types { name kind fields { name } } } }`)
+	if len(resp.Errors) > 0 {
+		t.Fatal(resp.Errors)
+	}
+	var data struct {
+		Schema struct {
+			Types []struct {
+				Name   string
+				Kind   string
+				Fields []struct{ Name string }
+			}
+		} `json:"__schema"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	userFields := 0
+	for _, typ := range data.Schema.Types {
+		if typ.Name == "User" {
+			userFields = len(typ.Fields)
+		}
+		for _, f := range typ.Fields {
+			if strings.Contains(strings.ToLower(f.Name), "password") {
+				t.Errorf("el tipo %s expone el campo %s", typ.Name, f.Name)
+			}
+		}
+	}
+
+	if userFields == 0 {
+		t.Fatalf("la introspección no devolvió el tipo User: %s", resp.Data)
+	}
+
+	resp = env.exec(env.admin, `{ me { password } }`)
+	if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, "password") {
+		t.Errorf("se esperaba un error de validación por el campo password: %v", resp.Errors)
+	}
+}
+
+func TestMutationRoles(t *testing.T) {
+	const (
+		unauthenticated = "se requiere autenticación"
+		forbidden       = "no tenés permisos para esta operación"
+	)
+	tests := []struct {
+		name    string
+		as      func(env *testEnv) *model.User
+		query   func(env *testEnv) string
+		wantErr string
+	}{
+		{name: "createBook anónimo", as: anonymous, query: createBook, wantErr: unauthenticated},
+		{name: "createBook usuario", as: alice, query: createBook},
+		{name: "updateBook anónimo", as: anonymous, query: updateBook(1), wantErr: unauthenticated},
+		{name: "updateBook usuario", as: alice, query: updateBook(1)},
+		{name: "deleteBook anónimo", as: anonymous, query: deleteBook(1), wantErr: unauthenticated},
+		{name: "deleteBook usuario", as: alice, query: deleteBook(1), wantErr: forbidden},
+		{name: "deleteBook admin", as: admin, query: deleteBook(1)},
+		{name: "updateUser anónimo", as: anonymous, query: updateAlice, wantErr: unauthenticated},
+		{name: "updateUser otro usuario", as: bob, query: updateAlice, wantErr: forbidden},
+		{name: "updateUser el propio", as: alice, query: updateAlice},
+		{name: "updateUser admin", as: admin, query: updateAlice},
+		{name: "deleteUser usuario", as: bob, query: deleteAlice, wantErr: forbidden},
+		{name: "deleteUser el propio", as: alice, query: deleteAlice, wantErr: forbidden},
+		{name: "deleteUser admin", as: admin, query: deleteAlice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			resp := env.exec(tt.as(env), tt.query(env))
+			switch {
+			case tt.wantErr == "" && len(resp.Errors) > 0:
+				t.Fatalf("errores: %v", resp.Errors)
+			case tt.wantErr != "" && (len(resp.Errors) != 1 || resp.Errors[0].Message != tt.wantErr):
+				t.Fatalf("errores = %v, se esperaba %q", resp.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func anonymous(*testEnv) *model.User { return nil }
+func alice(env *testEnv) *model.User { return env.alice }
+func bob(env *testEnv) *model.User   { return env.bob }
+func admin(env *testEnv) *model.User { return env.admin }
+
+func createBook(*testEnv) string {
+	return `mutation { createBook(input: {title: "Bestiario", author: "Julio Cortázar", year: 1951}) { id } }`
+}
+
+func updateBook(id int) func(*testEnv) string {
+	return func(*testEnv) string {
+		return fmt.Sprintf(`mutation { updateBook(id: "%d", version: -1, input: {title: "Ficciones (ed. 2)", author: "Jorge Luis Borges"}) { version } }`, id)
+	}
+}
+
+func deleteBook(id int) func(*testEnv) string {
+	return func(*testEnv) string {
+		return fmt.Sprintf(`mutation { deleteBook(id: "%d", version: -1) }`, id)
+	}
+}
+
+func updateAlice(env *testEnv) string {
+	return fmt.Sprintf(`mutation { updateUser(id: "%d", version: -1, input: {username: "alicia"}) { username } }`, env.alice.ID)
+}
+
+func deleteAlice(env *testEnv) string {
+	return fmt.Sprintf(`mutation { deleteUser(id: "%d", version: -1) }`, env.alice.ID)
+}
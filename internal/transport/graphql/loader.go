@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"context"
+	"practica-go/internal/model"
+	"practica-go/internal/service"
+	"sync"
+	"time"
+)
+
+// loaderWait es cuánto espera un loader a que lleguen más claves antes de
+// consultar la base. Los campos de una lista se resuelven en paralelo, así que
+// alcanza con muy poco para juntar todas las claves de un mismo nivel.
+const loaderWait = 2 * time.Millisecond
+
+// loader junta las claves pedidas por distintos resolvers en una sola llamada
+// a fetch y guarda los resultados durante la petición, para evitar el N+1
+// (ej. los libros de cada autor de una lista).
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu    sync.Mutex
+	cache map[K]V
+	batch *batch[K, V]
+}
+
+// batch es un grupo de claves que se resuelve con una sola llamada a fetch
+type batch[K comparable, V any] struct {
+	keys   []K
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, cache: make(map[K]V)}
+}
+
+// Load devuelve el valor de key; si no está en el mapa que devuelve fetch se
+// devuelve el valor cero de V.
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	if v, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		return v, nil
+	}
+	b := l.batch
+	if b == nil {
+		b = &batch[K, V]{done: make(chan struct{})}
+		l.batch = b
+		time.AfterFunc(loaderWait, func() { l.run(ctx, b) })
+	}
+	b.keys = append(b.keys, key)
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+	return b.values[key], b.err
+}
+
+// run cierra el lote para que las claves nuevas vayan a otro y lo resuelve
+func (l *loader[K, V]) run(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	b.values, b.err = l.fetch(ctx, uniq(b.keys))
+	if b.err == nil {
+		l.mu.Lock()
+		for k, v := range b.values {
+			l.cache[k] = v
+		}
+		l.mu.Unlock()
+	}
+	close(b.done)
+}
+
+// uniq quita las claves repetidas conservando el orden
+func uniq[K comparable](keys []K) []K {
+	seen := make(map[K]bool, len(keys))
+	out := keys[:0:0]
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// loaders son los loaders de una petición. Se crean por petición para que
+// la caché no mezcle datos de usuarios distintos ni quede desactualizada.
+type loaders struct {
+	bookByID      *loader[int, *model.Book]
+	booksByAuthor *loader[string, []*model.Book]
+}
+
+func newLoaders(books *service.BookService) *loaders {
+	return &loaders{
+		bookByID:      newLoader(books.BooksByIDs),
+		booksByAuthor: newLoader(books.BooksByAuthors),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
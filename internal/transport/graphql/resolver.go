@@ -0,0 +1,475 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"practica-go/internal/model"
+	"practica-go/internal/query"
+	"practica-go/internal/reqctx"
+	"practica-go/internal/service"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// maxPageSize limita el argumento first de books
+const maxPageSize = 100
+
+// resolver es la raíz del esquema: resuelve Query y Mutation con los services
+type resolver struct {
+	books *service.BookService
+	users *service.UserService
+}
+
+// Autorización
+
+var (
+	errUnauthenticated = errors.New("se requiere autenticación")
+	errForbidden       = errors.New("no tenés permisos para esta operación")
+)
+
+// requireRole replica middleware.RequireRole: con role vacío alcanza con
+// estar autenticado
+func requireRole(ctx context.Context, role string) error {
+	if reqctx.UserID(ctx) == 0 {
+		return errUnauthenticated
+	}
+	if role != "" && reqctx.Role(ctx) != role {
+		return errForbidden
+	}
+	return nil
+}
+
+// requireSelfOrAdmin deja pasar al propio usuario o a un admin
+func requireSelfOrAdmin(ctx context.Context, userID int) error {
+	if err := requireRole(ctx, ""); err != nil {
+		return err
+	}
+	if reqctx.UserID(ctx) != userID && reqctx.Role(ctx) != "admin" {
+		return errForbidden
+	}
+	return nil
+}
+
+func parseID(id graphql.ID) (int, error) {
+	n, err := strconv.Atoi(string(id))
+	if err != nil || n <= 0 {
+		return 0, errors.New("id inválido")
+	}
+	return n, nil
+}
+
+func formatID(id int) graphql.ID {
+	return graphql.ID(strconv.Itoa(id))
+}
+
+// Query
+
+type booksArgs struct {
+	Query  *string
+	First  int32
+	Offset int32
+}
+
+func (r *resolver) Books(ctx context.Context, args booksArgs) (*bookPageResolver, error) {
+	if args.First <= 0 || args.First > maxPageSize {
+		return nil, errors.New("first debe estar entre 1 y " + strconv.Itoa(maxPageSize))
+	}
+	var expr query.Expr
+	if args.Query != nil && strings.TrimSpace(*args.Query) != "" {
+		var err error
+		if expr, err = query.Parse(*args.Query); err != nil {
+			return nil, err
+		}
+	}
+	books, total, err := r.books.ListBooks(ctx, expr, false, int(args.First), int(args.Offset))
+	if err != nil {
+		return nil, err
+	}
+	return &bookPageResolver{total: total, books: bookResolvers(books)}, nil
+}
+
+func (r *resolver) Search(ctx context.Context, args struct{ Term string }) ([]*bookResolver, error) {
+	books, err := r.books.SearchBookByTitleOrAuthor(ctx, args.Term)
+	if err != nil {
+		return nil, err
+	}
+	return bookResolvers(books), nil
+}
+
+func (r *resolver) Book(ctx context.Context, args struct{ ID graphql.ID }) (*bookResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	book, err := loadersFrom(ctx).bookByID.Load(ctx, id)
+	if err != nil || book == nil {
+		return nil, err
+	}
+	return &bookResolver{book}, nil
+}
+
+func (r *resolver) Authors(ctx context.Context) ([]*authorResolver, error) {
+	facets, err := r.books.BookAuthors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	authors := make([]*authorResolver, len(facets))
+	for i, f := range facets {
+		authors[i] = &authorResolver{name: f.Name}
+	}
+	return authors, nil
+}
+
+func (r *resolver) Author(ctx context.Context, args struct{ Name string }) (*authorResolver, error) {
+	books, err := loadersFrom(ctx).booksByAuthor.Load(ctx, args.Name)
+	if err != nil || len(books) == 0 {
+		return nil, err
+	}
+	return &authorResolver{name: args.Name}, nil
+}
+
+func (r *resolver) Tags(ctx context.Context) ([]*tagResolver, error) {
+	facets, err := r.books.BookTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]*tagResolver, len(facets))
+	for i, f := range facets {
+		tags[i] = &tagResolver{name: f.Name, count: f.Count}
+	}
+	return tags, nil
+}
+
+func (r *resolver) Me(ctx context.Context) (*userResolver, error) {
+	id := reqctx.UserID(ctx)
+	if id == 0 {
+		return nil, nil
+	}
+	user, err := r.users.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{user}, nil
+}
+
+func (r *resolver) Users(ctx context.Context) ([]*userResolver, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+	users, err := r.users.GetAllUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*userResolver, len(users))
+	for i, u := range users {
+		out[i] = &userResolver{u}
+	}
+	return out, nil
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSelfOrAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+	user, err := r.users.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{user}, nil
+}
+
+// Mutation
+
+type bookInput struct {
+	Title        string
+	Author       string
+	Year         *int32
+	Tags         *[]string
+	ISBN         *string
+	Publisher    *string
+	Contributors *[]contributorInput
+	Prices       *[]priceInput
+}
+
+type contributorInput struct {
+	Name string
+	Role string
+}
+
+type priceInput struct {
+	Amount   float64
+	Currency string
+	Type     *string
+}
+
+// book arma el libro del input; los campos opcionales ausentes quedan vacíos
+func (in bookInput) book() *model.Book {
+	b := &model.Book{Titulo: in.Title, Autor: in.Author}
+	if in.Year != nil {
+		b.Anio = int(*in.Year)
+	}
+	if in.Tags != nil {
+		b.Etiquetas = *in.Tags
+	}
+	if in.ISBN != nil {
+		b.ISBN = *in.ISBN
+	}
+	if in.Publisher != nil {
+		b.Editorial = *in.Publisher
+	}
+	if in.Contributors != nil {
+		for _, c := range *in.Contributors {
+			b.Colaboradores = append(b.Colaboradores, model.Contributor{Nombre: c.Name, Rol: c.Role})
+		}
+	}
+	if in.Prices != nil {
+		for _, p := range *in.Prices {
+			price := model.Price{Monto: p.Amount, Moneda: p.Currency}
+			if p.Type != nil {
+				price.Tipo = *p.Type
+			}
+			b.Precios = append(b.Precios, price)
+		}
+	}
+	return b
+}
+
+func (r *resolver) CreateBook(ctx context.Context, args struct{ Input bookInput }) (*bookResolver, error) {
+	if err := requireRole(ctx, ""); err != nil {
+		return nil, err
+	}
+	book, err := r.books.CreateBook(ctx, args.Input.book())
+	if err != nil {
+		return nil, err
+	}
+	return &bookResolver{book}, nil
+}
+
+func (r *resolver) UpdateBook(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version int32
+	Input   bookInput
+}) (*bookResolver, error) {
+	if err := requireRole(ctx, ""); err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	input := args.Input.book()
+	input.Version = int(args.Version)
+	book, err := r.books.UpdateBook(ctx, id, input)
+	if err != nil {
+		return nil, err
+	}
+	return &bookResolver{book}, nil
+}
+
+func (r *resolver) DeleteBook(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version int32
+}) (bool, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return false, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+	if err := r.books.DeleteBook(ctx, id, int(args.Version)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type registerInput struct {
+	Username string
+	Email    string
+	Password string
+}
+
+func (r *resolver) Register(ctx context.Context, args struct{ Input registerInput }) (*userResolver, error) {
+	user, err := r.users.Register(ctx, &model.User{
+		Username: args.Input.Username,
+		Email:    args.Input.Email,
+		Password: args.Input.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{user}, nil
+}
+
+type userInput struct {
+	Username *string
+	Email    *string
+	Password *string
+}
+
+func (r *resolver) UpdateUser(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version int32
+	Input   userInput
+}) (*userResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSelfOrAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+	data := &model.User{Version: int(args.Version)}
+	if args.Input.Username != nil {
+		data.Username = *args.Input.Username
+	}
+	if args.Input.Email != nil {
+		data.Email = *args.Input.Email
+	}
+	if args.Input.Password != nil {
+		data.Password = *args.Input.Password
+	}
+	user, err := r.users.UpdateUser(ctx, id, data)
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{user}, nil
+}
+
+func (r *resolver) DeleteUser(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version int32
+}) (bool, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return false, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+	if err := r.users.DeleteUser(ctx, id, int(args.Version)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Tipos
+
+type bookPageResolver struct {
+	total int
+	books []*bookResolver
+}
+
+func (p *bookPageResolver) Total() int32           { return int32(p.total) }
+func (p *bookPageResolver) Books() []*bookResolver { return p.books }
+
+type bookResolver struct{ b *model.Book }
+
+func bookResolvers(books []*model.Book) []*bookResolver {
+	out := make([]*bookResolver, len(books))
+	for i, b := range books {
+		out[i] = &bookResolver{b}
+	}
+	return out
+}
+
+func (r *bookResolver) ID() graphql.ID          { return formatID(r.b.ID) }
+func (r *bookResolver) Title() string           { return r.b.Titulo }
+func (r *bookResolver) Author() *authorResolver { return &authorResolver{name: r.b.Autor} }
+func (r *bookResolver) Year() *int32            { return optionalInt(r.b.Anio) }
+func (r *bookResolver) Tags() []string          { return nonNil(r.b.Etiquetas) }
+func (r *bookResolver) ISBN() *string           { return optionalString(r.b.ISBN) }
+func (r *bookResolver) Publisher() *string      { return optionalString(r.b.Editorial) }
+func (r *bookResolver) Version() int32          { return int32(r.b.Version) }
+
+func (r *bookResolver) Contributors() []*contributorResolver {
+	out := make([]*contributorResolver, len(r.b.Colaboradores))
+	for i := range r.b.Colaboradores {
+		out[i] = &contributorResolver{&r.b.Colaboradores[i]}
+	}
+	return out
+}
+
+func (r *bookResolver) Prices() []*priceResolver {
+	out := make([]*priceResolver, len(r.b.Precios))
+	for i := range r.b.Precios {
+		out[i] = &priceResolver{&r.b.Precios[i]}
+	}
+	return out
+}
+
+// authorResolver resuelve los libros del autor con el loader, así una lista
+// de autores (o de libros con su autor) hace una sola consulta por nivel
+type authorResolver struct{ name string }
+
+func (r *authorResolver) Name() string { return r.name }
+
+func (r *authorResolver) Books(ctx context.Context) ([]*bookResolver, error) {
+	books, err := loadersFrom(ctx).booksByAuthor.Load(ctx, r.name)
+	if err != nil {
+		return nil, err
+	}
+	return bookResolvers(books), nil
+}
+
+type tagResolver struct {
+	name  string
+	count int
+}
+
+func (r *tagResolver) Name() string { return r.name }
+func (r *tagResolver) Count() int32 { return int32(r.count) }
+
+type contributorResolver struct{ c *model.Contributor }
+
+func (r *contributorResolver) Name() string { return r.c.Nombre }
+func (r *contributorResolver) Role() string { return r.c.Rol }
+
+type priceResolver struct{ p *model.Price }
+
+func (r *priceResolver) Amount() float64  { return r.p.Monto }
+func (r *priceResolver) Currency() string { return r.p.Moneda }
+func (r *priceResolver) Type() *string    { return optionalString(r.p.Tipo) }
+
+// userResolver no tiene campo para la contraseña: el esquema no la expone
+type userResolver struct{ u *model.User }
+
+func (r *userResolver) ID() graphql.ID   { return formatID(r.u.ID) }
+func (r *userResolver) Username() string { return r.u.Username }
+func (r *userResolver) Role() string     { return r.u.Role }
+func (r *userResolver) Version() int32   { return int32(r.u.Version) }
+
+func (r *userResolver) Email(ctx context.Context) (*string, error) {
+	if err := requireSelfOrAdmin(ctx, r.u.ID); err != nil {
+		return nil, err
+	}
+	return &r.u.Email, nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalInt(n int) *int32 {
+	if n == 0 {
+		return nil
+	}
+	v := int32(n)
+	return &v
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"context"
+	"practica-go/internal/model"
+	"practica-go/internal/query"
+	"practica-go/internal/service"
+	"practica-go/internal/transport/grpc/pb"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type bookServer struct {
+	pb.UnimplementedBookServiceServer
+	service *service.BookService
+}
+
+// ListBooks envía los libros a medida que se leen de la base
+func (s *bookServer) ListBooks(req *pb.ListBooksRequest, stream grpc.ServerStreamingServer[pb.Book]) error {
+	var expr query.Expr
+	if q := strings.TrimSpace(req.GetQuery()); q != "" {
+		var err error
+		if expr, err = query.Parse(q); err != nil {
+			return toStatus(err, codes.InvalidArgument)
+		}
+	}
+	err := s.service.ExportBooks(stream.Context(), expr, func(b *model.Book) error {
+		return stream.Send(bookToPB(b))
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err // error de Send: ya es un status
+		}
+		return toStatus(err, codes.Internal)
+	}
+	return nil
+}
+
+func (s *bookServer) SearchBooks(ctx context.Context, req *pb.SearchBooksRequest) (*pb.SearchBooksResponse, error) {
+	books, err := s.service.SearchBookByTitleOrAuthor(ctx, req.GetTerm())
+	if err != nil {
+		return nil, toStatus(err, codes.InvalidArgument)
+	}
+	resp := &pb.SearchBooksResponse{Books: make([]*pb.Book, len(books))}
+	for i, b := range books {
+		resp.Books[i] = bookToPB(b)
+	}
+	return resp, nil
+}
+
+func (s *bookServer) GetBook(ctx context.Context, req *pb.GetBookRequest) (*pb.Book, error) {
+	book, err := s.service.GetBookByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err, codes.NotFound)
+	}
+	return bookToPB(book), nil
+}
+
+func (s *bookServer) CreateBook(ctx context.Context, req *pb.CreateBookRequest) (*pb.Book, error) {
+	if req.GetBook() == nil {
+		return nil, status.Error(codes.InvalidArgument, "falta el libro")
+	}
+	created, err := s.service.CreateBook(ctx, bookFromPB(req.GetBook()))
+	if err != nil {
+		return nil, toStatus(err, codes.InvalidArgument)
+	}
+	return bookToPB(created), nil
+}
+
+func (s *bookServer) UpdateBook(ctx context.Context, req *pb.UpdateBookRequest) (*pb.Book, error) {
+	if req.GetBook() == nil {
+		return nil, status.Error(codes.InvalidArgument, "falta el libro")
+	}
+	book := bookFromPB(req.GetBook())
+	book.Version = int(req.GetVersion())
+	updated, err := s.service.UpdateBook(ctx, int(req.GetId()), book)
+	if err != nil {
+		return nil, toStatus(err, codes.InvalidArgument)
+	}
+	return bookToPB(updated), nil
+}
+
+func (s *bookServer) DeleteBook(ctx context.Context, req *pb.DeleteBookRequest) (*pb.DeleteBookResponse, error) {
+	if err := s.service.DeleteBook(ctx, int(req.GetId()), int(req.GetVersion())); err != nil {
+		return nil, toStatus(err, codes.InvalidArgument)
+	}
+	return &pb.DeleteBookResponse{}, nil
+}
+
+func bookToPB(b *model.Book) *pb.Book {
+	out := &pb.Book{
+		Id:        int64(b.ID),
+		Title:     b.Titulo,
+		Author:    b.Autor,
+		Year:      int32(b.Anio),
+		Tags:      b.Etiquetas,
+		Isbn:      b.ISBN,
+		Publisher: b.Editorial,
+		Version:   int64(b.Version),
+	}
+	for _, c := range b.Colaboradores {
+		out.Contributors = append(out.Contributors, &pb.Contributor{Name: c.Nombre, Role: c.Rol})
+	}
+	for _, p := range b.Precios {
+		out.Prices = append(out.Prices, &pb.Price{Amount: p.Monto, Currency: p.Moneda, Type: p.Tipo})
+	}
+	return out
+}
+
+// bookFromPB ignora el ID y la versión del mensaje: los decide la base o el
+// request
+func bookFromPB(b *pb.Book) *model.Book {
+	out := &model.Book{
+		Titulo:    b.GetTitle(),
+		Autor:     b.GetAuthor(),
+		Anio:      int(b.GetYear()),
+		Etiquetas: b.GetTags(),
+		ISBN:      b.GetIsbn(),
+		Editorial: b.GetPublisher(),
+	}
+	for _, c := range b.GetContributors() {
+		out.Colaboradores = append(out.Colaboradores, model.Contributor{Nombre: c.GetName(), Rol: c.GetRole()})
+	}
+	for _, p := range b.GetPrices() {
+		out.Precios = append(out.Precios, model.Price{Monto: p.GetAmount(), Moneda: p.GetCurrency(), Tipo: p.GetType()})
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package grpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"practica-go/internal/reqctx"
+	"practica-go/internal/security"
+	"practica-go/internal/transport/grpc/pb"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authenticated marca en methodRoles los métodos que solo piden estar
+// autenticado, sin un rol en particular
+const authenticated = ""
+
+// methodRoles indica qué rol exige cada método. Los que no están son públicos;
+// los que comparan contra el usuario del token (GetUser, UpdateUser) lo
+// revisan en el handler.
+var methodRoles = map[string]string{
+	pb.BookService_CreateBook_FullMethodName: authenticated,
+	pb.BookService_UpdateBook_FullMethodName: authenticated,
+	pb.BookService_DeleteBook_FullMethodName: "admin",
+	pb.UserService_GetUser_FullMethodName:    authenticated,
+	pb.UserService_UpdateUser_FullMethodName: authenticated,
+	pb.UserService_ListUsers_FullMethodName:  "admin",
+	pb.UserService_DeleteUser_FullMethodName: "admin",
+}
+
+// interceptors hace con gRPC lo mismo que los middlewares HTTP RequestID,
+// Auth, RequireRole y Logging
+type interceptors struct {
+	tokens *security.TokenIssuer
+	log    *slog.Logger
+}
+
+func (i *interceptors) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	ctx, err := i.authenticate(ctx, info.FullMethod)
+	var resp any
+	if err == nil {
+		resp, err = handler(ctx, req)
+	}
+	i.logCall(ctx, info.FullMethod, err, start)
+	return resp, err
+}
+
+func (i *interceptors) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+	if err == nil {
+		err = handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+	i.logCall(ctx, info.FullMethod, err, start)
+	return err
+}
+
+// authenticate arma el reqctx.Info de la llamada con el ID de la petición
+// (metadata x-request-id) y el usuario del token (metadata authorization),
+// y verifica el rol que exige el método
+func (i *interceptors) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	id := first(md, "x-request-id")
+	if id == "" || len(id) > 128 {
+		id = newRequestID()
+	}
+	ctx, info := reqctx.New(ctx, id)
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+
+	if header := first(md, "authorization"); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			return ctx, status.Error(codes.Unauthenticated, "cabecera authorization inválida")
+		}
+		claims, err := i.tokens.Verify(token, security.TokenAccess)
+		if err != nil {
+			return ctx, status.Error(codes.Unauthenticated, err.Error())
+		}
+		info.UserID = claims.Subject
+		info.Role = claims.Role
+	}
+
+	role, restricted := methodRoles[method]
+	if !restricted {
+		return ctx, nil
+	}
+	if info.UserID == 0 {
+		return ctx, status.Error(codes.Unauthenticated, "se requiere autenticación")
+	}
+	if role != authenticated && info.Role != role {
+		return ctx, status.Error(codes.PermissionDenied, "no tenés permisos para esta operación")
+	}
+	return ctx, nil
+}
+
+func (i *interceptors) logCall(ctx context.Context, method string, err error, start time.Time) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	if code == codes.Internal || code == codes.Unknown {
+		level = slog.LevelError
+	}
+	i.log.LogAttrs(ctx, level, "llamada gRPC",
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
+
+// contextStream reemplaza el context de un ServerStream por el que armó el
+// interceptor
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,9 @@
+// Package pb tiene los mensajes y stubs gRPC generados desde bookstore.proto.
+// Después de editar el .proto se regeneran con:
+//
+//	go generate ./internal/transport/grpc/pb
+//
+// (requiere protoc, protoc-gen-go y protoc-gen-go-grpc en el PATH)
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative bookstore.proto
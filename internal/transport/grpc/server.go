@@ -0,0 +1,49 @@
+// Package grpc expone BookService y UserService por gRPC para consumidores
+// internos. Usa los mismos services que la API HTTP; los mensajes están en
+// pb/bookstore.proto.
+package grpc
+
+import (
+	"errors"
+	"log/slog"
+	"practica-go/internal/query"
+	"practica-go/internal/security"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"practica-go/internal/transport/grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer arma el servidor gRPC con los dos servicios, los interceptores de
+// autenticación y la reflexión habilitada para poder explorarlo con
+// herramientas como grpcurl.
+func NewServer(books *service.BookService, users *service.UserService, tokens *security.TokenIssuer, log *slog.Logger) *grpc.Server {
+	i := &interceptors{tokens: tokens, log: log}
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(i.unary),
+		grpc.StreamInterceptor(i.stream),
+	)
+	pb.RegisterBookServiceServer(srv, &bookServer{service: books})
+	pb.RegisterUserServiceServer(srv, &userServer{service: users})
+	reflection.Register(srv)
+	return srv
+}
+
+// toStatus convierte un error de los services en un status gRPC. Los
+// services no distinguen tipos de error, así que el handler indica el código
+// igual que la API HTTP elige el status; los errores de consulta siempre son
+// InvalidArgument y los conflictos de versión, Aborted.
+func toStatus(err error, code codes.Code) error {
+	var qerr *query.Error
+	if errors.As(err, &qerr) {
+		return status.Error(codes.InvalidArgument, qerr.Error())
+	}
+	if errors.Is(err, store.ErrVersionConflict) {
+		return status.Error(codes.Aborted, err.Error())
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,368 @@
+package grpc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"practica-go/internal/model"
+	"practica-go/internal/security"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"practica-go/internal/transport/grpc/pb"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	_ "modernc.org/sqlite"
+)
+
+// testServer es el servidor gRPC completo (interceptores, services y una
+// base SQLite migrada) detrás de una conexión en memoria
+type testServer struct {
+	conn        *grpc.ClientConn
+	books       pb.BookServiceClient
+	users       pb.UserServiceClient
+	tokens      *security.TokenIssuer
+	bookService *service.BookService
+	userService *service.UserService
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	if err := security.SetBcryptCost(bcrypt.MinCost); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st := store.New(db)
+	if _, err := st.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := security.NewTokenIssuer("0123456789abcdef0123456789abcdef", time.Minute, time.Hour)
+	books := service.NewBook(*st)
+	users := service.NewUser(*st, tokens)
+	srv := NewServer(books, users, tokens, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &testServer{
+		conn:        conn,
+		books:       pb.NewBookServiceClient(conn),
+		users:       pb.NewUserServiceClient(conn),
+		tokens:      tokens,
+		bookService: books,
+		userService: users,
+	}
+}
+
+// as devuelve un context con el token de acceso de un usuario con ese rol
+func (s *testServer) as(t *testing.T, userID int, role string) context.Context {
+	t.Helper()
+	pair, err := s.tokens.Issue(userID, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+pair.AccessToken)
+}
+
+func wantCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("código = %s (%v), se esperaba %s", got, err, want)
+	}
+}
+
+// Todos los métodos de methodRoles rechazan las llamadas sin token
+func TestInterceptorUnauthenticated(t *testing.T) {
+	s := newTestServer(t)
+	for method := range methodRoles {
+		t.Run(method, func(t *testing.T) {
+			err := s.conn.Invoke(context.Background(), method, &emptypb.Empty{}, &emptypb.Empty{})
+			wantCode(t, err, codes.Unauthenticated)
+		})
+	}
+}
+
+func TestInterceptorInvalidToken(t *testing.T) {
+	s := newTestServer(t)
+	pair, err := s.tokens.Issue(1, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, header := range map[string]string{
+		"sin Bearer":             pair.AccessToken,
+		"token mal formado":      "Bearer no-es-un-jwt",
+		"token de refresco":      "Bearer " + pair.RefreshToken,
+		"firmado con otra clave": "Bearer " + mustIssue(t, security.NewTokenIssuer("otra-clave-de-al-menos-32-caracteres", time.Minute, time.Hour)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", header)
+			// Un token inválido se rechaza aunque el método sea público
+			_, err := s.books.GetBook(ctx, &pb.GetBookRequest{Id: 1})
+			wantCode(t, err, codes.Unauthenticated)
+		})
+	}
+}
+
+func mustIssue(t *testing.T, tokens *security.TokenIssuer) string {
+	t.Helper()
+	pair, err := tokens.Issue(1, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair.AccessToken
+}
+
+// Los métodos que exigen un rol devuelven PermissionDenied a los demás roles
+// y dejan pasar al rol correcto hasta el handler
+func TestInterceptorPermissionDenied(t *testing.T) {
+	s := newTestServer(t)
+	for method, role := range methodRoles {
+		t.Run(method, func(t *testing.T) {
+			err := s.conn.Invoke(s.as(t, 99, "user"), method, &emptypb.Empty{}, &emptypb.Empty{})
+			if role == authenticated {
+				// Pasa el interceptor: lo que falle después lo decide el handler
+				if status.Code(err) == codes.Unauthenticated {
+					t.Fatalf("un usuario autenticado fue rechazado: %v", err)
+				}
+				return
+			}
+			wantCode(t, err, codes.PermissionDenied)
+
+			err = s.conn.Invoke(s.as(t, 99, role), method, &emptypb.Empty{}, &emptypb.Empty{})
+			if c := status.Code(err); c == codes.Unauthenticated || c == codes.PermissionDenied {
+				t.Fatalf("el rol %s fue rechazado: %v", role, err)
+			}
+		})
+	}
+}
+
+func TestInterceptorRequestID(t *testing.T) {
+	s := newTestServer(t)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-123")
+	var header metadata.MD
+	if _, err := s.books.SearchBooks(ctx, &pb.SearchBooksRequest{Term: "nada"}, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-123" {
+		t.Errorf("x-request-id = %v, se esperaba req-123", got)
+	}
+}
+
+func TestListBooksStream(t *testing.T) {
+	s := newTestServer(t)
+	for _, b := range []*model.Book{
+		{Titulo: "Ficciones", Autor: "Jorge Luis Borges", Etiquetas: []string{"cuentos"}},
+		{Titulo: "Rayuela", Autor: "Julio Cortázar", Etiquetas: []string{"novela"}},
+		{Titulo: "El Aleph", Autor: "Jorge Luis Borges", Etiquetas: []string{"cuentos"}},
+	} {
+		if _, err := s.bookService.CreateBook(context.Background(), b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Ficciones", "Rayuela", "El Aleph"}},
+		{"tag:cuentos", []string{"Ficciones", "El Aleph"}},
+		{"tag:poesia", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			stream, err := s.books.ListBooks(context.Background(), &pb.ListBooksRequest{Query: tt.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for {
+				b, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, b.GetTitle())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("títulos = %v, se esperaba %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("títulos = %v, se esperaba %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// Una consulta inválida llega como error del stream, no como stream vacío
+	stream, err := s.books.ListBooks(context.Background(), &pb.ListBooksRequest{Query: "year:>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+// Los errores de los services llegan con el código que elige cada handler
+func TestStatusMapping(t *testing.T) {
+	s := newTestServer(t)
+	user, err := s.userService.Register(context.Background(), &model.User{Username: "lector", Email: "lector@example.com", Password: "secreto123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.userService.Register(context.Background(), &model.User{Username: "otro", Email: "otro@example.com", Password: "secreto123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asUser := s.as(t, user.ID, "user")
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"libro inexistente", func() error {
+			_, err := s.books.GetBook(context.Background(), &pb.GetBookRequest{Id: 404})
+			return err
+		}, codes.NotFound},
+		{"alta sin libro", func() error {
+			_, err := s.books.CreateBook(asUser, &pb.CreateBookRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"alta inválida", func() error {
+			_, err := s.books.CreateBook(asUser, &pb.CreateBookRequest{Book: &pb.Book{Title: "x"}})
+			return err
+		}, codes.InvalidArgument},
+		{"alta válida", func() error {
+			_, err := s.books.CreateBook(asUser, &pb.CreateBookRequest{Book: &pb.Book{Title: "Ficciones", Author: "Jorge Luis Borges"}})
+			return err
+		}, codes.OK},
+		{"login con clave incorrecta", func() error {
+			_, err := s.users.Login(context.Background(), &pb.LoginRequest{User: "lector", Password: "incorrecta"})
+			return err
+		}, codes.Unauthenticated},
+		{"refresco inválido", func() error {
+			_, err := s.users.RefreshTokens(context.Background(), &pb.RefreshTokensRequest{RefreshToken: "no-es-un-jwt"})
+			return err
+		}, codes.Unauthenticated},
+		{"registro duplicado", func() error {
+			_, err := s.users.Register(context.Background(), &pb.RegisterRequest{Username: "lector", Email: "otro2@example.com", Password: "secreto123"})
+			return err
+		}, codes.InvalidArgument},
+		{"usuario propio", func() error {
+			_, err := s.users.GetUser(asUser, &pb.GetUserRequest{Id: int64(user.ID)})
+			return err
+		}, codes.OK},
+		{"usuario ajeno", func() error {
+			_, err := s.users.GetUser(asUser, &pb.GetUserRequest{Id: int64(other.ID)})
+			return err
+		}, codes.PermissionDenied},
+		{"usuario inexistente como admin", func() error {
+			_, err := s.users.GetUser(s.as(t, 1, "admin"), &pb.GetUserRequest{Id: 404})
+			return err
+		}, codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantCode(t, tt.call(), tt.want)
+		})
+	}
+}
+
+// Las escrituras sobre un registro existente exigen la versión leída: 0 es
+// un error del cliente, una vieja es un conflicto y -1 saltea el control
+func TestVersionRequired(t *testing.T) {
+	s := newTestServer(t)
+	user, err := s.userService.Register(context.Background(), &model.User{Username: "lector", Email: "lector@example.com", Password: "secreto123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asUser := s.as(t, user.ID, "user")
+	asAdmin := s.as(t, 1, "admin")
+
+	created, err := s.books.CreateBook(asUser, &pb.CreateBookRequest{Book: &pb.Book{Title: "Ficciones", Author: "Jorge Luis Borges"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.GetVersion() != 1 {
+		t.Fatalf("versión = %d, se esperaba 1", created.GetVersion())
+	}
+	update := func(version int64, title string) (*pb.Book, error) {
+		return s.books.UpdateBook(asUser, &pb.UpdateBookRequest{
+			Id:      created.GetId(),
+			Version: version,
+			Book:    &pb.Book{Title: title, Author: "Jorge Luis Borges"},
+		})
+	}
+
+	_, err = update(0, "Sin versión")
+	wantCode(t, err, codes.InvalidArgument)
+
+	updated, err := update(created.GetVersion(), "Ficciones (1944)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GetVersion() != 2 {
+		t.Fatalf("versión = %d, se esperaba 2", updated.GetVersion())
+	}
+
+	_, err = update(created.GetVersion(), "Versión vieja")
+	wantCode(t, err, codes.Aborted)
+
+	forced, err := update(store.AnyVersion, "Ficciones")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forced.GetVersion() != 3 {
+		t.Fatalf("versión = %d, se esperaba 3", forced.GetVersion())
+	}
+
+	_, err = s.books.DeleteBook(asAdmin, &pb.DeleteBookRequest{Id: created.GetId()})
+	wantCode(t, err, codes.InvalidArgument)
+	_, err = s.books.DeleteBook(asAdmin, &pb.DeleteBookRequest{Id: created.GetId(), Version: updated.GetVersion()})
+	wantCode(t, err, codes.Aborted)
+	_, err = s.books.DeleteBook(asAdmin, &pb.DeleteBookRequest{Id: created.GetId(), Version: forced.GetVersion()})
+	wantCode(t, err, codes.OK)
+
+	_, err = s.users.UpdateUser(asUser, &pb.UpdateUserRequest{Id: int64(user.ID), Username: "lectora"})
+	wantCode(t, err, codes.InvalidArgument)
+	renamed, err := s.users.UpdateUser(asUser, &pb.UpdateUserRequest{Id: int64(user.ID), Version: int64(user.Version), Username: "lectora"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.users.DeleteUser(asAdmin, &pb.DeleteUserRequest{Id: int64(user.ID), Version: int64(user.Version)})
+	wantCode(t, err, codes.Aborted)
+	_, err = s.users.DeleteUser(asAdmin, &pb.DeleteUserRequest{Id: int64(user.ID), Version: renamed.GetVersion()})
+	wantCode(t, err, codes.OK)
+}
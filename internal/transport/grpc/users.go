@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"practica-go/internal/model"
+	"practica-go/internal/reqctx"
+	"practica-go/internal/security"
+	"practica-go/internal/service"
+	"practica-go/internal/transport/grpc/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	service *service.UserService
+}
+
+func (s *userServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	user, err := s.service.Login(ctx, req.GetUser(), req.GetPassword())
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "credenciales inválidas")
+	}
+	tokens, err := s.service.IssueTokens(user)
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return &pb.LoginResponse{User: userToPB(user), Tokens: tokensToPB(tokens)}, nil
+}
+
+func (s *userServer) RefreshTokens(ctx context.Context, req *pb.RefreshTokensRequest) (*pb.TokenPair, error) {
+	tokens, err := s.service.RefreshTokens(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, toStatus(err, codes.Unauthenticated)
+	}
+	return tokensToPB(tokens), nil
+}
+
+func (s *userServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.User, error) {
+	created, err := s.service.Register(ctx, &model.User{
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, toStatus(err, codes.InvalidArgument)
+	}
+	return userToPB(created), nil
+}
+
+func (s *userServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	if err := selfOrAdmin(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+	user, err := s.service.GetUserByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err, codes.NotFound)
+	}
+	return userToPB(user), nil
+}
+
+func (s *userServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	users, err := s.service.GetAllUser(ctx)
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	resp := &pb.ListUsersResponse{Users: make([]*pb.User, len(users))}
+	for i, u := range users {
+		resp.Users[i] = userToPB(u)
+	}
+	return resp, nil
+}
+
+func (s *userServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	if err := selfOrAdmin(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+	updated, err := s.service.UpdateUser(ctx, int(req.GetId()), &model.User{
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Version:  int(req.GetVersion()),
+	})
+	if err != nil {
+		return nil, toStatus(err, codes.InvalidArgument)
+	}
+	return userToPB(updated), nil
+}
+
+func (s *userServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	if err := s.service.DeleteUser(ctx, int(req.GetId()), int(req.GetVersion())); err != nil {
+		return nil, toStatus(err, codes.InvalidArgument)
+	}
+	return &pb.DeleteUserResponse{}, nil
+}
+
+// selfOrAdmin deja pasar al propio usuario o a un admin. La autenticación ya
+// la verificó el interceptor.
+func selfOrAdmin(ctx context.Context, id int64) error {
+	if int64(reqctx.UserID(ctx)) != id && reqctx.Role(ctx) != "admin" {
+		return status.Error(codes.PermissionDenied, "no tenés permisos para esta operación")
+	}
+	return nil
+}
+
+func userToPB(u *model.User) *pb.User {
+	return &pb.User{Id: int64(u.ID), Username: u.Username, Email: u.Email, Role: u.Role, Version: int64(u.Version)}
+}
+
+func tokensToPB(t *security.TokenPair) *pb.TokenPair {
+	return &pb.TokenPair{AccessToken: t.AccessToken, RefreshToken: t.RefreshToken, ExpiresIn: int32(t.ExpiresIn)}
+}
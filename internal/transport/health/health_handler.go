@@ -0,0 +1,38 @@
+package health
+
+import (
+	"net/http"
+	"practica-go/internal/health"
+	"practica-go/internal/transport"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func New(c *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: c}
+}
+
+// Liveness: el proceso está vivo y puede responder peticiones
+func (h *HealthHandler) HandleLive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+// Readiness: las dependencias están sanas y el servidor no se está apagando
+func (h *HealthHandler) HandleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+	report := h.checker.Ready(r.Context())
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	transport.WriteJSON(w, status, report)
+}
@@ -0,0 +1,192 @@
+package opds
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"practica-go/internal/opds"
+	"practica-go/internal/query"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"practica-go/internal/transport"
+	"strconv"
+	"strings"
+)
+
+// pageSize es la cantidad de entradas por página de cada feed
+const pageSize = 20
+
+// maxPage es la última página cuyo desplazamiento, (page-1)*pageSize, entra
+// en un int
+const maxPage = math.MaxInt / pageSize
+
+// catalogTitle es el nombre del catálogo en los feeds y en OpenSearch
+const catalogTitle = "Books-Store"
+
+type OPDSHandler struct {
+	service *service.BookService
+}
+
+func New(s *service.BookService) *OPDSHandler {
+	return &OPDSHandler{service: s}
+}
+
+// Catálogo OPDS: /opds/... en OPDS 1.2 (Atom) y /opds/v2/... en OPDS 2.0 (JSON)
+//
+//	/opds                 navegación principal
+//	/opds/new             novedades
+//	/opds/authors[/X]     autores / libros del autor X
+//	/opds/tags[/X]        categorías / libros de la categoría X
+//	/opds/search?q=       búsqueda por título o autor
+//	/opds/opensearch.xml  descripción OpenSearch
+func (h *OPDSHandler) HandleOPDS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.EscapedPath(), "/opds"), "/")
+	asJSON := path == "v2" || strings.HasPrefix(path, "v2/")
+	if asJSON {
+		path = strings.Trim(strings.TrimPrefix(path, "v2"), "/")
+	}
+	section, arg, _ := strings.Cut(path, "/")
+	arg, err := url.PathUnescape(arg)
+	if err != nil {
+		transport.WriteError(w, http.StatusBadRequest, "ruta inválida")
+		return
+	}
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if page, err = strconv.Atoi(p); err != nil || page < 1 {
+			transport.WriteError(w, http.StatusBadRequest, "page debe ser un número positivo")
+			return
+		}
+		if page > maxPage {
+			transport.WriteError(w, http.StatusBadRequest, fmt.Sprintf("page no puede ser mayor a %d", maxPage))
+			return
+		}
+	}
+
+	catalog := opds.Catalog{Title: catalogTitle, Base: baseURL(r)}
+	var feed *opds.Feed
+
+	switch {
+	case section == "" && arg == "":
+		feed = rootFeed()
+	case section == "opensearch.xml" && !asJSON:
+		w.Header().Set("Content-Type", opds.TypeOpenSearch)
+		catalog.WriteOpenSearch(w)
+		return
+	case section == "new" && arg == "":
+		feed, err = h.booksFeed(r, "Novedades", "new", nil, true, page)
+	case section == "authors" && arg == "":
+		feed, err = h.facetFeed(r, "Autores", "authors", h.service.BookAuthors, page)
+	case section == "authors":
+		feed, err = h.booksFeed(r, arg, "authors/"+opds.PathSegment(arg), &query.Term{Field: query.FieldAuthor, Value: arg}, false, page)
+	case section == "tags" && arg == "":
+		feed, err = h.facetFeed(r, "Categorías", "tags", h.service.BookTags, page)
+	case section == "tags":
+		feed, err = h.booksFeed(r, arg, "tags/"+opds.PathSegment(arg), &query.Term{Field: query.FieldTag, Value: arg}, false, page)
+	case section == "search" && arg == "":
+		feed, err = h.searchFeed(r, page)
+	default:
+		transport.WriteError(w, http.StatusNotFound, "no existe ese feed")
+		return
+	}
+	if err != nil {
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if asJSON {
+		w.Header().Set("Content-Type", opds.TypeJSON)
+		catalog.WriteJSON(w, feed)
+		return
+	}
+	w.Header().Set("Content-Type", feed.Kind.AtomType())
+	catalog.WriteAtom(w, feed)
+}
+
+// rootFeed es la navegación principal del catálogo
+func rootFeed() *opds.Feed {
+	return &opds.Feed{
+		Title: catalogTitle,
+		Kind:  opds.Navigation,
+		Entries: []opds.NavEntry{
+			{Title: "Novedades", Path: "new", Summary: "Los últimos libros agregados", Kind: opds.Acquisition, Rel: opds.RelSortNew},
+			{Title: "Autores", Path: "authors", Summary: "Libros por autor", Kind: opds.Navigation},
+			{Title: "Categorías", Path: "tags", Summary: "Libros por categoría", Kind: opds.Navigation},
+		},
+	}
+}
+
+// booksFeed arma un feed de adquisición paginado con los libros que cumplen expr
+func (h *OPDSHandler) booksFeed(r *http.Request, title, path string, expr query.Expr, newest bool, page int) (*opds.Feed, error) {
+	books, total, err := h.service.ListBooks(r.Context(), expr, newest, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &opds.Feed{
+		Title: title, Kind: opds.Acquisition, Path: path, Books: books,
+		Page: page, PageSize: pageSize, Total: total,
+	}, nil
+}
+
+// facetFeed arma un feed de navegación con un enlace por autor o categoría
+func (h *OPDSHandler) facetFeed(r *http.Request, title, path string, list func(ctx context.Context) ([]store.Facet, error), page int) (*opds.Feed, error) {
+	facets, err := list(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	feed := &opds.Feed{Title: title, Kind: opds.Navigation, Path: path, Page: page, PageSize: pageSize, Total: len(facets)}
+	for _, f := range paginate(facets, page) {
+		feed.Entries = append(feed.Entries, opds.NavEntry{
+			Title:   f.Name,
+			Path:    path + "/" + opds.PathSegment(f.Name),
+			Summary: fmt.Sprintf("%d libros", f.Count),
+			Kind:    opds.Acquisition,
+		})
+	}
+	return feed, nil
+}
+
+// searchFeed usa la misma búsqueda por título o autor que /books/search.
+// OPDS 1.2 manda el término en q y OPDS 2.0 en query.
+func (h *OPDSHandler) searchFeed(r *http.Request, page int) (*opds.Feed, error) {
+	term := r.URL.Query().Get("q")
+	if term == "" {
+		term = r.URL.Query().Get("query")
+	}
+	books, err := h.service.SearchBookByTitleOrAuthor(r.Context(), term)
+	if err != nil {
+		return nil, err
+	}
+	return &opds.Feed{
+		Title: "Resultados para " + term, Kind: opds.Acquisition, Path: "search",
+		Query: url.Values{"q": {term}}, Books: paginate(books, page),
+		Page: page, PageSize: pageSize, Total: len(books),
+	}, nil
+}
+
+// paginate recorta una lista ya cargada a la página pedida
+func paginate[T any](items []T, page int) []T {
+	start := min((page-1)*pageSize, len(items))
+	return items[start:min(start+pageSize, len(items))]
+}
+
+// baseURL reconstruye esquema y host para armar enlaces absolutos,
+// respetando X-Forwarded-Proto si hay un proxy con TLS adelante
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "https" || proto == "http" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
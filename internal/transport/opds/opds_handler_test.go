@@ -0,0 +1,65 @@
+package opds
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"practica-go/internal/model"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"strconv"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestHandler(t *testing.T) *OPDSHandler {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st := store.New(db)
+	if _, err := st.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	books := service.NewBook(*st)
+	if _, err := books.CreateBook(context.Background(), &model.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges", Etiquetas: []string{"cuentos"}}); err != nil {
+		t.Fatal(err)
+	}
+	return New(books)
+}
+
+// Las páginas enormes se rechazan antes de calcular el desplazamiento, que
+// con ellas desbordaría
+func TestOPDSPage(t *testing.T) {
+	h := newTestHandler(t)
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"/opds/authors", http.StatusOK},
+		{"/opds/authors?page=2", http.StatusOK},
+		{"/opds/new?page=" + strconv.Itoa(maxPage), http.StatusOK},
+		{"/opds/search?q=borges&page=" + strconv.Itoa(maxPage), http.StatusOK},
+		{"/opds/v2/tags?page=" + strconv.Itoa(maxPage), http.StatusOK},
+		{"/opds/authors?page=0", http.StatusBadRequest},
+		{"/opds/authors?page=-1", http.StatusBadRequest},
+		{"/opds/authors?page=uno", http.StatusBadRequest},
+		{"/opds/authors?page=461168601842738793", http.StatusBadRequest},
+		{"/opds/new?page=" + strconv.Itoa(maxPage+1), http.StatusBadRequest},
+		{"/opds/tags/cuentos?page=99999999999999999999", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.HandleOPDS(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, se esperaba %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"strings"
 )
 
-func (h *UserHandler) HandleBookExists(w http.ResponseWriter, r *http.Request) {
+func (h *UserHandler) HandleUserExists(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
 		return
@@ -19,7 +19,7 @@ func (h *UserHandler) HandleBookExists(w http.ResponseWriter, r *http.Request) {
 		transport.WriteError(w, http.StatusBadRequest, "id invalido")
 		return
 	}
-	exists, err := h.service.ExistsUser(id)
+	exists, err := h.service.ExistsUser(r.Context(), id)
 	if err != nil {
 		transport.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
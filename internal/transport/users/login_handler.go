@@ -0,0 +1,59 @@
+package users
+
+import (
+	"encoding/json"
+	"net/http"
+	"practica-go/internal/transport"
+)
+
+// loginRequest son las credenciales que envía el cliente
+type loginRequest struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+func (h *UserHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, "input no valido")
+		return
+	}
+	user, err := h.service.Login(r.Context(), req.User, req.Password)
+	if err != nil {
+		transport.WriteError(w, http.StatusUnauthorized, "credenciales inválidas")
+		return
+	}
+	tokens, err := h.service.IssueTokens(user)
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"user": user, "tokens": tokens})
+}
+
+// refreshRequest es el cuerpo de POST /users/refresh
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *UserHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		transport.WriteError(w, http.StatusBadRequest, "input no valido")
+		return
+	}
+	tokens, err := h.service.RefreshTokens(r.Context(), req.RefreshToken)
+	if err != nil {
+		transport.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"tokens": tokens})
+}
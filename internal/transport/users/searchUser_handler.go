@@ -14,7 +14,7 @@ func (h *UserHandler) HandleSearchUsersOrEmail(w http.ResponseWriter, r *http.Re
 			transport.WriteError(w, http.StatusBadRequest, "el término de búsqueda no puede quedar vacío")
 			return
 		}
-		result, err := h.service.SearchUserByUserOrEmail(query)
+		result, err := h.service.SearchUserByUserOrEmail(r.Context(), query)
 		if err != nil {
 			transport.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -2,10 +2,15 @@ package users
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"practica-go/internal/middleware"
 	"practica-go/internal/model"
+	"practica-go/internal/reqctx"
 	"practica-go/internal/service"
+	"practica-go/internal/store"
 	"practica-go/internal/transport"
+	"strconv"
 	"strings"
 )
 
@@ -17,10 +22,14 @@ func NewHandlerUser(s *service.UserService) *UserHandler {
 	return &UserHandler{service: s}
 }
 
-func (h *UserHandler) HandleUsers(w http.ResponseWriter, r http.Request) {
+func (h *UserHandler) HandleUsers(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		users, err := h.service.GetAllUser()
+		svc, ok := h.readService(w, r)
+		if !ok {
+			return
+		}
+		users, err := svc.GetAllUser(r.Context())
 		if err != nil {
 			transport.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -32,7 +41,7 @@ func (h *UserHandler) HandleUsers(w http.ResponseWriter, r http.Request) {
 			transport.WriteError(w, http.StatusBadRequest, "input no valido")
 			return
 		}
-		created, err := h.service.Register(&user)
+		created, err := h.service.Register(r.Context(), &user)
 		if err != nil {
 			transport.WriteError(w, http.StatusBadRequest, err.Error())
 			return
@@ -43,12 +52,31 @@ func (h *UserHandler) HandleUsers(w http.ResponseWriter, r http.Request) {
 	}
 }
 
+// readService devuelve el service para las lecturas: con include_deleted
+// (solo admins) incluye los usuarios borrados
+func (h *UserHandler) readService(w http.ResponseWriter, r *http.Request) (*service.UserService, bool) {
+	include, ok := transport.IncludeDeleted(w, r)
+	if !ok {
+		return nil, false
+	}
+	if include {
+		return h.service.WithDeleted(), true
+	}
+	return h.service, true
+}
+
+// HandleUserByUserOrEmail maneja /users/{userOrEmail}; en el PATCH el
+// segmento es el ID del usuario
 func (h *UserHandler) HandleUserByUserOrEmail(w http.ResponseWriter, r *http.Request) {
 	userStr := strings.TrimPrefix(r.URL.Path, "/users/")
 
 	switch r.Method {
 	case http.MethodGet:
-		user, err := h.service.GetUsersByEmailOrUser(userStr)
+		svc, ok := h.readService(w, r)
+		if !ok {
+			return
+		}
+		user, err := svc.GetUsersByEmailOrUser(r.Context(), userStr)
 		if err != nil {
 			transport.WriteError(w, http.StatusBadRequest, err.Error())
 			return
@@ -57,10 +85,48 @@ func (h *UserHandler) HandleUserByUserOrEmail(w http.ResponseWriter, r *http.Req
 			transport.WriteError(w, http.StatusNotFound, "usuario no encontrado")
 			return
 		}
-
+		w.Header().Set("ETag", transport.ETag(user.Version))
 		transport.WriteJSON(w, http.StatusOK, map[string]any{"user": user})
 
+	case http.MethodPatch:
+		middleware.RequireRole("", func(w http.ResponseWriter, r *http.Request) {
+			h.handlePatch(w, r, userStr)
+		})(w, r)
+
 	default:
 		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
 	}
 }
+
+// handlePatch modifica parcialmente un usuario: el propio o, si es admin,
+// cualquiera
+func (h *UserHandler) handlePatch(w http.ResponseWriter, r *http.Request, idStr string) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		transport.WriteError(w, http.StatusBadRequest, "id inválido")
+		return
+	}
+	if reqctx.UserID(r.Context()) != id && reqctx.Role(r.Context()) != service.RoleAdmin {
+		transport.WriteError(w, http.StatusForbidden, "no tenés permisos para esta operación")
+		return
+	}
+	version, ok := transport.IfMatch(w, r)
+	if !ok {
+		return
+	}
+	mediaType, doc, ok := transport.ReadPatch(w, r)
+	if !ok {
+		return
+	}
+	updated, err := h.service.PatchUser(r.Context(), id, version, mediaType, doc)
+	if err != nil {
+		if errors.Is(err, store.ErrVersionConflict) {
+			transport.WriteError(w, http.StatusPreconditionFailed, err.Error())
+			return
+		}
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("ETag", transport.ETag(updated.Version))
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"user": updated})
+}
@@ -2,7 +2,14 @@ package transport
 
 import (
 	"encoding/json"
+	"io"
+	"mime"
 	"net/http"
+	"practica-go/internal/patch"
+	"practica-go/internal/reqctx"
+	"practica-go/internal/store"
+	"strconv"
+	"strings"
 )
 
 func WriteJSON(w http.ResponseWriter, status int, data any) {
@@ -14,3 +21,74 @@ func WriteJSON(w http.ResponseWriter, status int, data any) {
 func WriteError(w http.ResponseWriter, status int, message string) {
 	WriteJSON(w, status, map[string]string{"error": message})
 }
+
+// IncludeDeleted lee el parámetro include_deleted. Ver los registros borrados
+// es solo para admins: si no corresponde responde el error y ok es false.
+func IncludeDeleted(w http.ResponseWriter, r *http.Request) (include, ok bool) {
+	v := r.URL.Query().Get("include_deleted")
+	if v == "" {
+		return false, true
+	}
+	include, err := strconv.ParseBool(v)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "include_deleted debe ser true o false")
+		return false, false
+	}
+	if !include {
+		return false, true
+	}
+	info := reqctx.From(r.Context())
+	if info == nil || info.UserID == 0 {
+		WriteError(w, http.StatusUnauthorized, "se requiere autenticación")
+		return false, false
+	}
+	if info.Role != "admin" {
+		WriteError(w, http.StatusForbidden, "no tenés permisos para esta operación")
+		return false, false
+	}
+	return true, true
+}
+
+// ETag es la ETag de una versión de un libro o usuario
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// IfMatch lee la versión de la cabecera If-Match, obligatoria en las
+// escrituras de libros y usuarios. "*" acepta cualquier versión y devuelve
+// store.AnyVersion.
+// Si falta o es inválida responde el error y ok es false.
+func IfMatch(w http.ResponseWriter, r *http.Request) (version int, ok bool) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" {
+		WriteError(w, http.StatusPreconditionRequired, "falta la cabecera If-Match con la ETag del recurso")
+		return 0, false
+	}
+	if v == "*" {
+		return store.AnyVersion, true
+	}
+	if len(v) > 2 && strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`) {
+		if version, err := strconv.Atoi(v[1 : len(v)-1]); err == nil && version > 0 {
+			return version, true
+		}
+	}
+	WriteError(w, http.StatusBadRequest, "If-Match inválido: se espera una ETag como \"3\"")
+	return 0, false
+}
+
+// ReadPatch lee el cuerpo de un PATCH y su formato (patch.MergePatch o
+// patch.JSONPatch). Si el Content-Type es otro responde 415 y ok es false.
+func ReadPatch(w http.ResponseWriter, r *http.Request) (mediaType string, doc []byte, ok bool) {
+	mediaType, _, _ = mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != patch.MergePatch && mediaType != patch.JSONPatch {
+		WriteError(w, http.StatusUnsupportedMediaType,
+			"Content-Type no soportado, se espera "+patch.MergePatch+" o "+patch.JSONPatch)
+		return "", nil, false
+	}
+	doc, err := io.ReadAll(r.Body)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "no se pudo leer el cuerpo")
+		return "", nil, false
+	}
+	return mediaType, doc, true
+}
@@ -0,0 +1,120 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"practica-go/internal/model"
+	"practica-go/internal/service"
+	"practica-go/internal/transport"
+	"strconv"
+	"strings"
+)
+
+type WebhookHandler struct {
+	service *service.WebhookService
+}
+
+func New(s *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: s}
+}
+
+// Manejo de todas las suscripciones
+func (h *WebhookHandler) HandleWebhooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		hooks, err := h.service.ListWebhooks(r.Context())
+		if err != nil {
+			transport.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]any{"webhooks": hooks})
+	case http.MethodPost:
+		var hook model.Webhook
+		if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+			transport.WriteError(w, http.StatusBadRequest, "input inválido")
+			return
+		}
+		created, err := h.service.CreateWebhook(r.Context(), &hook)
+		if err != nil {
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// Es la única respuesta que incluye el secreto
+		transport.WriteJSON(w, http.StatusCreated, map[string]any{"webhook": created})
+	default:
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+	}
+}
+
+// HandleWebhookByID atiende /webhooks/{id}, /webhooks/{id}/deliveries y
+// /webhooks/{id}/deliveries/{delivery_id}/redeliver
+func (h *WebhookHandler) HandleWebhookByID(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/webhooks/"), "/")
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id <= 0 {
+		transport.WriteError(w, http.StatusBadRequest, "id inválido")
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		h.handleWebhook(w, r, id)
+	case len(parts) == 2 && parts[1] == "deliveries":
+		h.handleDeliveries(w, r, id)
+	case len(parts) == 4 && parts[1] == "deliveries" && parts[3] == "redeliver":
+		deliveryID, err := strconv.Atoi(parts[2])
+		if err != nil || deliveryID <= 0 {
+			transport.WriteError(w, http.StatusBadRequest, "id de entrega inválido")
+			return
+		}
+		h.handleRedeliver(w, r, id, deliveryID)
+	default:
+		transport.WriteError(w, http.StatusNotFound, "ruta no encontrada")
+	}
+}
+
+func (h *WebhookHandler) handleWebhook(w http.ResponseWriter, r *http.Request, id int) {
+	switch r.Method {
+	case http.MethodGet:
+		hook, err := h.service.GetWebhook(r.Context(), id)
+		if err != nil {
+			transport.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		transport.WriteJSON(w, http.StatusOK, map[string]any{"webhook": hook})
+	case http.MethodDelete:
+		if err := h.service.DeleteWebhook(r.Context(), id); err != nil {
+			transport.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		transport.WriteJSON(w, http.StatusNoContent, map[string]string{"message": "el webhook fue eliminado"})
+	default:
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+	}
+}
+
+func (h *WebhookHandler) handleDeliveries(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+	deliveries, err := h.service.WebhookDeliveries(r.Context(), id)
+	if err != nil {
+		transport.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, map[string]any{"deliveries": deliveries})
+}
+
+func (h *WebhookHandler) handleRedeliver(w http.ResponseWriter, r *http.Request, id, deliveryID int) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "método no permitido")
+		return
+	}
+	delivery, err := h.service.Redeliver(r.Context(), id, deliveryID)
+	if err != nil {
+		transport.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	transport.WriteJSON(w, http.StatusAccepted, map[string]any{"delivery": delivery})
+}
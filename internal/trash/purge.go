@@ -0,0 +1,62 @@
+// Package trash borra definitivamente los libros y usuarios dados de baja.
+// Las bajas son lógicas (deleted_at) para poder restaurarlas; pasado el
+// período de retención las filas se purgan.
+package trash
+
+import (
+	"context"
+	"log/slog"
+	"practica-go/internal/metrics"
+	"practica-go/internal/model"
+	"practica-go/internal/store"
+	"time"
+)
+
+// Purger borra las filas dadas de baja hace más de retention
+type Purger struct {
+	store     *store.Store
+	retention time.Duration
+}
+
+func New(st *store.Store, retention time.Duration) *Purger {
+	return &Purger{store: st, retention: retention}
+}
+
+// Run purga al arrancar y después cada interval hasta que se cancele ctx
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.Purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge borra las filas vencidas de cada entidad. Un error en una no frena
+// la otra: se reintenta en la próxima pasada.
+func (p *Purger) Purge(ctx context.Context) {
+	before := time.Now().Add(-p.retention)
+	purges := []struct {
+		entity string
+		fn     func(context.Context, time.Time) (int, error)
+	}{
+		{model.EntityBook, p.store.BookStorage.Purge},
+		{model.EntityUser, p.store.UserStorage.Purge},
+	}
+	for _, e := range purges {
+		n, err := e.fn(ctx, before)
+		if err != nil {
+			slog.ErrorContext(ctx, "no se pudieron purgar las bajas", slog.String("entity", e.entity), slog.Any("error", err))
+			continue
+		}
+		if n > 0 {
+			metrics.TrashPurged.Add(float64(n), e.entity)
+			slog.InfoContext(ctx, "bajas purgadas", slog.String("entity", e.entity), slog.Int("rows", n))
+		}
+	}
+}
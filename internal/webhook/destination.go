@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// privateAddr indica si addr es de loopback, de una red privada, link-local
+// (incluye el servicio de metadatos de las nubes, 169.254.169.254) o no
+// especificada: destinos a los que un webhook no debería poder apuntar
+func privateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast()
+}
+
+// CheckHost resuelve host y devuelve un error si alguna de sus direcciones
+// es privada o de loopback
+func CheckHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if privateAddr(addr) {
+			return fmt.Errorf("la url apunta a una dirección privada o local (%s)", addr)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("no se pudo resolver el host %s", host)
+	}
+	for _, addr := range addrs {
+		if privateAddr(addr) {
+			return fmt.Errorf("la url apunta a una dirección privada o local (%s resuelve a %s)", host, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// refusePrivate es el Control del dialer del dispatcher: se vuelve a revisar
+// la dirección al conectar porque el DNS puede cambiar después de crear el
+// webhook
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if privateAddr(addrPort.Addr()) {
+		return fmt.Errorf("conexión a una dirección privada o local rechazada (%s)", addrPort.Addr().Unmap())
+	}
+	return nil
+}
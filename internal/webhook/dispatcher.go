@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"practica-go/internal/health"
+	"practica-go/internal/metrics"
+	"practica-go/internal/model"
+	"practica-go/internal/store"
+	"strconv"
+	"time"
+)
+
+const (
+	// batchSize es cuántas entregas se toman por consulta
+	batchSize = 50
+	// firstRetry es la espera antes del primer reintento; se duplica en cada
+	// intento fallido hasta maxRetryDelay
+	firstRetry    = 30 * time.Second
+	maxRetryDelay = 6 * time.Hour
+	// maxErrorLen recorta el error guardado en el registro de entregas
+	maxErrorLen = 500
+)
+
+// Dispatcher envía las entregas pendientes de la cola persistente. Una entrega
+// que falla (error de red o respuesta que no es 2xx) se reintenta con backoff
+// exponencial hasta maxAttempts intentos; después queda como failed y solo se
+// vuelve a enviar a mano.
+type Dispatcher struct {
+	store       store.WebhookStore
+	client      *http.Client
+	maxAttempts int
+	heartbeat   health.Heartbeat
+}
+
+// New crea un dispatcher; timeout limita cada envío. Las redirecciones no se
+// siguen (un 3xx cuenta como fallo) y, salvo con allowPrivate, tampoco se
+// conecta a direcciones privadas o de loopback.
+func New(st store.WebhookStore, timeout time.Duration, maxAttempts int, allowPrivate bool) *Dispatcher {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = refusePrivate
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	return &Dispatcher{
+		store: st,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// Seguir una redirección permitiría llegar a un destino que no
+			// pasó la validación de la URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Payload es el cuerpo que recibe cada webhook. ID identifica el evento y se
+// repite si el mismo evento llega dos veces, así el receptor puede descartar
+// duplicados.
+type Payload struct {
+	ID         int             `json:"id"`
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// HandleEvent es el handler del outbox: encola una entrega del evento para
+// cada webhook suscripto
+func (d *Dispatcher) HandleEvent(ctx context.Context, e *model.OutboxEvent) error {
+	payload, err := json.Marshal(Payload{ID: e.ID, Event: e.Event, OccurredAt: e.CreatedAt.UTC(), Data: e.Payload})
+	if err != nil {
+		return err
+	}
+	_, err = d.store.Enqueue(ctx, e.Event, payload, time.Now())
+	return err
+}
+
+// Check es el chequeo de readiness del dispatcher: falla si en los últimos
+// maxAge no pudo leer la cola ni terminó ningún envío
+func (d *Dispatcher) Check(maxAge time.Duration) health.CheckFunc {
+	return d.heartbeat.Check(maxAge)
+}
+
+// Run revisa la cola cada interval hasta que se cancele ctx
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.flush(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// flush envía todas las entregas vencidas, de a batchSize
+func (d *Dispatcher) flush(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := d.store.Due(ctx, time.Now(), batchSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "no se pudo leer la cola de webhooks", slog.Any("error", err))
+			return
+		}
+		d.heartbeat.Beat()
+		for _, dd := range due {
+			d.deliver(ctx, dd)
+			// Un lote lento (muchos receptores que agotan el timeout) no es
+			// un dispatcher colgado
+			d.heartbeat.Beat()
+		}
+		if len(due) < batchSize {
+			return
+		}
+	}
+}
+
+// deliver hace un intento de entrega y guarda el resultado
+func (d *Dispatcher) deliver(ctx context.Context, dd *store.DueDelivery) {
+	delivery := dd.WebhookDelivery
+	delivery.Attempts++
+	status, err := d.send(ctx, dd)
+	delivery.LastStatusCode = status
+	delivery.LastError = ""
+
+	now := time.Now().UTC()
+	result := "delivered"
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryDelivered
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= d.maxAttempts:
+		delivery.Status = model.DeliveryFailed
+		delivery.LastError = truncate(err.Error())
+		result = "failed"
+	default:
+		delivery.NextAttemptAt = now.Add(retryDelay(delivery.Attempts))
+		delivery.LastError = truncate(err.Error())
+		result = "retry"
+	}
+	metrics.WebhookDeliveries.Inc(result)
+
+	attrs := []any{
+		slog.Int("webhook_id", delivery.WebhookID),
+		slog.Int("delivery_id", delivery.ID),
+		slog.String("event", delivery.Event),
+		slog.Int("attempt", delivery.Attempts),
+		slog.String("result", result),
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "entrega de webhook fallida", append(attrs, slog.Any("error", err))...)
+	} else {
+		slog.InfoContext(ctx, "webhook entregado", attrs...)
+	}
+
+	if err := d.store.RecordAttempt(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "no se pudo guardar el intento de entrega", slog.Int("delivery_id", delivery.ID), slog.Any("error", err))
+	}
+}
+
+// send hace el POST firmado y devuelve el status de la respuesta (0 si no
+// hubo respuesta)
+func (d *Dispatcher) send(ctx context.Context, dd *store.DueDelivery) (int, error) {
+	ts := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dd.URL, bytes.NewReader(dd.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "practica-go-webhooks")
+	req.Header.Set(HeaderEvent, dd.Event)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(dd.ID))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(dd.Secret, ts, dd.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("el receptor respondió %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay es la espera después del intento número attempt (desde 1)
+func retryDelay(attempt int) time.Duration {
+	delay := firstRetry
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLen {
+		return s[:maxErrorLen]
+	}
+	return s
+}
@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"practica-go/internal/model"
+	"practica-go/internal/store"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{8, 64 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, maxRetryDelay}, // 512m supera el máximo
+		{100, maxRetryDelay},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempt); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, se esperaba %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// newTestStore abre una base SQLite migrada en un directorio temporal
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st := store.New(db)
+	if _, err := st.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+// receiver es un receptor de webhooks que responde con los códigos de
+// statuses en orden (el último se repite) y verifica la firma de cada pedido
+type receiver struct {
+	t        *testing.T
+	secret   string
+	statuses []int
+	calls    atomic.Int32
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := int(rc.calls.Add(1))
+	body, _ := io.ReadAll(r.Body)
+	ts, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if !Verify(rc.secret, ts, body, r.Header.Get(HeaderSignature)) {
+		rc.t.Errorf("pedido %d con firma inválida", n)
+	}
+	w.WriteHeader(rc.statuses[min(n, len(rc.statuses))-1])
+}
+
+// setup crea un webhook hacia url, encola un evento y devuelve la entrega
+func setup(t *testing.T, st *store.Store, url, secret string) int {
+	t.Helper()
+	ctx := context.Background()
+	_, err := st.WebhookStorage.Create(ctx, &model.Webhook{
+		URL: url, Secret: secret, Events: []string{model.EventBookCreated}, CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := New(st.WebhookStorage, time.Second, 3, true)
+	if err := d.HandleEvent(ctx, &model.OutboxEvent{ID: 1, Event: model.EventBookCreated, Payload: []byte(`{"id":1}`), CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	due, err := st.WebhookStorage.Due(ctx, time.Now(), 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("entregas pendientes = %d, err = %v", len(due), err)
+	}
+	return due[0].ID
+}
+
+// attempt hace el próximo intento de la entrega sin esperar el backoff
+func attempt(t *testing.T, d *Dispatcher, st *store.Store, id int) *model.WebhookDelivery {
+	t.Helper()
+	ctx := context.Background()
+	due, err := st.WebhookStorage.Due(ctx, time.Now().Add(24*time.Hour), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dd := range due {
+		if dd.ID == id {
+			d.deliver(ctx, dd)
+		}
+	}
+	got, err := st.WebhookStorage.GetDelivery(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+// pending → reintento con backoff → failed al agotar los intentos
+func TestDeliveryRetriesUntilFailed(t *testing.T) {
+	const secret = "secreto-de-prueba"
+	rc := &receiver{t: t, secret: secret, statuses: []int{http.StatusInternalServerError}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	st := newTestStore(t)
+	id := setup(t, st, srv.URL, secret)
+	d := New(st.WebhookStorage, time.Second, 3, true)
+
+	for n := 1; n <= 2; n++ {
+		before := time.Now()
+		got := attempt(t, d, st, id)
+		if got.Status != model.DeliveryPending || got.Attempts != n || got.LastStatusCode != 500 {
+			t.Fatalf("intento %d: %+v", n, got)
+		}
+		if wait := got.NextAttemptAt.Sub(before); wait < retryDelay(n)-time.Second || wait > retryDelay(n)+time.Second {
+			t.Errorf("intento %d: próximo intento en %s, se esperaba %s", n, wait, retryDelay(n))
+		}
+	}
+
+	got := attempt(t, d, st, id)
+	if got.Status != model.DeliveryFailed || got.Attempts != 3 || !strings.Contains(got.LastError, "500") {
+		t.Fatalf("último intento: %+v", got)
+	}
+	// Una entrega fallida ya no se toma de la cola
+	attempt(t, d, st, id)
+	if calls := rc.calls.Load(); calls != 3 {
+		t.Errorf("el receptor recibió %d pedidos, se esperaban 3", calls)
+	}
+}
+
+// Un reintento que sale bien deja la entrega como delivered
+func TestDeliveryRetryThenDelivered(t *testing.T) {
+	const secret = "secreto-de-prueba"
+	rc := &receiver{t: t, secret: secret, statuses: []int{http.StatusServiceUnavailable, http.StatusNoContent}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	st := newTestStore(t)
+	id := setup(t, st, srv.URL, secret)
+	d := New(st.WebhookStorage, time.Second, 3, true)
+
+	if got := attempt(t, d, st, id); got.Status != model.DeliveryPending || got.LastStatusCode != 503 {
+		t.Fatalf("primer intento: %+v", got)
+	}
+	got := attempt(t, d, st, id)
+	if got.Status != model.DeliveryDelivered || got.Attempts != 2 || got.DeliveredAt == nil || got.LastError != "" {
+		t.Fatalf("segundo intento: %+v", got)
+	}
+}
+
+// Una redirección no se sigue: cuenta como fallo y el destino no recibe nada
+func TestDeliveryDoesNotFollowRedirects(t *testing.T) {
+	var target atomic.Int32
+	dst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { target.Add(1) }))
+	defer dst.Close()
+	src := httptest.NewServer(http.RedirectHandler(dst.URL, http.StatusTemporaryRedirect))
+	defer src.Close()
+
+	st := newTestStore(t)
+	id := setup(t, st, src.URL, "secreto-de-prueba")
+	d := New(st.WebhookStorage, time.Second, 3, true)
+
+	got := attempt(t, d, st, id)
+	if got.Status != model.DeliveryPending || got.LastStatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("entrega = %+v", got)
+	}
+	if target.Load() != 0 {
+		t.Error("se siguió la redirección")
+	}
+}
+
+// Sin allowPrivate el dispatcher no se conecta a loopback aunque la url ya
+// esté guardada (ej. el DNS cambió después de crear el webhook)
+func TestDeliveryRefusesPrivate(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls.Add(1) }))
+	defer srv.Close()
+
+	st := newTestStore(t)
+	id := setup(t, st, srv.URL, "secreto-de-prueba")
+	d := New(st.WebhookStorage, time.Second, 3, false)
+
+	got := attempt(t, d, st, id)
+	if got.Status != model.DeliveryPending || !strings.Contains(got.LastError, "rechazada") {
+		t.Fatalf("entrega = %+v", got)
+	}
+	if calls.Load() != 0 {
+		t.Error("el receptor en loopback recibió la entrega")
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	ctx := context.Background()
+	private := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1", "localhost"}
+	for _, host := range private {
+		if err := CheckHost(ctx, host); err == nil {
+			t.Errorf("CheckHost(%q) = nil, se esperaba un error", host)
+		}
+	}
+	public := []string{"203.0.113.10", "8.8.8.8", "2001:4860:4860::8888"}
+	for _, host := range public {
+		if err := CheckHost(ctx, host); err != nil {
+			t.Errorf("CheckHost(%q) = %v", host, err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Package webhook envía las entregas encoladas a los webhooks suscriptos.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Cabeceras de cada entrega
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign calcula la firma de una entrega: HMAC-SHA256 con el secreto del
+// webhook sobre "<timestamp>.<cuerpo>", en hexadecimal y con el prefijo
+// "sha256=". Incluir el timestamp permite al receptor descartar entregas
+// viejas reenviadas por un tercero.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify comprueba una firma en tiempo constante; es lo que tiene que hacer
+// el receptor con las cabeceras X-Webhook-Timestamp y X-Webhook-Signature
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	// Calculado aparte: HMAC-SHA256("secreto-de-prueba", `1700000000.{"id":1}`)
+	const want = "sha256=a2a124cb4d64b1585bdbd0f8a4f8b5a6277f5faf17e1088008575ca37cf465b8"
+	if got := Sign("secreto-de-prueba", 1700000000, []byte(`{"id":1}`)); got != want {
+		t.Errorf("Sign = %s, se esperaba %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "secreto-de-prueba"
+	const ts = 1700000000
+	body := []byte(`{"id":1}`)
+	sig := Sign(secret, ts, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		ts        int64
+		body      string
+		signature string
+		want      bool
+	}{
+		{name: "firma correcta", secret: secret, ts: ts, body: `{"id":1}`, signature: sig, want: true},
+		{name: "cuerpo alterado", secret: secret, ts: ts, body: `{"id":2}`, signature: sig},
+		{name: "timestamp alterado", secret: secret, ts: ts + 1, body: `{"id":1}`, signature: sig},
+		{name: "otro secreto", secret: "otro-secreto-de-prueba", ts: ts, body: `{"id":1}`, signature: sig},
+		{name: "sin prefijo", secret: secret, ts: ts, body: `{"id":1}`, signature: sig[len("sha256="):]},
+		{name: "vacía", secret: secret, ts: ts, body: `{"id":1}`, signature: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.ts, []byte(tt.body), tt.signature); got != tt.want {
+				t.Errorf("Verify = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,16 +1,190 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"practica-go/internal/model"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"practica-go/internal/config"
+	"practica-go/internal/events"
+	"practica-go/internal/health"
+	"practica-go/internal/logger"
+	"practica-go/internal/metrics"
+	"practica-go/internal/outbox"
+	"practica-go/internal/security"
+	"practica-go/internal/server"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"practica-go/internal/tracing"
+	grpctransport "practica-go/internal/transport/grpc"
+	"practica-go/internal/trash"
+	"practica-go/internal/webhook"
+	"strconv"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
 )
 
+// staleIntervals es cuántos intervalos de sondeo puede pasar el relay del
+// outbox o el dispatcher de webhooks sin completar una vuelta antes de que
+// readiness falle
+const staleIntervals = 3
+
 func main() {
-	b := model.Book{
-		ID:     1,
-		Titulo: "El Principito",
-		Autor:  "Antoine de Saint-Exupéry",
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		fmt.Print(cfg.Dump())
+		return
+	}
+
+	log, err := logger.New(os.Stdout, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(log)
+	log.Info("configuración efectiva", slog.Any("config", cfg.Map()))
+
+	if err := security.SetBcryptCost(cfg.Auth.BcryptCost); err != nil {
+		log.Error("configuración de bcrypt inválida", slog.Any("error", err))
+		os.Exit(2)
+	}
+
+	exporter, closer, err := tracing.NewExporter(cfg.Trace.Exporter, cfg.Trace.File, "practica-go")
+	if err != nil {
+		log.Error("configuración de trazas inválida", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	tracing.SetExporter(exporter)
+
+	db, err := store.Open(cfg.Database.URL)
+	if err != nil {
+		log.Error("no se pudo abrir la base de datos", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+
+	metrics.RegisterDBStats(db)
+	st := store.New(db)
+
+	if cfg.Database.AutoMigrate {
+		if _, err := st.Migrate(context.Background()); err != nil {
+			log.Error("no se pudieron aplicar las migraciones", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	checker := health.New(cfg.Server.HealthTimeout)
+	checker.Register("database", st.Ping)
+	checker.Register("migrations", st.CheckMigrations)
+
+	events.SetDefault(events.NewBroker(cfg.Events.ReplaySize, cfg.Events.ClientBuffer))
+
+	tokens := security.NewTokenIssuer(cfg.Auth.JWTSecret, cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+
+	deps := server.Deps{
+		Store:                st,
+		Health:               checker,
+		Tokens:               tokens,
+		Logger:               log,
+		MaxBodyBytes:         int64(cfg.Server.MaxBodyBytes),
+		AllowPrivateWebhooks: cfg.Webhooks.AllowPrivate,
+	}
+	srv := &http.Server{
+		Addr:         ":" + strconv.Itoa(cfg.Server.Port),
+		Handler:      server.New(deps),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+	// Shutdown no cancela las peticiones en curso: los streams de /events se
+	// cierran desde el broker para no esperar el timeout
+	srv.RegisterOnShutdown(events.Default.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Info("servidor escuchando", slog.String("addr", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("el servidor se detuvo", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}()
+
+	// El relay reparte los eventos del outbox a la cola de webhooks y a
+	// /events; las entregas de webhooks se envían en segundo plano. Lo que
+	// quede pendiente al apagar se retoma al volver a arrancar.
+	dispatcher := webhook.New(st.WebhookStorage, cfg.Webhooks.Timeout, cfg.Webhooks.MaxAttempts, cfg.Webhooks.AllowPrivate)
+	relay := outbox.New(st.OutboxStorage, cfg.Outbox.Retention)
+	relay.Register("webhooks", dispatcher.HandleEvent)
+	relay.Register("events", events.Default.HandleEvent)
+	go relay.Run(ctx, cfg.Outbox.PollInterval)
+	go dispatcher.Run(ctx, cfg.Webhooks.PollInterval)
+	// Readiness falla si alguno no completa una vuelta en staleIntervals
+	// intervalos; el dispatcher tiene además el timeout de un envío en curso
+	checker.Register("outbox", relay.Check(staleIntervals*cfg.Outbox.PollInterval))
+	checker.Register("webhooks", dispatcher.Check(staleIntervals*cfg.Webhooks.PollInterval+cfg.Webhooks.Timeout))
+
+	// Los libros y usuarios dados de baja se borran definitivamente después
+	// de la retención
+	go trash.New(st, cfg.Trash.Retention).Run(ctx, cfg.Trash.PurgeInterval)
+
+	// El servidor gRPC comparte el store y los tokens con el HTTP
+	var grpcSrv *grpc.Server
+	if cfg.Server.GRPCPort > 0 {
+		lis, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.Server.GRPCPort))
+		if err != nil {
+			log.Error("no se pudo abrir el puerto gRPC", slog.Any("error", err))
+			os.Exit(1)
+		}
+		grpcSrv = grpctransport.NewServer(service.NewBook(*st), service.NewUser(*st, tokens), tokens, log)
+		checker.Register("grpc", health.DialCheck("tcp", lis.Addr().String()))
+		go func() {
+			log.Info("servidor gRPC escuchando", slog.String("addr", lis.Addr().String()))
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Error("el servidor gRPC se detuvo", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}()
 	}
 
-	fmt.Println(b)
+	<-ctx.Done()
+	shutdown(srv, grpcSrv, checker, cfg.Server, log)
+}
+
+// shutdown apaga el servidor de forma ordenada: primero marca readiness como
+// falso y espera a que el balanceador lo note, luego deja terminar las
+// peticiones en curso (y las llamadas gRPC, si el servidor gRPC está activo)
+func shutdown(srv *http.Server, grpcSrv *grpc.Server, checker *health.Checker, cfg config.ServerConfig, log *slog.Logger) {
+	log.Info("apagando servidor")
+	checker.SetShuttingDown()
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if grpcSrv != nil {
+		go func() {
+			// GracefulStop espera a los streams abiertos: si vence el plazo se cortan
+			<-ctx.Done()
+			grpcSrv.Stop()
+		}()
+		defer grpcSrv.GracefulStop()
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error("error al apagar el servidor", slog.Any("error", err))
+	}
 }
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListBooks devuelve todo el catálogo (GET /books)
+func (c *Client) ListBooks(ctx context.Context) ([]*Book, error) {
+	var resp struct {
+		Books []*Book `json:"books"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/books", nil, &resp)
+	return resp.Books, err
+}
+
+// GetBook obtiene un libro por ID (GET /books/{id})
+func (c *Client) GetBook(ctx context.Context, id int) (*Book, error) {
+	var resp struct {
+		Book *Book `json:"book"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/books/"+strconv.Itoa(id), nil, &resp)
+	return resp.Book, err
+}
+
+// CreateBook crea un libro y lo devuelve con su ID (POST /books)
+func (c *Client) CreateBook(ctx context.Context, book *Book) (*Book, error) {
+	var resp struct {
+		Book *Book `json:"book"`
+	}
+	err := c.doJSON(ctx, http.MethodPost, "/books", book, &resp)
+	return resp.Book, err
+}
+
+// UpdateBook reemplaza los datos de un libro (PUT /books/{id}). book.Version
+// se manda como If-Match: si otro cambió el libro después, la API responde
+// 412 (ver IsPreconditionFailed); AnyVersion lo reemplaza sin controlarlo.
+func (c *Client) UpdateBook(ctx context.Context, id int, book *Book) (*Book, error) {
+	var resp struct {
+		Book *Book `json:"book"`
+	}
+	err := c.doVersion(ctx, http.MethodPut, "/books/"+strconv.Itoa(id), "application/json", book.Version, book, &resp)
+	return resp.Book, err
+}
+
+// PatchBook modifica parte de un libro (PATCH /books/{id}). Con mediaType
+// MergePatch, p es un objeto con los campos a cambiar (nil los borra); con
+// JSONPatch, una lista de PatchOperation. version es la del libro que se
+// leyó (AnyVersion para no controlarla).
+func (c *Client) PatchBook(ctx context.Context, id, version int, mediaType string, p any) (*Book, error) {
+	var resp struct {
+		Book *Book `json:"book"`
+	}
+	err := c.doVersion(ctx, http.MethodPatch, "/books/"+strconv.Itoa(id), mediaType, version, p, &resp)
+	return resp.Book, err
+}
+
+// DeleteBook da de baja un libro (DELETE /books/{id}). version es la del
+// libro que se leyó (AnyVersion para borrarlo sin control de versión).
+func (c *Client) DeleteBook(ctx context.Context, id, version int) error {
+	return c.doVersion(ctx, http.MethodDelete, "/books/"+strconv.Itoa(id), "", version, nil, nil)
+}
+
+// RestoreBook vuelve a dar de alta un libro borrado; requiere rol admin
+// (POST /books/{id}/restore)
+func (c *Client) RestoreBook(ctx context.Context, id int) (*Book, error) {
+	var resp struct {
+		Book *Book `json:"book"`
+	}
+	err := c.doJSON(ctx, http.MethodPost, "/books/"+strconv.Itoa(id)+"/restore", nil, &resp)
+	return resp.Book, err
+}
+
+// BookExists indica si existe un libro con ese ID (GET /books/exists/{id})
+func (c *Client) BookExists(ctx context.Context, id int) (bool, error) {
+	var resp struct {
+		Exists bool `json:"exists"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/books/exists/"+strconv.Itoa(id), nil, &resp)
+	return resp.Exists, err
+}
+
+// SearchBooks busca libros cuyo título o autor contenga term (GET /books/search)
+func (c *Client) SearchBooks(ctx context.Context, term string) ([]*Book, error) {
+	return c.results(ctx, "/books/search", term)
+}
+
+// QueryBooks busca con el lenguaje de consultas avanzado (GET /books/query).
+// Si la consulta es inválida el *APIError trae Position y Token.
+func (c *Client) QueryBooks(ctx context.Context, q string) ([]*Book, error) {
+	return c.results(ctx, "/books/query", q)
+}
+
+func (c *Client) results(ctx context.Context, path, q string) ([]*Book, error) {
+	var resp struct {
+		Results []*Book `json:"results"`
+	}
+	err := c.do(ctx, http.MethodGet, path, url.Values{"q": {q}}, nil, "", &resp)
+	return resp.Results, err
+}
+
+// ImportCSV importa libros desde un CSV (POST /books/import). mapping es
+// opcional, ej. "title:Nombre,author:Escritor".
+func (c *Client) ImportCSV(ctx context.Context, csv io.Reader, mapping string) (*ImportReport, error) {
+	// El cuerpo se lee entero para poder reenviarlo si hay que reintentar
+	body, err := io.ReadAll(csv)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if mapping != "" {
+		query.Set("map", mapping)
+	}
+	var resp struct {
+		Report *ImportReport `json:"report"`
+	}
+	err = c.do(ctx, http.MethodPost, "/books/import", query, body, "text/csv", &resp)
+	return resp.Report, err
+}
+
+// ExportBooks escribe en w el catálogo en el formato indicado (csv, jsonl,
+// xlsx u onix), filtrado con q si no está vacío (GET /books/export)
+func (c *Client) ExportBooks(ctx context.Context, w io.Writer, format, q string) error {
+	query := url.Values{"format": {format}}
+	if q != "" {
+		query.Set("q", q)
+	}
+	return c.do(ctx, http.MethodGet, "/books/export", query, nil, "", w)
+}
@@ -0,0 +1,303 @@
+// Package client es el cliente Go de la API HTTP de Books-Store.
+//
+//	c := client.New("http://localhost:8080", client.Options{})
+//	if _, err := c.Login(ctx, "admin", "secreto"); err != nil { ... }
+//	book, err := c.CreateBook(ctx, &client.Book{Titulo: "Ficciones", Autor: "Borges"})
+//
+// Después del login el cliente agrega el token de acceso a cada petición y lo
+// refresca solo cuando está por vencer o la API responde 401. Las respuestas
+// 429 y los 5xx de métodos idempotentes se reintentan con backoff
+// exponencial. Los errores de la API se devuelven como *APIError.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnyVersion, pasado como versión a UpdateBook, PatchBook, DeleteBook o
+// PatchUser, escribe sin control de versión (If-Match: *)
+const AnyVersion = -1
+
+// Valores por defecto de Options
+const (
+	defaultMaxRetries  = 3
+	defaultBaseBackoff = 200 * time.Millisecond
+	defaultMaxBackoff  = 5 * time.Second
+)
+
+// refreshSkew es cuánto antes del vencimiento se refresca el token de acceso,
+// para no mandar uno que vence en el camino
+const refreshSkew = 30 * time.Second
+
+// Options configura el cliente. El valor cero usa los valores por defecto.
+type Options struct {
+	// HTTPClient es el cliente HTTP a usar (http.DefaultClient si es nil)
+	HTTPClient *http.Client
+	// MaxRetries es la cantidad de reintentos ante 429 o 5xx (3 si es 0;
+	// negativo deshabilita los reintentos)
+	MaxRetries int
+	// BaseBackoff es la espera antes del primer reintento; se duplica en
+	// cada uno hasta MaxBackoff (200ms y 5s por defecto)
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Client es un cliente de la API. Es seguro usarlo desde varias goroutines.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	opts    Options
+
+	mu      sync.Mutex
+	tokens  *TokenPair
+	expires time.Time
+}
+
+// New crea un cliente para la API en baseURL (ej. "http://localhost:8080")
+func New(baseURL string, opts Options) *Client {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaultBaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    opts.HTTPClient,
+		opts:    opts,
+	}
+}
+
+// SetTokens usa un par de tokens ya obtenido (ej. guardado de una sesión
+// anterior). Con nil el cliente queda anónimo.
+func (c *Client) SetTokens(tokens *TokenPair) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setTokensLocked(tokens)
+}
+
+// Tokens devuelve el par de tokens actual, o nil si no hay sesión
+func (c *Client) Tokens() *TokenPair {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		return nil
+	}
+	t := *c.tokens
+	return &t
+}
+
+func (c *Client) setTokensLocked(tokens *TokenPair) {
+	c.tokens = tokens
+	c.expires = time.Time{}
+	if tokens != nil && tokens.ExpiresIn > 0 {
+		c.expires = time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second)
+	}
+}
+
+// accessToken devuelve el token de acceso, refrescándolo antes si está por
+// vencer. force refresca aunque no esté vencido (la API respondió 401).
+func (c *Client) accessToken(ctx context.Context, force bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		return "", nil
+	}
+	expiring := !c.expires.IsZero() && time.Until(c.expires) < refreshSkew
+	if (force || expiring) && c.tokens.RefreshToken != "" {
+		var resp struct {
+			Tokens *TokenPair `json:"tokens"`
+		}
+		body, _ := json.Marshal(map[string]string{"refresh_token": c.tokens.RefreshToken})
+		if err := c.call(ctx, http.MethodPost, "/users/refresh", nil, nil, body, "application/json", "", &resp); err != nil {
+			if IsUnauthorized(err) {
+				c.setTokensLocked(nil) // el refresco también venció: hay que volver a iniciar sesión
+			}
+			return "", err
+		}
+		c.setTokensLocked(resp.Tokens)
+	}
+	return c.tokens.AccessToken, nil
+}
+
+// do envía una petición autenticada y decodifica la respuesta JSON en out
+// (si no es nil). Si la API responde 401 y hay sesión, refresca los tokens y
+// reintenta una vez.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body []byte, contentType string, out any) error {
+	return c.doHeader(ctx, method, path, query, nil, body, contentType, out)
+}
+
+// doHeader es do con cabeceras adicionales (ej. If-Match)
+func (c *Client) doHeader(ctx context.Context, method, path string, query url.Values, header http.Header, body []byte, contentType string, out any) error {
+	token, err := c.accessToken(ctx, false)
+	if err != nil {
+		return err
+	}
+	err = c.call(ctx, method, path, query, header, body, contentType, token, out)
+	if token != "" && IsUnauthorized(err) {
+		if token, err = c.accessToken(ctx, true); err != nil {
+			return err
+		}
+		err = c.call(ctx, method, path, query, header, body, contentType, token, out)
+	}
+	return err
+}
+
+// doJSON es do con el cuerpo in codificado como JSON
+func (c *Client) doJSON(ctx context.Context, method, path string, in, out any) error {
+	return c.doVersion(ctx, method, path, "application/json", 0, in, out)
+}
+
+// doVersion es doJSON con el tipo de contenido contentType y la cabecera
+// If-Match: la ETag de version, o "*" si es AnyVersion. Con version 0 no se
+// manda la cabecera y la API rechaza las escrituras con 428.
+func (c *Client) doVersion(ctx context.Context, method, path, contentType string, version int, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	} else {
+		contentType = ""
+	}
+	var header http.Header
+	switch {
+	case version == AnyVersion:
+		header = http.Header{"If-Match": {"*"}}
+	case version > 0:
+		header = http.Header{"If-Match": {`"` + strconv.Itoa(version) + `"`}}
+	}
+	return c.doHeader(ctx, method, path, nil, header, body, contentType, out)
+}
+
+// call hace la petición con reintentos y decodifica la respuesta
+func (c *Client) call(ctx context.Context, method, path string, query url.Values, header http.Header, body []byte, contentType, token string, out any) error {
+	resp, err := c.send(ctx, method, path, query, header, body, contentType, token)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch w := out.(type) {
+	case nil:
+		return nil
+	case io.Writer:
+		_, err = io.Copy(w, resp.Body)
+		return err
+	default:
+		if resp.StatusCode == http.StatusNoContent {
+			return nil
+		}
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+}
+
+// send hace la petición reintentando los 429 y los 5xx (estos solo en
+// métodos idempotentes, para no crear dos veces lo mismo). Las respuestas que
+// no son 2xx se devuelven como *APIError.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, header http.Header, body []byte, contentType, token string) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.http.Do(req)
+		retry := attempt < c.opts.MaxRetries
+		if err != nil {
+			if !retry || !idempotent(method) || ctx.Err() != nil {
+				return nil, err
+			}
+			if err := c.wait(ctx, attempt, 0); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		retryable := resp.StatusCode == http.StatusTooManyRequests ||
+			(resp.StatusCode >= 500 && idempotent(method))
+		if !retry || !retryable {
+			defer resp.Body.Close()
+			return nil, decodeError(resp)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := c.wait(ctx, attempt, retryAfter(resp)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait espera antes del reintento attempt. Si la API indicó Retry-After se
+// respeta; si no, backoff exponencial con jitter.
+func (c *Client) wait(ctx context.Context, attempt int, after time.Duration) error {
+	d := after
+	if d <= 0 {
+		d = c.opts.BaseBackoff << attempt
+		if d > c.opts.MaxBackoff || d <= 0 {
+			d = c.opts.MaxBackoff
+		}
+		// La mitad fija y la otra al azar, para que varios clientes no
+		// reintenten todos juntos
+		d = d/2 + rand.N(d/2+1)
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryAfter lee la cabecera Retry-After en segundos (0 si no está)
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
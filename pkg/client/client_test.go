@@ -0,0 +1,338 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"practica-go/internal/health"
+	"practica-go/internal/model"
+	"practica-go/internal/security"
+	"practica-go/internal/server"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"practica-go/pkg/client"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	adminUser     = "admin"
+	adminPassword = "secreto123"
+)
+
+func TestMain(m *testing.M) {
+	// Los services registran cada operación; en los tests no aportan
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// newAPI levanta la API completa (router, middlewares y handlers reales)
+// sobre una base SQLite migrada, con un admin creado. wrap, si no es nil,
+// envuelve el handler para simular fallas de red o del servidor.
+func newAPI(t *testing.T, accessTTL time.Duration, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	if err := security.SetBcryptCost(bcrypt.MinCost); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	st := store.New(db)
+	if _, err := st.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := security.NewTokenIssuer("0123456789abcdef0123456789abcdef", accessTTL, time.Hour)
+	admin := &model.User{Username: adminUser, Email: "admin@example.com", Password: adminPassword}
+	if _, err := service.NewUser(*st, tokens).CreateAdmin(context.Background(), admin); err != nil {
+		t.Fatal(err)
+	}
+
+	h := server.New(server.Deps{
+		Store:        st,
+		Health:       health.New(time.Second),
+		Tokens:       tokens,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		MaxBodyBytes: 1 << 20,
+	})
+	if wrap != nil {
+		h = wrap(h)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newClient devuelve un cliente sin esperas entre reintentos
+func newClient(srv *httptest.Server) *client.Client {
+	return client.New(srv.URL, client.Options{HTTPClient: srv.Client(), BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+}
+
+func login(t *testing.T, c *client.Client) {
+	t.Helper()
+	if _, err := c.Login(context.Background(), adminUser, adminPassword); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBooksCRUD(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(newAPI(t, time.Hour, nil))
+	login(t, c)
+
+	created, err := c.CreateBook(ctx, &client.Book{Titulo: "Ficciones", Autor: "Jorge Luis Borges", Anio: 1944, Etiquetas: []string{"cuentos"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == 0 || created.Version != 1 {
+		t.Fatalf("libro creado = %+v", created)
+	}
+
+	got, err := c.GetBook(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Titulo != "Ficciones" || got.Version != 1 {
+		t.Fatalf("GetBook = %+v", got)
+	}
+
+	got.Anio = 1956
+	updated, err := c.UpdateBook(ctx, got.ID, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Anio != 1956 || updated.Version != 2 {
+		t.Fatalf("UpdateBook = %+v", updated)
+	}
+
+	// got todavía tiene la versión 1: la API responde 412
+	if _, err := c.UpdateBook(ctx, got.ID, got); !client.IsPreconditionFailed(err) {
+		t.Fatalf("UpdateBook con versión vieja: err = %v, se esperaba 412", err)
+	}
+
+	patched, err := c.PatchBook(ctx, got.ID, updated.Version, client.MergePatch, map[string]any{"publisher": "Sur"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched.Editorial != "Sur" || patched.Anio != 1956 {
+		t.Fatalf("PatchBook = %+v", patched)
+	}
+
+	found, err := c.QueryBooks(ctx, "tag:cuentos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != got.ID {
+		t.Fatalf("QueryBooks = %+v", found)
+	}
+
+	if err := c.DeleteBook(ctx, got.ID, patched.Version); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetBook(ctx, got.ID); !client.IsNotFound(err) {
+		t.Fatalf("GetBook después de borrar: err = %v, se esperaba 404", err)
+	}
+	exists, err := c.BookExists(ctx, got.ID)
+	if err != nil || exists {
+		t.Fatalf("BookExists = %v, %v", exists, err)
+	}
+
+	restored, err := c.RestoreBook(ctx, got.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt != nil {
+		t.Fatalf("RestoreBook = %+v", restored)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	srv := newAPI(t, time.Hour, nil)
+	anon := newClient(srv)
+
+	if _, err := anon.RestoreBook(ctx, 1); !client.IsUnauthorized(err) {
+		t.Fatalf("RestoreBook sin sesión: err = %v, se esperaba 401", err)
+	}
+	if _, err := anon.Login(ctx, adminUser, "incorrecta"); !client.IsUnauthorized(err) {
+		t.Fatalf("Login con clave incorrecta: err = %v, se esperaba 401", err)
+	}
+
+	if _, err := anon.Register(ctx, &client.User{Username: "lector", Email: "lector@example.com", Password: "secreto123"}); err != nil {
+		t.Fatal(err)
+	}
+	user := newClient(srv)
+	if _, err := user.Login(ctx, "lector", "secreto123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := user.RestoreBook(ctx, 1); !client.IsForbidden(err) {
+		t.Fatalf("RestoreBook como user: err = %v, se esperaba 403", err)
+	}
+
+	_, err := user.CreateBook(ctx, &client.Book{Titulo: "x", Autor: "Borges"})
+	if !client.IsBadRequest(err) {
+		t.Fatalf("CreateBook inválido: err = %v, se esperaba 400", err)
+	}
+
+	// Los errores de consulta traen la posición del problema
+	_, err = user.QueryBooks(ctx, "author:borges AND year:>")
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Position != 18 {
+		t.Fatalf("QueryBooks inválida: err = %#v", err)
+	}
+}
+
+// Con un token de acceso que vence antes de refreshSkew el cliente refresca
+// antes de cada petición
+func TestRefreshBeforeExpiry(t *testing.T) {
+	ctx := context.Background()
+	var refreshes atomic.Int32
+	c := newClient(newAPI(t, 10*time.Second, countPath("/users/refresh", &refreshes)))
+	login(t, c)
+
+	if _, err := c.ListUsers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := refreshes.Load(); got != 1 {
+		t.Fatalf("refrescos = %d, se esperaba 1", got)
+	}
+}
+
+// countPath cuenta las peticiones a path
+func countPath(path string, calls *atomic.Int32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == path {
+				calls.Add(1)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Ante un 401 el cliente refresca y reintenta; si el refresco también falla
+// la sesión se descarta
+func TestRefreshOn401(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(newAPI(t, time.Hour, nil))
+	login(t, c)
+	valid := c.Tokens()
+
+	c.SetTokens(&client.TokenPair{AccessToken: "vencido", RefreshToken: valid.RefreshToken})
+	if _, err := c.ListUsers(ctx); err != nil {
+		t.Fatalf("ListUsers con token inválido y refresco válido: %v", err)
+	}
+	if got := c.Tokens(); got == nil || got.AccessToken == "vencido" {
+		t.Fatalf("tokens después del refresco = %+v", got)
+	}
+
+	c.SetTokens(&client.TokenPair{AccessToken: "vencido", RefreshToken: "tambien-vencido"})
+	if _, err := c.ListUsers(ctx); !client.IsUnauthorized(err) {
+		t.Fatalf("err = %v, se esperaba 401", err)
+	}
+	if c.Tokens() != nil {
+		t.Fatal("el cliente conservó una sesión que no se pudo refrescar")
+	}
+}
+
+// failFirst responde status a las primeras n peticiones del método indicado
+// y deja pasar el resto al handler real
+func failFirst(method string, status int, n int32, calls *atomic.Int32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == method && calls.Add(1) <= n {
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, `{"error":"ocupado"}`, status)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("5xx en GET se reintenta", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newClient(newAPI(t, time.Hour, failFirst(http.MethodGet, http.StatusServiceUnavailable, 2, &calls)))
+		if _, err := c.ListBooks(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got := calls.Load(); got != 3 {
+			t.Fatalf("peticiones = %d, se esperaban 3", got)
+		}
+	})
+
+	t.Run("5xx en POST no se reintenta", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newClient(newAPI(t, time.Hour, failFirst(http.MethodPost, http.StatusServiceUnavailable, 1, &calls)))
+		_, err := c.Register(ctx, &client.User{Username: "lector", Email: "lector@example.com", Password: "secreto123"})
+		var apiErr *client.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("err = %v, se esperaba 503", err)
+		}
+		if got := calls.Load(); got != 1 {
+			t.Fatalf("peticiones = %d, se esperaba 1", got)
+		}
+	})
+
+	t.Run("429 se reintenta en cualquier método", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newClient(newAPI(t, time.Hour, failFirst(http.MethodPost, http.StatusTooManyRequests, 1, &calls)))
+		if _, err := c.Register(ctx, &client.User{Username: "lector", Email: "lector@example.com", Password: "secreto123"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("se agotan los reintentos", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newClient(newAPI(t, time.Hour, failFirst(http.MethodGet, http.StatusBadGateway, 100, &calls)))
+		if _, err := c.ListBooks(ctx); err == nil {
+			t.Fatal("se esperaba un error")
+		}
+		if got := calls.Load(); got != 4 {
+			t.Fatalf("peticiones = %d, se esperaban 4 (1 + 3 reintentos)", got)
+		}
+	})
+}
+
+func TestImportExport(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(newAPI(t, time.Hour, nil))
+	login(t, c)
+
+	csv := "Nombre,Escritor,isbn\nFicciones,Jorge Luis Borges,9780306406157\nRayuela,Julio Cortázar,\n"
+	report, err := c.ImportCSV(ctx, strings.NewReader(csv), "title:Nombre,author:Escritor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 2 || report.Failed != 0 {
+		t.Fatalf("reporte = %+v", report)
+	}
+
+	var out bytes.Buffer
+	if err := c.ExportBooks(ctx, &out, "csv", "author:Borges"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "Ficciones") {
+		t.Fatalf("export = %q", out.String())
+	}
+}
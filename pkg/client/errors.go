@@ -0,0 +1,75 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// FieldError es el problema de un campo cuando el cuerpo no cumple el esquema
+type FieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+// APIError es una respuesta de error de la API, decodificada del cuerpo
+// {"error": "...", "details": [...]}. Los errores de /books/query traen
+// además la posición y el token de la consulta donde está el problema.
+type APIError struct {
+	StatusCode int          `json:"-"`
+	Message    string       `json:"error"`
+	Details    []FieldError `json:"details,omitempty"`
+	Position   int          `json:"position,omitempty"`
+	Token      string       `json:"token,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("bookstore: %d %s", e.StatusCode, e.Message)
+	if len(e.Details) > 0 {
+		parts := make([]string, len(e.Details))
+		for i, d := range e.Details {
+			parts[i] = d.Field + ": " + d.Error
+		}
+		msg += " (" + strings.Join(parts, "; ") + ")"
+	}
+	return msg
+}
+
+// IsNotFound indica si err es un 404 de la API
+func IsNotFound(err error) bool { return hasStatus(err, http.StatusNotFound) }
+
+// IsUnauthorized indica si err es un 401 de la API (sin sesión, o la sesión
+// venció y no se pudo refrescar)
+func IsUnauthorized(err error) bool { return hasStatus(err, http.StatusUnauthorized) }
+
+// IsForbidden indica si err es un 403 de la API
+func IsForbidden(err error) bool { return hasStatus(err, http.StatusForbidden) }
+
+// IsBadRequest indica si err es un 400 de la API (datos inválidos)
+func IsBadRequest(err error) bool { return hasStatus(err, http.StatusBadRequest) }
+
+// IsPreconditionFailed indica si err es un 412 de la API: el recurso cambió
+// desde que se leyó y hay que volver a leerlo antes de modificarlo
+func IsPreconditionFailed(err error) bool { return hasStatus(err, http.StatusPreconditionFailed) }
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// decodeError arma el APIError de una respuesta que no es 2xx. Si el cuerpo
+// no es el JSON esperado se usa como mensaje.
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(data))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tipos de la API. Son copias de los del servidor (mismos campos y mismos
+// nombres JSON) para que el cliente no dependa de sus paquetes internos; un
+// test verifica que sigan coincidiendo.
+
+// Book es un libro del catálogo
+type Book struct {
+	ID            int           `json:"id"`
+	Titulo        string        `json:"title"`
+	Autor         string        `json:"author"`
+	Anio          int           `json:"year,omitempty"`
+	Etiquetas     []string      `json:"tags,omitempty"`
+	ISBN          string        `json:"isbn,omitempty"`
+	Editorial     string        `json:"publisher,omitempty"`
+	Colaboradores []Contributor `json:"contributors,omitempty"`
+	Precios       []Price       `json:"prices,omitempty"`
+	Version       int           `json:"version"`
+	DeletedAt     *time.Time    `json:"deleted_at,omitempty"`
+}
+
+// Contributor es una persona o entidad que participó en el libro. Rol usa
+// los códigos de ONIX (lista 17): A01 autor, B01 editor, B06 traductor...
+type Contributor struct {
+	Nombre string `json:"name"`
+	Rol    string `json:"role"`
+}
+
+// Price es un precio de venta. Tipo usa los códigos de ONIX (lista 58)
+type Price struct {
+	Monto  float64 `json:"amount"`
+	Moneda string  `json:"currency"`
+	Tipo   string  `json:"type,omitempty"`
+}
+
+// User es un usuario. Password solo se envía (alta, cambio de contraseña);
+// la API nunca la devuelve.
+type User struct {
+	ID        int        `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	Password  string     `json:"password,omitempty"`
+	Role      string     `json:"role"`
+	Version   int        `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// TokenPair son los tokens que devuelven el login y el refresco. ExpiresIn
+// es la duración del token de acceso en segundos.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// Estados de una fila de ImportReport
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportSkipped = "skipped"
+	ImportFailed  = "failed"
+	ImportValid   = "valid"
+)
+
+// ImportReport resume una importación fila por fila
+type ImportReport struct {
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Skipped int         `json:"skipped"`
+	Failed  int         `json:"failed"`
+	Valid   int         `json:"valid,omitempty"`
+	DryRun  bool        `json:"dry_run,omitempty"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// ImportRow es el resultado de una fila del archivo importado
+type ImportRow struct {
+	Row    int    `json:"row"`
+	Status string `json:"status"`
+	BookID int    `json:"book_id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Formatos de los parches de PatchBook y PatchUser
+const (
+	MergePatch = "application/merge-patch+json"
+	JSONPatch  = "application/json-patch+json"
+)
+
+// PatchOperation es una operación de JSON Patch (RFC 6902). From solo se usa
+// en move y copy; Value en add, replace y test.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Webhook es la suscripción de un sistema externo a eventos del catálogo.
+// Secret solo viene en la respuesta de CreateWebhook.
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Estados de una entrega de webhook
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery es el envío de un evento a un webhook, con el resultado
+// del último intento
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
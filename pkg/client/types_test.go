@@ -0,0 +1,90 @@
+package client_test
+
+import (
+	"practica-go/internal/model"
+	"practica-go/internal/patch"
+	"practica-go/internal/security"
+	"practica-go/internal/service"
+	"practica-go/internal/store"
+	"practica-go/pkg/client"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Los tipos del cliente son copias de los del servidor: tienen que tener los
+// mismos campos, con el mismo nombre JSON y el mismo tipo
+func TestTypesMatchServer(t *testing.T) {
+	pairs := []struct{ client, server any }{
+		{client.Book{}, model.Book{}},
+		{client.Contributor{}, model.Contributor{}},
+		{client.Price{}, model.Price{}},
+		{client.User{}, model.User{}},
+		{client.TokenPair{}, security.TokenPair{}},
+		{client.ImportReport{}, service.ImportReport{}},
+		{client.ImportRow{}, service.ImportRow{}},
+		{client.PatchOperation{}, patch.Operation{}},
+		{client.Webhook{}, model.Webhook{}},
+		{client.WebhookDelivery{}, model.WebhookDelivery{}},
+	}
+	for _, p := range pairs {
+		ct, st := reflect.TypeOf(p.client), reflect.TypeOf(p.server)
+		t.Run(ct.Name(), func(t *testing.T) {
+			if ct.NumField() != st.NumField() {
+				t.Fatalf("%d campos, el servidor tiene %d", ct.NumField(), st.NumField())
+			}
+			for i := range ct.NumField() {
+				cf, sf := ct.Field(i), st.Field(i)
+				if cf.Name != sf.Name || jsonName(cf) != jsonName(sf) {
+					t.Errorf("campo %d: %s (%s), el servidor tiene %s (%s)", i, cf.Name, jsonName(cf), sf.Name, jsonName(sf))
+				}
+				if !sameShape(cf.Type, sf.Type) {
+					t.Errorf("%s: tipo %s, el servidor tiene %s", cf.Name, cf.Type, sf.Type)
+				}
+			}
+		})
+	}
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
+
+// sameShape compara dos tipos por su forma: los structs del cliente y del
+// servidor se consideran iguales si se llaman igual (sus campos se comparan
+// en su propio par)
+func sameShape(a, b reflect.Type) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Slice:
+		return sameShape(a.Elem(), b.Elem())
+	case reflect.Struct:
+		if a.PkgPath() == "practica-go/pkg/client" {
+			return a.Name() == b.Name() || a.Name() == "PatchOperation" && b.Name() == "Operation"
+		}
+	}
+	return a == b
+}
+
+func TestConstantsMatchServer(t *testing.T) {
+	for _, c := range []struct{ client, server any }{
+		{client.AnyVersion, store.AnyVersion},
+		{client.MergePatch, patch.MergePatch},
+		{client.JSONPatch, patch.JSONPatch},
+		{client.ImportCreated, service.ImportCreated},
+		{client.ImportUpdated, service.ImportUpdated},
+		{client.ImportSkipped, service.ImportSkipped},
+		{client.ImportFailed, service.ImportFailed},
+		{client.ImportValid, service.ImportValid},
+		{client.DeliveryPending, model.DeliveryPending},
+		{client.DeliveryDelivered, model.DeliveryDelivered},
+		{client.DeliveryFailed, model.DeliveryFailed},
+	} {
+		if c.client != c.server {
+			t.Errorf("cliente %v, servidor %v", c.client, c.server)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Login inicia sesión con username o email y guarda los tokens en el
+// cliente para las peticiones siguientes (POST /users/login)
+func (c *Client) Login(ctx context.Context, userOrEmail, password string) (*User, error) {
+	var resp struct {
+		User   *User      `json:"user"`
+		Tokens *TokenPair `json:"tokens"`
+	}
+	in := map[string]string{"user": userOrEmail, "password": password}
+	if err := c.doJSON(ctx, http.MethodPost, "/users/login", in, &resp); err != nil {
+		return nil, err
+	}
+	c.SetTokens(resp.Tokens)
+	return resp.User, nil
+}
+
+// Logout olvida los tokens; el cliente sigue como anónimo
+func (c *Client) Logout() {
+	c.SetTokens(nil)
+}
+
+// Register crea un usuario con rol user (POST /users)
+func (c *Client) Register(ctx context.Context, user *User) (*User, error) {
+	var resp struct {
+		User *User `json:"user"`
+	}
+	err := c.doJSON(ctx, http.MethodPost, "/users", user, &resp)
+	return resp.User, err
+}
+
+// ListUsers devuelve todos los usuarios (GET /users)
+func (c *Client) ListUsers(ctx context.Context) ([]*User, error) {
+	var resp struct {
+		Users []*User `json:"users"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/users", nil, &resp)
+	return resp.Users, err
+}
+
+// GetUser obtiene un usuario por username o email (GET /users/{usuario})
+func (c *Client) GetUser(ctx context.Context, userOrEmail string) (*User, error) {
+	var resp struct {
+		User *User `json:"user"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/users/"+url.PathEscape(userOrEmail), nil, &resp)
+	return resp.User, err
+}
+
+// PatchUser modifica parte de un usuario: el propio, o cualquiera con rol
+// admin (PATCH /users/{id}). p y version funcionan como en PatchBook.
+func (c *Client) PatchUser(ctx context.Context, id, version int, mediaType string, p any) (*User, error) {
+	var resp struct {
+		User *User `json:"user"`
+	}
+	err := c.doVersion(ctx, http.MethodPatch, "/users/"+strconv.Itoa(id), mediaType, version, p, &resp)
+	return resp.User, err
+}
+
+// SearchUsers busca usuarios por username o email (GET /users/search)
+func (c *Client) SearchUsers(ctx context.Context, term string) ([]*User, error) {
+	var resp struct {
+		Results []*User `json:"results"`
+	}
+	err := c.do(ctx, http.MethodGet, "/users/search", url.Values{"q": {term}}, nil, "", &resp)
+	return resp.Results, err
+}
+
+// UserExists indica si existe un usuario con ese ID (GET /users/exists/{id})
+func (c *Client) UserExists(ctx context.Context, id int) (bool, error) {
+	var resp struct {
+		Exists bool `json:"exists"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/users/exists/"+strconv.Itoa(id), nil, &resp)
+	return resp.Exists, err
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// CreateWebhook crea una suscripción (POST /webhooks, solo admin). La
+// respuesta es la única que incluye el secreto.
+func (c *Client) CreateWebhook(ctx context.Context, hook *Webhook) (*Webhook, error) {
+	var resp struct {
+		Webhook *Webhook `json:"webhook"`
+	}
+	err := c.doJSON(ctx, http.MethodPost, "/webhooks", hook, &resp)
+	return resp.Webhook, err
+}
+
+// ListWebhooks devuelve las suscripciones (GET /webhooks)
+func (c *Client) ListWebhooks(ctx context.Context) ([]*Webhook, error) {
+	var resp struct {
+		Webhooks []*Webhook `json:"webhooks"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/webhooks", nil, &resp)
+	return resp.Webhooks, err
+}
+
+// GetWebhook obtiene una suscripción (GET /webhooks/{id})
+func (c *Client) GetWebhook(ctx context.Context, id int) (*Webhook, error) {
+	var resp struct {
+		Webhook *Webhook `json:"webhook"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/webhooks/"+strconv.Itoa(id), nil, &resp)
+	return resp.Webhook, err
+}
+
+// DeleteWebhook elimina una suscripción y sus entregas (DELETE /webhooks/{id})
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	return c.doJSON(ctx, http.MethodDelete, "/webhooks/"+strconv.Itoa(id), nil, nil)
+}
+
+// WebhookDeliveries devuelve las últimas entregas de una suscripción
+// (GET /webhooks/{id}/deliveries)
+func (c *Client) WebhookDeliveries(ctx context.Context, id int) ([]*WebhookDelivery, error) {
+	var resp struct {
+		Deliveries []*WebhookDelivery `json:"deliveries"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/webhooks/"+strconv.Itoa(id)+"/deliveries", nil, &resp)
+	return resp.Deliveries, err
+}
+
+// RedeliverWebhook vuelve a encolar una entrega
+// (POST /webhooks/{id}/deliveries/{deliveryId}/redeliver)
+func (c *Client) RedeliverWebhook(ctx context.Context, id, deliveryID int) (*WebhookDelivery, error) {
+	var resp struct {
+		Delivery *WebhookDelivery `json:"delivery"`
+	}
+	err := c.doJSON(ctx, http.MethodPost, "/webhooks/"+strconv.Itoa(id)+"/deliveries/"+strconv.Itoa(deliveryID)+"/redeliver", nil, &resp)
+	return resp.Delivery, err
+}